@@ -0,0 +1,185 @@
+// Package bridge connects the service to the MQTT broker the physical drones talk to. Drones publish
+// their telemetry to drones/{serial}/telemetry and receive the commands of their transitions on drones/{serial}/cmd.
+package bridge
+
+import (
+	repo "drone/v2/repository"
+	"drone/v2/settings"
+	"drone/v2/usecase"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+)
+
+const (
+	telemetryTopic = "drones/+/telemetry"
+	commandTopic   = "drones/%s/cmd"
+	// timeout is how long connecting, subscribing and publishing are waited for
+	timeout = 10 * time.Second
+	// commandQueueSize is how many commands wait to be published before new commands are dropped
+	commandQueueSize = 100
+)
+
+var ErrUnknownSerial = errors.New("no drone has the serial number")
+
+type Bridge struct {
+	client    paho.Client
+	droneRepo repo.IDroneRepository
+	telemetry usecase.ITelemetryUsecase
+	qos       byte
+	// subscribed gets the result of the first subscription to the telemetry
+	subscribed chan error
+	// commands are published in the order they were queued, away from the drone lock of the transitions
+	commands chan command
+	stop     chan struct{}
+}
+
+type command struct {
+	topic   string
+	payload []byte
+}
+
+// NewBridge builds the bridge to the configured broker.
+func NewBridge(config settings.MQTT, d repo.IDroneRepository) *Bridge {
+	b := &Bridge{
+		droneRepo:  d,
+		qos:        byte(config.QoS),
+		subscribed: make(chan error, 1),
+		commands:   make(chan command, commandQueueSize),
+		stop:       make(chan struct{}),
+	}
+	options := paho.NewClientOptions().
+		AddBroker(config.Broker).
+		SetClientID(config.ClientID).
+		SetUsername(config.Username).
+		SetPassword(config.Password).
+		SetAutoReconnect(true).
+		SetConnectTimeout(timeout).
+		// the telemetry is subscribed again on every connect as the session is not kept
+		SetOnConnectHandler(b.subscribe).
+		SetConnectionLostHandler(func(client paho.Client, err error) {
+			log.Println(fmt.Sprintf("mqtt connection lost: %v", err))
+		})
+	b.client = paho.NewClient(options)
+	return b
+}
+
+// Connect connects to the broker and returns once the telemetry is subscribed, the telemetry of the
+// drones is fed to the telemetry usecase like the HTTP API does.
+func (b *Bridge) Connect(telemetry usecase.ITelemetryUsecase) error {
+	b.telemetry = telemetry
+	if err := wait(b.client.Connect()); err != nil {
+		return err
+	}
+	go b.publishCommands()
+	select {
+	case err := <-b.subscribed:
+		return err
+	case <-time.After(timeout):
+		return errors.New("timed out subscribing to the drones telemetry")
+	}
+}
+
+func (b *Bridge) Disconnect() {
+	close(b.stop)
+	b.client.Disconnect(uint(timeout / time.Millisecond))
+}
+
+func (b *Bridge) subscribe(client paho.Client) {
+	err := wait(client.Subscribe(telemetryTopic, b.qos, b.handleTelemetry))
+	if err != nil {
+		log.Println(err.Error())
+	}
+	select {
+	case b.subscribed <- err:
+	default:
+	}
+}
+
+func (b *Bridge) handleTelemetry(client paho.Client, message paho.Message) {
+	if err := b.recordTelemetry(message.Topic(), message.Payload()); err != nil {
+		log.Println(fmt.Sprintf("telemetry on %s is dropped: %v", message.Topic(), err))
+	}
+}
+
+// recordTelemetry records a reading or a json array of readings published to drones/{serial}/telemetry.
+func (b *Bridge) recordTelemetry(topic string, payload []byte) error {
+	levels := strings.Split(topic, "/")
+	if len(levels) != 3 || levels[0] != "drones" || levels[2] != "telemetry" {
+		return errors.New(fmt.Sprintf("topic %s is not a telemetry topic", topic))
+	}
+	drone, err := b.droneBySerial(levels[1])
+	if err != nil {
+		return err
+	}
+	var readings []usecase.TelemetryObject
+	if trimmed := strings.TrimSpace(string(payload)); strings.HasPrefix(trimmed, "[") {
+		err = json.Unmarshal(payload, &readings)
+	} else {
+		readings = make([]usecase.TelemetryObject, 1)
+		err = json.Unmarshal(payload, &readings[0])
+	}
+	if err != nil {
+		return errors.New("Invaild json payload")
+	}
+	_, err = b.telemetry.RecordTelemetry(drone.ID, readings)
+	return err
+}
+
+func (b *Bridge) droneBySerial(serialNumber string) (repo.Drone, error) {
+	drones, _, err := b.droneRepo.List(repo.DroneFilter{SerialNumber: serialNumber, Limit: 1})
+	if err != nil {
+		return repo.Drone{}, err
+	}
+	if len(drones) == 0 {
+		return repo.Drone{}, errors.New(fmt.Sprintf("%v: %s", ErrUnknownSerial, serialNumber))
+	}
+	return drones[0], nil
+}
+
+// PublishCommand publishes the command as json to drones/{serial}/cmd.
+func (b *Bridge) PublishCommand(serialNumber string, command usecase.DroneCommand) error {
+	if serialNumber == "" || strings.ContainsAny(serialNumber, "/+#") {
+		return errors.New(fmt.Sprintf("serial number %q can not be used in a topic", serialNumber))
+	}
+	payload, err := json.Marshal(command)
+	if err != nil {
+		return err
+	}
+	return b.queueCommand(fmt.Sprintf(commandTopic, serialNumber), payload)
+}
+
+// queueCommand queues the command without waiting for the broker, the transitions hold the drone lock meanwhile.
+func (b *Bridge) queueCommand(topic string, payload []byte) error {
+	select {
+	case b.commands <- command{topic: topic, payload: payload}:
+		return nil
+	default:
+		return errors.New(fmt.Sprintf("command on %s is dropped: %d commands wait to be published", topic, commandQueueSize))
+	}
+}
+
+func (b *Bridge) publishCommands() {
+	for {
+		select {
+		case command := <-b.commands:
+			if err := wait(b.client.Publish(command.topic, b.qos, false, command.payload)); err != nil {
+				log.Println(fmt.Sprintf("command on %s is dropped: %v", command.topic, err))
+			}
+		case <-b.stop:
+			return
+		}
+	}
+}
+
+func wait(token paho.Token) error {
+	if !token.WaitTimeout(timeout) {
+		return errors.New("timed out waiting for the mqtt broker")
+	}
+	return token.Error()
+}
@@ -0,0 +1,185 @@
+package bridge
+
+import (
+	"drone/v2/clock"
+	repo "drone/v2/repository"
+	"drone/v2/settings"
+	"drone/v2/usecase"
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+	mqtt "github.com/mochi-co/mqtt/server"
+	"github.com/mochi-co/mqtt/server/listeners"
+)
+
+var testTime = time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+// startBroker starts an in-process broker on a free local port and returns its url.
+func startBroker(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Can't find a free port: %v", err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	broker := mqtt.New()
+	if err := broker.AddListener(listeners.NewTCP("t1", addr), nil); err != nil {
+		t.Fatalf("Can't listen on %s: %v", addr, err)
+	}
+	if err := broker.Serve(); err != nil {
+		t.Fatalf("Can't start the broker: %v", err)
+	}
+	t.Cleanup(func() { broker.Close() })
+	return "tcp://" + addr
+}
+
+func newTestBridge(t *testing.T, broker string) (*Bridge, repo.IDroneRepository, usecase.ITelemetryUsecase) {
+	t.Helper()
+	clock := clock.NewFake(testTime)
+	droneRepo := repo.NewMemoryDroneRepo(repo.NewMemoryLogRepository(clock))
+	if _, err := droneRepo.Create(&repo.Drone{SerialNumber: "serial-1", State: usecase.StateLoaded, BatteryCapacity: 100}); err != nil {
+		t.Fatalf("Can't create drone: %v", err)
+	}
+	config := settings.Telemetry{
+		Retention:        settings.Duration{Duration: 48 * time.Hour},
+		DownsampleAfter:  settings.Duration{Duration: time.Hour},
+		DownsampleBucket: settings.Duration{Duration: 5 * time.Minute},
+	}
+	battery := settings.Battery{MinLoadingLevel: 25}
+	drones := usecase.NewDroneUsecase(droneRepo, repo.NewMemoryMedicationRepo(), battery, clock, nil, nil)
+	telemetry := usecase.NewTelemetryUsecase(repo.NewMemoryTelemetryRepo(), droneRepo, drones, config, battery, clock)
+	b := NewBridge(settings.MQTT{Broker: broker, ClientID: "drone-service", QoS: 1}, droneRepo)
+	b.telemetry = telemetry
+	return b, droneRepo, telemetry
+}
+
+func newTestClient(t *testing.T, broker string) paho.Client {
+	t.Helper()
+	client := paho.NewClient(paho.NewClientOptions().AddBroker(broker).SetClientID("drone-serial-1"))
+	if err := wait(client.Connect()); err != nil {
+		t.Fatalf("Can't connect the drone client: %v", err)
+	}
+	t.Cleanup(func() { client.Disconnect(100) })
+	return client
+}
+
+func TestBridge_Telemetry(t *testing.T) {
+	broker := startBroker(t)
+	b, droneRepo, telemetry := newTestBridge(t, broker)
+	if err := b.Connect(telemetry); err != nil {
+		t.Fatalf("Bridge.Connect() error = %v", err)
+	}
+	defer b.Disconnect()
+
+	client := newTestClient(t, broker)
+	payload := `{"recorded_at": "2026-10-18T11:59:00Z", "battery_level": 80, "latitude": 30.05, "longitude": 31.2, "state": "DELIVERING"}`
+	if err := wait(client.Publish("drones/serial-1/telemetry", 1, false, payload)); err != nil {
+		t.Fatalf("Can't publish telemetry: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		drone, _ := droneRepo.Get(1)
+		if drone.TelemetryAt != nil {
+			if drone.BatteryCapacity != 80 || drone.State != usecase.StateDelivering || drone.Latitude != 30.05 {
+				t.Errorf("drone = %+v, want it updated from the published reading", drone)
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("drone = %+v, the published reading was not recorded", drone)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestBridge_PublishCommand(t *testing.T) {
+	broker := startBroker(t)
+	b, _, telemetry := newTestBridge(t, broker)
+	if err := b.Connect(telemetry); err != nil {
+		t.Fatalf("Bridge.Connect() error = %v", err)
+	}
+	defer b.Disconnect()
+
+	client := newTestClient(t, broker)
+	received := make(chan []byte, 1)
+	err := wait(client.Subscribe("drones/serial-1/cmd", 1, func(client paho.Client, message paho.Message) {
+		received <- message.Payload()
+	}))
+	if err != nil {
+		t.Fatalf("Can't subscribe to the commands: %v", err)
+	}
+
+	command := usecase.DroneCommand{Command: usecase.CommandReturn, DroneID: 1, State: usecase.StateReturning, HomeLatitude: 30, HomeLongitude: 31, IssuedAt: testTime}
+	if err := b.PublishCommand("serial-1", command); err != nil {
+		t.Fatalf("Bridge.PublishCommand() error = %v", err)
+	}
+	select {
+	case payload := <-received:
+		var got usecase.DroneCommand
+		if err := json.Unmarshal(payload, &got); err != nil || got.Command != usecase.CommandReturn ||
+			got.State != usecase.StateReturning || got.HomeLongitude != 31 || !got.IssuedAt.Equal(testTime) {
+			t.Errorf("command = %s, %v, want %+v", payload, err, command)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the command was not received")
+	}
+
+	if err := b.PublishCommand("serial/+", command); err == nil {
+		t.Error("Bridge.PublishCommand() to a serial with wildcards error = nil")
+	}
+}
+
+func TestBridge_PublishCommand_QueueFull(t *testing.T) {
+	// not connected so nothing is published off the queue
+	b, _, _ := newTestBridge(t, "tcp://127.0.0.1:1883")
+	command := usecase.DroneCommand{Command: usecase.CommandReturn, DroneID: 1, State: usecase.StateReturning}
+	for i := 0; i < commandQueueSize; i++ {
+		if err := b.PublishCommand("serial-1", command); err != nil {
+			t.Fatalf("Bridge.PublishCommand() %d error = %v", i, err)
+		}
+	}
+	if err := b.PublishCommand("serial-1", command); err == nil {
+		t.Error("Bridge.PublishCommand() to a full queue error = nil")
+	}
+}
+
+func TestBridge_recordTelemetry(t *testing.T) {
+	b, droneRepo, _ := newTestBridge(t, "tcp://127.0.0.1:1883")
+	tests := []struct {
+		name    string
+		topic   string
+		payload string
+		wantErr string
+	}{
+		{name: "not a telemetry topic", topic: "drones/serial-1/cmd", payload: "{}", wantErr: "topic drones/serial-1/cmd is not a telemetry topic"},
+		{name: "unknown serial", topic: "drones/serial-2/telemetry", payload: "{}", wantErr: "no drone has the serial number: serial-2"},
+		{name: "invalid json", topic: "drones/serial-1/telemetry", payload: "{battery", wantErr: "Invaild json payload"},
+		{name: "invalid reading", topic: "drones/serial-1/telemetry", payload: `{"battery_level": 50}`, wantErr: "Recorded at is not provided"},
+		{name: "batch", topic: "drones/serial-1/telemetry",
+			payload: ` [{"recorded_at": "2026-10-18T11:58:00Z", "battery_level": 90}, {"recorded_at": "2026-10-18T11:59:00Z", "battery_level": 89}]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := b.recordTelemetry(tt.topic, []byte(tt.payload))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Bridge.recordTelemetry() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Bridge.recordTelemetry() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+	if drone, _ := droneRepo.Get(1); drone.BatteryCapacity != 89 {
+		t.Errorf("drone battery = %d, want 89 from the newest reading of the batch", drone.BatteryCapacity)
+	}
+}
@@ -0,0 +1,60 @@
+// Package clock tells the time and runs the periodic jobs of the app, tests use a Fake
+// they move forward by hand so time based behaviour is checked without waiting.
+package clock
+
+import (
+	"log"
+	"time"
+
+	"github.com/go-co-op/gocron"
+)
+
+type Clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+}
+
+// Scheduler runs jobs periodically once it is started.
+type Scheduler interface {
+	Every(interval time.Duration, job func())
+	Start()
+	Stop()
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+// System is the wall clock.
+var System Clock = systemClock{}
+
+type cronScheduler struct {
+	scheduler *gocron.Scheduler
+}
+
+// NewScheduler runs the jobs on gocron by the wall clock.
+func NewScheduler() Scheduler {
+	return &cronScheduler{
+		scheduler: gocron.NewScheduler(time.UTC),
+	}
+}
+
+func (s *cronScheduler) Every(interval time.Duration, job func()) {
+	if _, err := s.scheduler.Every(interval).Do(job); err != nil {
+		log.Println(err.Error())
+	}
+}
+
+func (s *cronScheduler) Start() {
+	s.scheduler.StartAsync()
+}
+
+func (s *cronScheduler) Stop() {
+	s.scheduler.Stop()
+}
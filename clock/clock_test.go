@@ -0,0 +1,69 @@
+package clock
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+var start = time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+func TestFake_Sleep(t *testing.T) {
+	f := NewFake(start)
+	woke := make(chan time.Time, 2)
+	for _, d := range []time.Duration{time.Minute, 2 * time.Minute} {
+		d := d
+		go func() {
+			f.Sleep(d)
+			woke <- f.Now()
+		}()
+	}
+	f.BlockUntil(2)
+
+	f.Advance(30 * time.Second)
+	select {
+	case <-woke:
+		t.Fatal("Sleep() returned before its time")
+	default:
+	}
+	f.Advance(time.Minute)
+	if got := <-woke; got.Before(start.Add(time.Minute)) {
+		t.Errorf("Sleep() woke at %v, want after %v", got, start.Add(time.Minute))
+	}
+	f.Advance(time.Hour)
+	<-woke
+	if got, want := f.Now(), start.Add(time.Hour+90*time.Second); !got.Equal(want) {
+		t.Errorf("Now() = %v, want %v", got, want)
+	}
+	f.Sleep(0)
+}
+
+func TestFake_Every(t *testing.T) {
+	f := NewFake(start)
+	var runs []string
+	f.Every(time.Minute, func() {
+		runs = append(runs, "minute "+f.Now().Format("15:04"))
+	})
+	f.Every(90*time.Second, func() {
+		runs = append(runs, "90s "+f.Now().Format("15:04:05"))
+	})
+	f.Every(0, func() {
+		runs = append(runs, "never")
+	})
+
+	f.Advance(time.Hour)
+	if len(runs) != 0 {
+		t.Errorf("jobs ran before Start() = %v", runs)
+	}
+	f.Start()
+	f.Advance(3 * time.Minute)
+	want := []string{"minute 13:01", "90s 13:01:30", "minute 13:02", "minute 13:03", "90s 13:03:00"}
+	if !reflect.DeepEqual(runs, want) {
+		t.Errorf("runs = %v, want %v", runs, want)
+	}
+	f.Stop()
+	f.Advance(time.Hour)
+	if len(runs) != len(want) {
+		t.Errorf("jobs ran after Stop() = %v", runs[len(want):])
+	}
+}
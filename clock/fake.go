@@ -0,0 +1,131 @@
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Fake is a Clock and a Scheduler whose time only moves when Advance is called.
+type Fake struct {
+	mu       sync.Mutex
+	slept    *sync.Cond
+	now      time.Time
+	sleepers []sleeper
+	jobs     []*fakeJob
+	started  bool
+}
+
+type sleeper struct {
+	until time.Time
+	wake  chan struct{}
+}
+
+type fakeJob struct {
+	interval time.Duration
+	next     time.Time
+	run      func()
+}
+
+func NewFake(now time.Time) *Fake {
+	f := &Fake{now: now}
+	f.slept = sync.NewCond(&f.mu)
+	return f
+}
+
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Sleep blocks until the time is advanced past d.
+func (f *Fake) Sleep(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	f.mu.Lock()
+	wake := make(chan struct{})
+	f.sleepers = append(f.sleepers, sleeper{until: f.now.Add(d), wake: wake})
+	f.slept.Broadcast()
+	f.mu.Unlock()
+	<-wake
+}
+
+// BlockUntil waits until n goroutines are sleeping, tests call it before Advance
+// so the sleeps they want to move past have started.
+func (f *Fake) BlockUntil(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for len(f.sleepers) < n {
+		f.slept.Wait()
+	}
+}
+
+func (f *Fake) Every(interval time.Duration, job func()) {
+	if interval <= 0 {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.jobs = append(f.jobs, &fakeJob{interval: interval, next: f.now.Add(interval), run: job})
+}
+
+// Start schedules the jobs, each runs first one interval after the start.
+func (f *Fake) Start() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.started = true
+	for _, job := range f.jobs {
+		job.next = f.now.Add(job.interval)
+	}
+}
+
+func (f *Fake) Stop() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.started = false
+}
+
+// Advance moves the time forward by d, the sleeps that end are woken up and the jobs
+// that fall due run in time order on the calling goroutine, each at its own time.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	end := f.now.Add(d)
+	for job := f.due(end); job != nil; job = f.due(end) {
+		f.now = job.next
+		f.wake()
+		job.next = job.next.Add(job.interval)
+		f.mu.Unlock()
+		job.run()
+		f.mu.Lock()
+	}
+	f.now = end
+	f.wake()
+	f.mu.Unlock()
+}
+
+// due is the job that falls due first until end, jobs only run once the scheduler is started.
+func (f *Fake) due(end time.Time) *fakeJob {
+	if !f.started {
+		return nil
+	}
+	var first *fakeJob
+	for _, job := range f.jobs {
+		if !job.next.After(end) && (first == nil || job.next.Before(first.next)) {
+			first = job
+		}
+	}
+	return first
+}
+
+// wake ends the sleeps that are over, the earliest first.
+func (f *Fake) wake() {
+	sort.SliceStable(f.sleepers, func(i, j int) bool {
+		return f.sleepers[i].until.Before(f.sleepers[j].until)
+	})
+	for len(f.sleepers) > 0 && !f.sleepers[0].until.After(f.now) {
+		close(f.sleepers[0].wake)
+		f.sleepers = f.sleepers[1:]
+	}
+}
@@ -4,10 +4,15 @@ go 1.18
 
 require (
 	github.com/asaskevich/govalidator v0.0.0-20210307081110-f21760c49a8d
+	github.com/eclipse/paho.mqtt.golang v1.4.3
 	github.com/go-co-op/gocron v1.17.0
 	github.com/gorilla/mux v1.8.0
+	github.com/gorilla/websocket v1.5.0
 	github.com/lib/pq v1.10.7
+	github.com/mochi-co/mqtt v1.3.2
+	gopkg.in/yaml.v3 v3.0.1
 	gorm.io/driver/postgres v1.3.10
+	gorm.io/driver/sqlite v1.2.4
 	gorm.io/gorm v1.23.9
 )
 
@@ -27,9 +32,10 @@ require (
 	github.com/kylelemons/go-gypsy v1.0.0 // indirect
 	github.com/mattn/go-sqlite3 v1.14.9 // indirect
 	github.com/robfig/cron/v3 v3.0.1 // indirect
+	github.com/rs/xid v1.4.0 // indirect
 	golang.org/x/crypto v0.0.0-20220722155217-630584e8d5aa // indirect
-	golang.org/x/sync v0.0.0-20210220032951-036812b2e83c // indirect
-	golang.org/x/text v0.3.7 // indirect
+	golang.org/x/net v0.8.0 // indirect
+	golang.org/x/sync v0.1.0 // indirect
+	golang.org/x/text v0.8.0 // indirect
 	gorm.io/driver/mysql v1.4.3 // indirect
-	gorm.io/driver/sqlite v1.2.4 // indirect
 )
@@ -1,47 +1,142 @@
 package main
 
 import (
+	"drone/v2/bridge"
+	"drone/v2/clock"
 	"drone/v2/repository"
 	db "drone/v2/repository"
 	server "drone/v2/server"
+	"drone/v2/settings"
 	"drone/v2/usecase"
+	"flag"
 	"fmt"
 	"log"
-	"time"
-
-	"github.com/go-co-op/gocron"
+	"os"
 )
 
-func runCornJob(d usecase.IDroneUsecase) {
-	s := gocron.NewScheduler(time.UTC)
-
-	s.Every(1).Minutes().Do(func() {
-		d.CheckDronesBatteries()
+func runCornJob(s clock.Scheduler, d usecase.IDroneUsecase, docks usecase.IDockUsecase, orders usecase.IOrderUsecase, flights usecase.IFlightSimulator, telemetry usecase.ITelemetryUsecase, webhooks usecase.IWebhookUsecase, config settings.Cron, simulate bool) {
+	// the drones of a broker report their battery and flights themselves
+	if simulate {
+		s.Every(config.BatteryInterval.Duration, func() {
+			d.CheckDronesBatteries(config.BatteryInterval.Duration)
+		})
+		s.Every(config.FlightInterval.Duration, func() {
+			flights.Advance()
+		})
+	}
+	s.Every(config.ChargeInterval.Duration, func() {
+		docks.ChargeDockedDrones(config.ChargeInterval.Duration)
 	})
+	s.Every(config.DispatchInterval.Duration, func() {
+		if _, err := orders.Dispatch(); err != nil {
+			log.Println(err.Error())
+		}
+	})
+	s.Every(config.TelemetryInterval.Duration, telemetry.CompactTelemetry)
+	s.Every(config.WebhookInterval.Duration, webhooks.DeliverWebhooks)
 
-	s.StartAsync()
+	s.Start()
 }
 func main() {
 	fmt.Println("Dorne start")
 
-	DB, err := db.Init()
+	configFile := flag.String("config", os.Getenv(settings.ConfigFileEnv), "Path of the yaml or json config file")
+	port := flag.String("port", "", "Port to listen on, overrides the configured port")
+	storage := flag.String("storage", "", "Storage backend postgres, sqlite or memory, overrides the configured database driver")
+	flag.Parse()
+
+	config, err := settings.Load(*configFile)
 	if err != nil {
-		log.Println("cant connect to database")
+		log.Println(err.Error())
 		return
 	}
-	logRepo := repository.NewLogRepository(DB)
-	droneRepo := repository.NewDroneRepo(DB, logRepo)
-	droneUseCase := usecase.NewDroneUsecase(droneRepo)
+	if *port != "" {
+		config.Server.Port = *port
+	}
+	if *storage != "" {
+		config.Database.Driver = *storage
+		if err := config.Validate(); err != nil {
+			log.Println(err.Error())
+			return
+		}
+	}
+
+	var logRepo repository.ILogRepository
+	var droneRepo repository.IDroneRepository
+	var medicationRepo repository.IMedicationRepository
+	var dockRepo repository.IDockRepository
+	var orderRepo repository.IOrderRepository
+	var telemetryRepo repository.ITelemetryRepository
+	var webhookRepo repository.IWebhookRepository
+	var alertRepo repository.IAlertRepository
+	if config.Database.Driver == settings.DriverMemory {
+		logRepo = repository.NewMemoryLogRepository(clock.System)
+		droneRepo = repository.NewMemoryDroneRepo(logRepo)
+		medicationRepo = repository.NewMemoryMedicationRepo()
+		dockRepo = repository.NewMemoryDockRepo()
+		orderRepo = repository.NewMemoryOrderRepo(clock.System)
+		telemetryRepo = repository.NewMemoryTelemetryRepo()
+		webhookRepo = repository.NewMemoryWebhookRepo(clock.System)
+		alertRepo = repository.NewMemoryAlertRepo(clock.System)
+	} else {
+		DB, err := db.Init(config.Database, clock.System)
+		if err != nil {
+			log.Println("cant connect to database")
+			return
+		}
+		logRepo = repository.NewLogRepository(DB)
+		droneRepo = repository.NewDroneRepo(DB, logRepo)
+		medicationRepo = repository.NewMedicationRepo(DB)
+		dockRepo = repository.NewDockRepo(DB)
+		orderRepo = repository.NewOrderRepo(DB)
+		telemetryRepo = repository.NewTelemetryRepo(DB)
+		webhookRepo = repository.NewWebhookRepo(DB)
+		alertRepo = repository.NewAlertRepo(DB)
+	}
+	var commands usecase.ICommandPublisher
+	var mqttBridge *bridge.Bridge
+	if config.MQTT.Broker != "" {
+		mqttBridge = bridge.NewBridge(config.MQTT, droneRepo)
+		commands = mqttBridge
+	}
+	eventBus := usecase.NewEventBus(clock.System)
+	droneUseCase := usecase.NewDroneUsecase(droneRepo, medicationRepo, config.Battery, clock.System, commands, eventBus)
+	telemetryUseCase := usecase.NewTelemetryUsecase(telemetryRepo, droneRepo, droneUseCase, config.Telemetry, config.Battery, clock.System)
+	if mqttBridge != nil {
+		if err := mqttBridge.Connect(telemetryUseCase); err != nil {
+			log.Println(fmt.Sprintf("cant connect to mqtt broker: %v", err))
+			return
+		}
+		defer mqttBridge.Disconnect()
+	}
+	medicationUseCase := usecase.NewMedicationUsecase(medicationRepo, orderRepo, droneRepo)
 	logUseCase := usecase.NewlogUseCase(logRepo)
-	droneAPI := server.NewDroneAPI(droneUseCase)
+	dockUseCase := usecase.NewDockUsecase(dockRepo, droneRepo, droneUseCase, logRepo, config.Battery)
+	orderUseCase := usecase.NewOrderUsecase(orderRepo, droneRepo, medicationRepo, droneUseCase, config.Battery)
+	webhookUseCase := usecase.NewWebhookUsecase(webhookRepo, eventBus, config.Webhooks, clock.System)
+	alertUseCase := usecase.NewAlertUsecase(alertRepo, droneRepo, eventBus, config.Alerts, clock.System)
+	flightSimulator := usecase.NewFlightSimulator(droneRepo, droneUseCase, clock.System)
+	droneAPI := server.NewDroneAPI(droneUseCase, medicationUseCase)
 	logAPI := server.NewLogsAPI(logUseCase)
+	dockAPI := server.NewDockAPI(dockUseCase)
+	orderAPI := server.NewOrderAPI(orderUseCase)
+	telemetryAPI := server.NewTelemetryAPI(telemetryUseCase)
+	eventAPI := server.NewEventAPI(eventBus)
+	webhookAPI := server.NewWebhookAPI(webhookUseCase)
+	alertAPI := server.NewAlertAPI(alertUseCase)
 
 	apis := server.APIs{
-		DroneAPI: droneAPI,
-		LogsAPI:  logAPI,
+		DroneAPI:     droneAPI,
+		LogsAPI:      logAPI,
+		DockAPI:      dockAPI,
+		OrderAPI:     orderAPI,
+		TelemetryAPI: telemetryAPI,
+		EventAPI:     eventAPI,
+		WebhookAPI:   webhookAPI,
+		AlertAPI:     alertAPI,
 	}
 
-	go runCornJob(droneUseCase)
+	go runCornJob(clock.NewScheduler(), droneUseCase, dockUseCase, orderUseCase, flightSimulator, telemetryUseCase, webhookUseCase, config.Cron, mqttBridge == nil)
 
-	server.StartServer(apis)
+	server.StartServer(apis, config.Server)
 }
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// ErrAlertChanged is returned when an alert is not in the status it was read with anymore.
+var ErrAlertChanged = errors.New("alert was modified by another request")
+
+// AlertFilter picks the alerts of a drone or in a status, zero values match every alert.
+type AlertFilter struct {
+	DroneID int
+	Status  string
+}
+
+type IAlertRepository interface {
+	Create(alert *Alert) (int, error)
+	Get(id int) (Alert, error)
+	List(filter AlertFilter) ([]Alert, error)
+	FindOpen(droneID int, rule string) (Alert, error)
+	Update(alert Alert, from string) error
+}
+
+type alertRepo struct {
+	client *gorm.DB
+}
+
+func NewAlertRepo(client *gorm.DB) IAlertRepository {
+	return &alertRepo{
+		client: client,
+	}
+}
+
+func (a *alertRepo) Create(alert *Alert) (int, error) {
+	if result := a.client.Create(alert); result.Error != nil {
+		return 0, result.Error
+	}
+	return alert.ID, nil
+}
+
+func (a *alertRepo) Get(id int) (Alert, error) {
+	var alert Alert
+	if result := a.client.First(&alert, id); result.Error != nil {
+		return Alert{}, result.Error
+	}
+	return alert, nil
+}
+
+// List lists the alerts matching the filter, the newest first.
+func (a *alertRepo) List(filter AlertFilter) ([]Alert, error) {
+	alerts := []Alert{}
+	query := a.client.Order("id desc")
+	if filter.DroneID != 0 {
+		query = query.Where("drone_id = ?", filter.DroneID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if result := query.Find(&alerts); result.Error != nil {
+		return nil, result.Error
+	}
+	return alerts, nil
+}
+
+// FindOpen returns the alert of the rule for the drone that is not resolved yet.
+func (a *alertRepo) FindOpen(droneID int, rule string) (Alert, error) {
+	var alert Alert
+	result := a.client.Where("drone_id = ? AND rule = ? AND status <> ?", droneID, rule, "RESOLVED").Order("id").First(&alert)
+	if result.Error != nil {
+		return Alert{}, result.Error
+	}
+	return alert, nil
+}
+
+// Update saves the status and the acknowledge and resolve times of the alert,
+// it fails with ErrAlertChanged if the alert is not in from status.
+func (a *alertRepo) Update(alert Alert, from string) error {
+	return a.client.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&Alert{}).Where("id = ? AND status = ?", alert.ID, from).Updates(map[string]interface{}{
+			"status":          alert.Status,
+			"acknowledged_at": alert.AcknowledgedAt,
+			"resolved_at":     alert.ResolvedAt,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			var count int64
+			if result := tx.Model(&Alert{}).Where("id = ?", alert.ID).Count(&count); result.Error != nil {
+				return result.Error
+			}
+			if count == 0 {
+				return ErrRecordNotFound
+			}
+			return ErrAlertChanged
+		}
+		return nil
+	})
+}
@@ -0,0 +1,75 @@
+package repository_test
+
+import (
+	"drone/v2/clock"
+	"drone/v2/repository"
+	"drone/v2/repository/repotest"
+	"drone/v2/settings"
+	"os"
+	"testing"
+	"time"
+)
+
+var testTime = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func TestMemoryConformance(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Repositories {
+		clock := clock.NewFake(testTime)
+		logRepo := repository.NewMemoryLogRepository(clock)
+		return repotest.Repositories{
+			Drones:      repository.NewMemoryDroneRepo(logRepo),
+			Logs:        logRepo,
+			Medications: repository.NewMemoryMedicationRepo(),
+			Docks:       repository.NewMemoryDockRepo(),
+			Orders:      repository.NewMemoryOrderRepo(clock),
+			Telemetry:   repository.NewMemoryTelemetryRepo(),
+			Webhooks:    repository.NewMemoryWebhookRepo(clock),
+			Alerts:      repository.NewMemoryAlertRepo(clock),
+			Clock:       clock,
+		}
+	})
+}
+
+// TestGormConformance runs on a new in-memory sqlite database for every test, or inside
+// a rolled back transaction on the configured database when DRONE_DB_DRIVER is set.
+func TestGormConformance(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Repositories {
+		config := settings.Database{Driver: settings.DriverSQLite, Path: ":memory:"}
+		if _, found := os.LookupEnv("DRONE_DB_DRIVER"); found {
+			loaded, err := settings.Load(os.Getenv(settings.ConfigFileEnv))
+			if err != nil {
+				t.Fatalf("Can't load test config: %v", err)
+			}
+			config = loaded.Database
+		}
+		clock := clock.NewFake(testTime)
+		db, err := repository.Init(config, clock)
+		if err != nil {
+			t.Fatalf("Can't open test database: %v", err)
+		}
+		client := db.Begin()
+		t.Cleanup(func() {
+			client.Rollback()
+			if sqlDB, err := db.DB(); err == nil {
+				sqlDB.Close()
+			}
+		})
+		for _, model := range []interface{}{&repository.LoadItem{}, &repository.Drone{}, &repository.Medication{}, &repository.Log{},
+			&repository.Dock{}, &repository.OrderItem{}, &repository.Order{}, &repository.Telemetry{},
+			&repository.WebhookAttempt{}, &repository.WebhookDelivery{}, &repository.Webhook{}, &repository.Alert{}} {
+			client.Unscoped().Where("1 = 1").Delete(model)
+		}
+		logRepo := repository.NewLogRepository(client)
+		return repotest.Repositories{
+			Drones:      repository.NewDroneRepo(client, logRepo),
+			Logs:        logRepo,
+			Medications: repository.NewMedicationRepo(client),
+			Docks:       repository.NewDockRepo(client),
+			Orders:      repository.NewOrderRepo(client),
+			Telemetry:   repository.NewTelemetryRepo(client),
+			Webhooks:    repository.NewWebhookRepo(client),
+			Alerts:      repository.NewAlertRepo(client),
+			Clock:       clock,
+		}
+	})
+}
@@ -1,28 +1,76 @@
 package repository
 
 import (
+	"drone/v2/clock"
+	"drone/v2/settings"
 	"fmt"
 
 	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
 )
 
-func Init() (*gorm.DB, error) {
-	dsn := "host=localhost user=postgres password=postgres dbname=drone port=5432 sslmode=disable TimeZone=Africa/Cairo"
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
-	return db, err
+// ErrRecordNotFound is returned by repositories when the requested record is not exist.
+var ErrRecordNotFound = gorm.ErrRecordNotFound
+
+// Init opens the database of the configured driver, the tables are named after the postgres schema
+// when there is one. SQLite databases are migrated on open as the migrations are written for postgres.
+// The created and updated times gorm writes are read from the clock.
+func Init(config settings.Database, clock clock.Clock) (*gorm.DB, error) {
+	var dialector gorm.Dialector
+	prefix := ""
+	switch config.Driver {
+	case settings.DriverSQLite:
+		dialector = sqlite.Open(config.Path)
+	default:
+		dialector = postgres.Open(config.DSN())
+		if config.Schema != "" {
+			prefix = config.Schema + "."
+		}
+	}
+	db, err := gorm.Open(dialector, &gorm.Config{
+		NamingStrategy: schema.NamingStrategy{TablePrefix: prefix},
+		NowFunc:        clock.Now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(config.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(config.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(config.ConnMaxLifetime.Duration)
+	if config.Driver == settings.DriverSQLite {
+		// sqlite has one writer, and every connection to ":memory:" opens a new empty database
+		// so the one connection is kept open
+		sqlDB.SetMaxOpenConns(1)
+		sqlDB.SetMaxIdleConns(1)
+		sqlDB.SetConnMaxLifetime(0)
+		if err := Migrate(db); err != nil {
+			return nil, err
+		}
+	}
+	return db, nil
+}
+
+// Migrate creates or updates the tables of all entities.
+func Migrate(db *gorm.DB) error {
+	return db.AutoMigrate(&Drone{}, &LoadItem{}, &Medication{}, &Log{}, &Dock{}, &Order{}, &OrderItem{}, &Telemetry{}, &Webhook{}, &WebhookDelivery{}, &WebhookAttempt{}, &Alert{})
 }
 
 var FixturesDrones []Drone
 
 func LoadFixtures(db *gorm.DB) error {
 	FixturesDrones = []Drone{
-		{State: "IDLE", BatteryCapacity: 100, Medications: []Medication{}},
-		{State: "IDLE", BatteryCapacity: 100, Medications: []Medication{}},
-		{State: "IDLE", BatteryCapacity: 100, Medications: []Medication{}},
-		{State: "IDLE", BatteryCapacity: 100, Medications: []Medication{}},
-		{State: "IDLE", BatteryCapacity: 100, Medications: []Medication{}},
-		{State: "IDLE", BatteryCapacity: 100, Medications: []Medication{}},
+		{State: "IDLE", BatteryCapacity: 100, Medications: []LoadItem{}},
+		{State: "IDLE", BatteryCapacity: 100, Medications: []LoadItem{}},
+		{State: "IDLE", BatteryCapacity: 100, Medications: []LoadItem{}},
+		{State: "IDLE", BatteryCapacity: 100, Medications: []LoadItem{}},
+		{State: "IDLE", BatteryCapacity: 100, Medications: []LoadItem{}},
+		{State: "IDLE", BatteryCapacity: 100, Medications: []LoadItem{}},
 	}
 
 	if result := db.Create(&FixturesDrones); result.Error != nil {
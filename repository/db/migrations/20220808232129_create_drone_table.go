@@ -1,7 +1,6 @@
 package main
 
 import (
-	entity "drone/v2/repository"
 	"time"
 
 	"gorm.io/gorm"
@@ -9,6 +8,15 @@ import (
 
 // Up is executed when this migration is applied
 func Up_20220808232129(txn *gorm.DB) {
+	// the tables are created from the schema of this migration, not from the current entities
+	type Medication struct {
+		Name    string `json:"name" gorm:"uniqueIndex"`
+		Code    string `json:"code" gorm:"primaryKey"`
+		Weight  int    `json:"weight"`
+		DroneID int    `gorm:"foreignKey:DroneID"`
+		Image   []byte `json:"image"`
+	}
+
 	type Drone struct {
 		ID              int     `json:"id" gorm:"primaryKey"`
 		SerialNumber    string  `json:"serial_number" gorm:"type:varchar(100);uniqueIndex"`
@@ -16,18 +24,10 @@ func Up_20220808232129(txn *gorm.DB) {
 		State           string  `json:"state" gorm:"default:IDLE"`
 		Model           string  `json:"model"`
 		BatteryCapacity int     `json:"battery_capactiy" gorm:"default:100"`
-		Medications     []entity.Medication
+		Medications     []Medication
 		CurrentPayload  float32 `json:"current_payload" gorm:"default:0"`
 	}
 	txn.AutoMigrate(&Drone{})
-
-	type Medication struct {
-		Name    string `json:"name" gorm:"uniqueIndex"`
-		Code    string `json:"code" gorm:"primaryKey"`
-		Weight  int    `json:"weight"`
-		DroneID int    `gorm:"foreignKey:DroneID"`
-		Image   []byte `json:"image"`
-	}
 	txn.AutoMigrate(&Medication{})
 
 	type Log struct {
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"gorm.io/gorm"
+)
+
+// Up is executed when this migration is applied
+func Up_20261018090000(txn *gorm.DB) {
+	type Medication struct {
+		Name    string  `json:"name" gorm:"uniqueIndex"`
+		Code    string  `json:"code" gorm:"primaryKey"`
+		Weight  float32 `json:"weight"`
+		Image   string  `json:"image"`
+		DroneID *int    `gorm:"foreignKey:DroneID"`
+	}
+	txn.Migrator().AlterColumn(&Medication{}, "Weight")
+	txn.Exec(`ALTER TABLE medications ALTER COLUMN image TYPE text USING convert_from(image, 'UTF8')`)
+}
+
+// Down is executed when this migration is rolled back
+func Down_20261018090000(txn *gorm.DB) {
+	type Medication struct {
+		Name    string `json:"name" gorm:"uniqueIndex"`
+		Code    string `json:"code" gorm:"primaryKey"`
+		Weight  int    `json:"weight"`
+		Image   []byte `json:"image"`
+		DroneID int    `gorm:"foreignKey:DroneID"`
+	}
+	txn.Migrator().AlterColumn(&Medication{}, "Weight")
+	txn.Exec(`ALTER TABLE medications ALTER COLUMN image TYPE bytea USING convert_to(image, 'UTF8')`)
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"gorm.io/gorm"
+)
+
+// Up is executed when this migration is applied
+func Up_20261018100000(txn *gorm.DB) {
+	type LoadItem struct {
+		ID             int     `json:"id" gorm:"primaryKey"`
+		DroneID        int     `json:"drone_id" gorm:"index"`
+		MedicationCode string  `json:"code" gorm:"index"`
+		Quantity       int     `json:"quantity" gorm:"default:1"`
+		LotNumber      string  `json:"lot_number"`
+		Weight         float32 `json:"weight"`
+	}
+	txn.AutoMigrate(&LoadItem{})
+	// medications attached to drones become load items, the catalog keeps only the medication
+	txn.Exec(`INSERT INTO load_items (drone_id, medication_code, quantity, weight)
+		SELECT drone_id, code, 1, weight FROM medications WHERE drone_id IS NOT NULL`)
+	txn.Migrator().DropColumn("medications", "drone_id")
+}
+
+// Down is executed when this migration is rolled back
+func Down_20261018100000(txn *gorm.DB) {
+	txn.Exec(`ALTER TABLE medications ADD COLUMN drone_id bigint`)
+	txn.Migrator().DropTable("load_items")
+}
@@ -0,0 +1,15 @@
+package main
+
+import (
+	"gorm.io/gorm"
+)
+
+// Up is executed when this migration is applied
+func Up_20261018110000(txn *gorm.DB) {
+	txn.Exec(`UPDATE drones SET current_payload = COALESCE(
+		(SELECT SUM(load_items.weight) FROM load_items WHERE load_items.drone_id = drones.id), 0)`)
+}
+
+// Down is executed when this migration is rolled back
+func Down_20261018110000(txn *gorm.DB) {
+}
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"gorm.io/gorm"
+)
+
+// Up is executed when this migration is applied
+func Up_20261018120000(txn *gorm.DB) {
+	type Drone struct {
+		Version int `json:"version" gorm:"default:1"`
+	}
+	txn.Migrator().AddColumn(&Drone{}, "Version")
+}
+
+// Down is executed when this migration is rolled back
+func Down_20261018120000(txn *gorm.DB) {
+	txn.Migrator().DropColumn("drones", "version")
+}
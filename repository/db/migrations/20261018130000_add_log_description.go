@@ -0,0 +1,18 @@
+package main
+
+import (
+	"gorm.io/gorm"
+)
+
+// Up is executed when this migration is applied
+func Up_20261018130000(txn *gorm.DB) {
+	type Log struct {
+		Description string
+	}
+	txn.Migrator().AddColumn(&Log{}, "Description")
+}
+
+// Down is executed when this migration is rolled back
+func Down_20261018130000(txn *gorm.DB) {
+	txn.Migrator().DropColumn("logs", "description")
+}
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"gorm.io/gorm"
+)
+
+// Up is executed when this migration is applied
+func Up_20261018140000(txn *gorm.DB) {
+	type Drone struct {
+		DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+	}
+	txn.Migrator().AddColumn(&Drone{}, "DeletedAt")
+	txn.Migrator().CreateIndex(&Drone{}, "DeletedAt")
+}
+
+// Down is executed when this migration is rolled back
+func Down_20261018140000(txn *gorm.DB) {
+	txn.Migrator().DropColumn("drones", "deleted_at")
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"gorm.io/gorm"
+)
+
+// Up is executed when this migration is applied
+func Up_20261018150000(txn *gorm.DB) {
+	type Dock struct {
+		ID         int     `json:"id" gorm:"primaryKey"`
+		Name       string  `json:"name" gorm:"uniqueIndex"`
+		Latitude   float64 `json:"latitude"`
+		Longitude  float64 `json:"longitude"`
+		Slots      int     `json:"slots"`
+		ChargeRate float64 `json:"charge_rate"`
+	}
+	type Drone struct {
+		DockID *int `json:"dock_id" gorm:"index"`
+	}
+	txn.AutoMigrate(&Dock{})
+	txn.Migrator().AddColumn(&Drone{}, "DockID")
+	txn.Migrator().CreateIndex(&Drone{}, "DockID")
+}
+
+// Down is executed when this migration is rolled back
+func Down_20261018150000(txn *gorm.DB) {
+	txn.Migrator().DropColumn("drones", "dock_id")
+	txn.Migrator().DropTable("docks")
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Up is executed when this migration is applied
+func Up_20261018160000(txn *gorm.DB) {
+	type OrderItem struct {
+		ID             int    `json:"id" gorm:"primaryKey"`
+		OrderID        int    `json:"-" gorm:"index"`
+		MedicationCode string `json:"code"`
+		Quantity       int    `json:"quantity" gorm:"default:1"`
+		JobID          int    `json:"job_id"`
+	}
+	type Order struct {
+		ID          int       `json:"id" gorm:"primaryKey"`
+		Destination string    `json:"destination"`
+		Priority    int       `json:"priority" gorm:"default:0"`
+		Status      string    `json:"status" gorm:"index;default:PENDING"`
+		DroneID     *int      `json:"drone_id" gorm:"index"`
+		CreatedAt   time.Time `json:"created_at"`
+		UpdatedAt   time.Time `json:"updated_at"`
+	}
+	txn.AutoMigrate(&Order{}, &OrderItem{})
+}
+
+// Down is executed when this migration is rolled back
+func Down_20261018160000(txn *gorm.DB) {
+	txn.Migrator().DropTable("order_items")
+	txn.Migrator().DropTable("orders")
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"gorm.io/gorm"
+)
+
+// Up is executed when this migration is applied
+func Up_20261018170000(txn *gorm.DB) {
+	type Drone struct {
+		Latitude             float64  `json:"latitude"`
+		Longitude            float64  `json:"longitude"`
+		HomeLatitude         float64  `json:"home_latitude"`
+		HomeLongitude        float64  `json:"home_longitude"`
+		DestinationLatitude  *float64 `json:"destination_latitude"`
+		DestinationLongitude *float64 `json:"destination_longitude"`
+	}
+	type Order struct {
+		DestinationLatitude  *float64 `json:"destination_latitude"`
+		DestinationLongitude *float64 `json:"destination_longitude"`
+	}
+	for _, column := range []string{"Latitude", "Longitude", "HomeLatitude", "HomeLongitude", "DestinationLatitude", "DestinationLongitude"} {
+		txn.Migrator().AddColumn(&Drone{}, column)
+	}
+	txn.Migrator().AddColumn(&Order{}, "DestinationLatitude")
+	txn.Migrator().AddColumn(&Order{}, "DestinationLongitude")
+}
+
+// Down is executed when this migration is rolled back
+func Down_20261018170000(txn *gorm.DB) {
+	txn.Migrator().DropColumn("orders", "destination_longitude")
+	txn.Migrator().DropColumn("orders", "destination_latitude")
+	for _, column := range []string{"destination_longitude", "destination_latitude", "home_longitude", "home_latitude", "longitude", "latitude"} {
+		txn.Migrator().DropColumn("drones", column)
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Up is executed when this migration is applied
+func Up_20261018180000(txn *gorm.DB) {
+	type Telemetry struct {
+		ID           int       `json:"id" gorm:"primaryKey"`
+		DroneID      int       `json:"drone_id" gorm:"index:idx_telemetries_drone_time,priority:1"`
+		RecordedAt   time.Time `json:"recorded_at" gorm:"index:idx_telemetries_drone_time,priority:2;index"`
+		BatteryLevel float64   `json:"battery_level"`
+		Latitude     float64   `json:"latitude"`
+		Longitude    float64   `json:"longitude"`
+		Altitude     float64   `json:"altitude"`
+		Speed        float64   `json:"speed"`
+		State        string    `json:"state"`
+		Samples      int       `json:"samples" gorm:"default:1"`
+	}
+	type Drone struct {
+		Altitude    float64    `json:"altitude"`
+		Speed       float64    `json:"speed"`
+		TelemetryAt *time.Time `json:"telemetry_at"`
+	}
+	txn.AutoMigrate(&Telemetry{})
+	for _, column := range []string{"Altitude", "Speed", "TelemetryAt"} {
+		txn.Migrator().AddColumn(&Drone{}, column)
+	}
+}
+
+// Down is executed when this migration is rolled back
+func Down_20261018180000(txn *gorm.DB) {
+	for _, column := range []string{"telemetry_at", "speed", "altitude"} {
+		txn.Migrator().DropColumn("drones", column)
+	}
+	txn.Migrator().DropTable("telemetries")
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Up is executed when this migration is applied
+func Up_20261018190000(txn *gorm.DB) {
+	type Webhook struct {
+		ID        int            `json:"id" gorm:"primaryKey"`
+		URL       string         `json:"url"`
+		Secret    string         `json:"-"`
+		Events    string         `json:"events"`
+		CreatedAt time.Time      `json:"created_at"`
+		DeletedAt gorm.DeletedAt `json:"-"`
+	}
+	type WebhookDelivery struct {
+		ID            int        `json:"id" gorm:"primaryKey"`
+		WebhookID     int        `json:"webhook_id" gorm:"index"`
+		EventID       int64      `json:"event_id"`
+		EventType     string     `json:"event_type"`
+		Payload       string     `json:"payload"`
+		Status        string     `json:"status" gorm:"default:PENDING"`
+		AttemptCount  int        `json:"attempt_count"`
+		NextAttemptAt *time.Time `json:"next_attempt_at" gorm:"index"`
+		CreatedAt     time.Time  `json:"created_at"`
+		UpdatedAt     time.Time  `json:"updated_at"`
+	}
+	type WebhookAttempt struct {
+		ID          int       `json:"id" gorm:"primaryKey"`
+		DeliveryID  int       `json:"-" gorm:"index"`
+		AttemptedAt time.Time `json:"attempted_at"`
+		StatusCode  int       `json:"status_code"`
+		Error       string    `json:"error,omitempty"`
+	}
+	txn.AutoMigrate(&Webhook{}, &WebhookDelivery{}, &WebhookAttempt{})
+}
+
+// Down is executed when this migration is rolled back
+func Down_20261018190000(txn *gorm.DB) {
+	txn.Migrator().DropTable("webhook_attempts")
+	txn.Migrator().DropTable("webhook_deliveries")
+	txn.Migrator().DropTable("webhooks")
+}
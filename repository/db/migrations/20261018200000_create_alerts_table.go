@@ -0,0 +1,35 @@
+package main
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Up is executed when this migration is applied
+func Up_20261018200000(txn *gorm.DB) {
+	type Drone struct {
+		Grounded bool `json:"grounded" gorm:"default:false"`
+	}
+	type Alert struct {
+		ID             int        `json:"id" gorm:"primaryKey"`
+		DroneID        int        `json:"drone_id" gorm:"index"`
+		Rule           string     `json:"rule"`
+		Severity       string     `json:"severity"`
+		Message        string     `json:"message"`
+		BatteryLevel   int        `json:"battery_level"`
+		DroneState     string     `json:"drone_state"`
+		Status         string     `json:"status" gorm:"index;default:OPEN"`
+		CreatedAt      time.Time  `json:"created_at"`
+		AcknowledgedAt *time.Time `json:"acknowledged_at"`
+		ResolvedAt     *time.Time `json:"resolved_at"`
+	}
+	txn.Migrator().AddColumn(&Drone{}, "Grounded")
+	txn.AutoMigrate(&Alert{})
+}
+
+// Down is executed when this migration is rolled back
+func Down_20261018200000(txn *gorm.DB) {
+	txn.Migrator().DropTable("alerts")
+	txn.Migrator().DropColumn("drones", "grounded")
+}
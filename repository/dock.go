@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+)
+
+type IDockRepository interface {
+	Create(dock *Dock) (int, error)
+	Get(id int) (Dock, error)
+	List() ([]Dock, error)
+}
+
+type dockRepo struct {
+	client *gorm.DB
+}
+
+func NewDockRepo(client *gorm.DB) IDockRepository {
+	return &dockRepo{
+		client: client,
+	}
+}
+
+func (d *dockRepo) Create(dock *Dock) (int, error) {
+	if result := d.client.Create(dock); result.Error != nil {
+		return 0, result.Error
+	}
+	return dock.ID, nil
+}
+
+func (d *dockRepo) Get(id int) (Dock, error) {
+	var dock Dock
+	if result := d.client.First(&dock, id); result.Error != nil {
+		return Dock{}, result.Error
+	}
+	return dock, nil
+}
+
+func (d *dockRepo) List() ([]Dock, error) {
+	docks := []Dock{}
+	if result := d.client.Order("id").Find(&docks); result.Error != nil {
+		return nil, result.Error
+	}
+	return docks, nil
+}
@@ -1,18 +1,59 @@
 package repository
 
 import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrConcurrentUpdate is returned when a drone is not in the state or version it was read with anymore.
+var ErrConcurrentUpdate = errors.New("drone was modified by another request")
+
+var (
+	ErrDockFull      = errors.New("all slots of the dock are taken")
+	ErrAlreadyDocked = errors.New("drone is already docked")
+	ErrNotDocked     = errors.New("drone is not docked")
 )
 
+// DroneFilter narrows and pages the drones returned by List, empty fields are not filtered on.
+type DroneFilter struct {
+	SerialNumber string
+	State        string
+	Model        string
+	MinBattery   int
+	DockID       int
+	Order        string
+	Offset       int
+	Limit        int
+}
+
 type IDroneRepository interface {
 	Create(drone *Drone) (int, error)
 	Get(id int) (Drone, error)
-	AddMedication(id int, medication *Medication) error
+	List(filter DroneFilter) ([]Drone, int64, error)
+	Update(drone *Drone) error
+	Decommission(id int, version int) error
+	AddMedication(id int, version int, item *LoadItem) error
+	RecalculatePayload(id int) (float32, error)
+	RemoveMedication(id int, version int, code string) error
+	RemoveItems(id int, version int, itemIDs []int) error
+	Unload(id int, version int) error
 	CheckLoadingMedication(id int) (string, error)
 	AvailableDroneForLoading() []Drone
 	CheckBatteryLevel(id int) (int, error)
-	ReduceBatteries()
-	// chnageDroneStatus(id int, state string) error
+	UpdateBatteries(levels map[int]int, description string) error
+	ChangeDroneState(id int, from string, to string) error
+	Dock(id int, dockID int, slots int) error
+	Undock(id int) error
+	UpdatePosition(id int, latitude float64, longitude float64) error
+	SetDestination(id int, latitude *float64, longitude *float64) error
+	UpdateSnapshot(id int, reading Telemetry) error
+	SetGrounded(id int, grounded bool) error
 }
 
 type droneRepo struct {
@@ -59,19 +100,180 @@ func (d *droneRepo) Get(id int) (Drone, error) {
 	return drone, nil
 }
 
-func (d *droneRepo) AddMedication(id int, medication *Medication) error {
-	drone, err := d.Get(id)
-	if err != nil {
-		return err
+// List returns one page of the drones matching the filter and the count of all matching drones.
+func (d *droneRepo) List(filter DroneFilter) ([]Drone, int64, error) {
+	query := d.client.Model(&Drone{})
+	if filter.SerialNumber != "" {
+		query = query.Where("serial_number = ?", filter.SerialNumber)
 	}
-	medication.DroneID = drone.ID
-	drone.Medications = append(drone.Medications, *medication)
-	drone.State = "Loading"
-	if result := d.client.Save(&drone); result.Error != nil {
-		return result.Error
+	if filter.State != "" {
+		query = query.Where("state = ?", filter.State)
+	}
+	if filter.Model != "" {
+		query = query.Where("model = ?", filter.Model)
+	}
+	if filter.MinBattery > 0 {
+		query = query.Where("battery_capacity >= ?", filter.MinBattery)
+	}
+	if filter.DockID > 0 {
+		query = query.Where("dock_id = ?", filter.DockID)
+	}
+	var total int64
+	if result := query.Count(&total); result.Error != nil {
+		return nil, 0, result.Error
+	}
+	order := filter.Order
+	if order == "" {
+		order = "id"
+	}
+	drones := []Drone{}
+	query = query.Preload("Medications").Order(order).Offset(filter.Offset)
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
 	}
-	// d.chnageDroneStatus(id, settings.GetDroneState()["loading"])
-	return nil
+	if result := query.Find(&drones); result.Error != nil {
+		return nil, 0, result.Error
+	}
+	return drones, total, nil
+}
+
+// Update saves the model, weight and home base of the drone if it still has the version it was read with.
+func (d *droneRepo) Update(drone *Drone) error {
+	return d.client.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&Drone{}).Where("id = ? AND version = ?", drone.ID, drone.Version).Updates(map[string]interface{}{
+			"model":          drone.Model,
+			"weight":         drone.Weight,
+			"home_latitude":  drone.HomeLatitude,
+			"home_longitude": drone.HomeLongitude,
+			"version":        gorm.Expr("version + 1"),
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return d.updateError(tx, drone.ID)
+		}
+		return nil
+	})
+}
+
+// Decommission soft deletes the drone so it is not listed or loaded anymore while its history is kept.
+func (d *droneRepo) Decommission(id int, version int) error {
+	return d.client.Transaction(func(tx *gorm.DB) error {
+		var drone Drone
+		if result := tx.First(&drone, id); result.Error != nil {
+			return result.Error
+		}
+		result := tx.Where("id = ? AND version = ?", id, version).Delete(&Drone{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrConcurrentUpdate
+		}
+		return tx.Create(&Log{
+			DroneID:         drone.ID,
+			BatteryCapacity: drone.BatteryCapacity,
+			DroneState:      drone.State,
+			Description:     "drone decommissioned",
+		}).Error
+	})
+}
+
+// AddMedication attaches the item to the drone and adds its weight to the drone payload in one transaction,
+// the drone is only updated if it still has the given version otherwise ErrConcurrentUpdate is returned.
+func (d *droneRepo) AddMedication(id int, version int, item *LoadItem) error {
+	return d.client.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&Drone{}).Where("id = ? AND version = ?", id, version).Updates(map[string]interface{}{
+			"current_payload": gorm.Expr("current_payload + ?", item.Weight),
+			"version":         gorm.Expr("version + 1"),
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return d.updateError(tx, id)
+		}
+		item.DroneID = id
+		return tx.Create(item).Error
+	})
+}
+
+// RecalculatePayload sets the drone payload to the total weight of its attached medications.
+func (d *droneRepo) RecalculatePayload(id int) (float32, error) {
+	var payload float32
+	err := d.client.Transaction(func(tx *gorm.DB) error {
+		var drone Drone
+		if result := tx.First(&drone, id); result.Error != nil {
+			return result.Error
+		}
+		result := tx.Model(&LoadItem{}).Select("COALESCE(SUM(weight), 0)").Where("drone_id = ?", id).Scan(&payload)
+		if result.Error != nil {
+			return result.Error
+		}
+		return tx.Model(&Drone{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"current_payload": payload,
+			"version":         gorm.Expr("version + 1"),
+		}).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return payload, nil
+}
+
+// RemoveMedication detaches the medication with the given code from the drone and takes its weight off the drone payload.
+func (d *droneRepo) RemoveMedication(id int, version int, code string) error {
+	return d.removeItems(id, version, fmt.Sprintf("medication %s unloaded", code), "medication_code = ?", code)
+}
+
+// RemoveItems detaches the load items with the given ids from the drone and takes their weight off the drone payload.
+func (d *droneRepo) RemoveItems(id int, version int, itemIDs []int) error {
+	return d.removeItems(id, version, fmt.Sprintf("%d load items unloaded", len(itemIDs)), "id IN ?", itemIDs)
+}
+
+// Unload detaches all medications from the drone and empties its payload.
+func (d *droneRepo) Unload(id int, version int) error {
+	return d.removeItems(id, version, "all medications unloaded", "1 = 1")
+}
+
+func (d *droneRepo) removeItems(id int, version int, description string, query string, args ...interface{}) error {
+	return d.client.Transaction(func(tx *gorm.DB) error {
+		var drone Drone
+		if result := tx.First(&drone, id); result.Error != nil {
+			return result.Error
+		}
+		var items []LoadItem
+		if result := tx.Where("drone_id = ?", id).Where(query, args...).Find(&items); result.Error != nil {
+			return result.Error
+		}
+		if len(items) == 0 {
+			return ErrRecordNotFound
+		}
+		var weight float32
+		for _, item := range items {
+			weight += item.Weight
+		}
+		result := tx.Model(&Drone{}).Where("id = ? AND version = ?", id, version).Updates(map[string]interface{}{
+			"current_payload": gorm.Expr("current_payload - ?", weight),
+			"version":         gorm.Expr("version + 1"),
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrConcurrentUpdate
+		}
+		if result := tx.Delete(&items); result.Error != nil {
+			return result.Error
+		}
+		return tx.Create(&Log{
+			DroneID:         drone.ID,
+			BatteryCapacity: drone.BatteryCapacity,
+			DroneState:      drone.State,
+			Description:     description,
+		}).Error
+	})
 }
 
 func (d *droneRepo) CheckLoadingMedication(id int) (string, error) {
@@ -84,7 +286,7 @@ func (d *droneRepo) CheckLoadingMedication(id int) (string, error) {
 
 func (d *droneRepo) AvailableDroneForLoading() []Drone {
 	var availableDrone []Drone
-	result := d.client.Where("state = ?", "IDLE").Preload("Medications").Find(&availableDrone)
+	result := d.client.Where("state = ? AND grounded = ?", "IDLE", false).Preload("Medications").Find(&availableDrone)
 	if result.Error != nil {
 		return []Drone{}
 	}
@@ -93,41 +295,280 @@ func (d *droneRepo) AvailableDroneForLoading() []Drone {
 
 func (d *droneRepo) CheckBatteryLevel(id int) (int, error) {
 	var drone Drone
-	result := d.client.Where("id = ?", id).Find(&drone)
+	result := d.client.First(&drone, id)
 	if result.Error != nil {
 		return 0, result.Error
 	}
 	return drone.BatteryCapacity, nil
 }
 
-// func (d *droneRepo) chnageDroneStatus(id int, state string) error {
-// 	result := d.client.Where("id = ?", id).Update("state", state)
-// 	if result.Error != nil {
-// 		return result.Error
-// 	}
-// 	return nil
-// }
-
-func (d *droneRepo) ReduceBatteries() {
-	//TODO: refactor can do this logic using ORM
-	var drones []Drone
-	d.client.Find(&drones)
-	drones = d.reduceBatteries(drones)
-	d.client.Save(&drones)
-}
-
-func (d *droneRepo) reduceBatteries(drones []Drone) []Drone {
-	var update []Drone
-	for _, o := range drones {
-		if o.BatteryCapacity > 1 {
-			o.BatteryCapacity = o.BatteryCapacity - 1
-			update = append(update, o)
-			d.logRepo.Create(Log{
-				DroneID:         o.ID,
-				DroneState:      o.State,
-				BatteryCapacity: o.BatteryCapacity,
-			})
-		}
-	}
-	return update
+// ChangeDroneState moves the drone from one state to another and records the move in the
+// logs within the same transaction, it fails with ErrConcurrentUpdate if the drone is not in from state.
+func (d *droneRepo) ChangeDroneState(id int, from string, to string) error {
+	return d.client.Transaction(func(tx *gorm.DB) error {
+		var drone Drone
+		if result := tx.First(&drone, id); result.Error != nil {
+			return result.Error
+		}
+		result := tx.Model(&Drone{}).Where("id = ? AND state = ?", id, from).Updates(map[string]interface{}{
+			"state":   to,
+			"version": gorm.Expr("version + 1"),
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrConcurrentUpdate
+		}
+		return tx.Create(&Log{
+			DroneID:         drone.ID,
+			BatteryCapacity: drone.BatteryCapacity,
+			DroneState:      to,
+			Description:     fmt.Sprintf("state changed from %s to %s", from, to),
+		}).Error
+	})
+}
+
+// UpdateBatteries sets the battery level of every drone in levels and logs the new levels with
+// the description, drones that are not exist anymore are skipped. The version is not changed as the battery
+// does not affect loading requests that are in progress.
+func (d *droneRepo) UpdateBatteries(levels map[int]int, description string) error {
+	return d.client.Transaction(func(tx *gorm.DB) error {
+		for _, id := range sortedIDs(levels) {
+			result := tx.Model(&Drone{}).Where("id = ?", id).Update("battery_capacity", levels[id])
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				continue
+			}
+			var drone Drone
+			if result := tx.First(&drone, id); result.Error != nil {
+				return result.Error
+			}
+			if result := tx.Create(&Log{
+				DroneID:         drone.ID,
+				DroneState:      drone.State,
+				BatteryCapacity: drone.BatteryCapacity,
+				Description:     description,
+			}); result.Error != nil {
+				return result.Error
+			}
+		}
+		return nil
+	})
+}
+
+// Dock puts the drone in a free slot of the dock, it fails with ErrDockFull if the
+// dock already holds slots drones and with ErrAlreadyDocked if the drone is in a dock.
+func (d *droneRepo) Dock(id int, dockID int, slots int) error {
+	return d.client.Transaction(func(tx *gorm.DB) error {
+		// the dock row stays locked until the transaction ends, so two requests
+		// can not both count the last free slot as theirs
+		var dock Dock
+		if result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", dockID).Find(&dock); result.Error != nil {
+			return result.Error
+		}
+		var drone Drone
+		if result := tx.First(&drone, id); result.Error != nil {
+			return result.Error
+		}
+		if drone.DockID != nil {
+			return ErrAlreadyDocked
+		}
+		var docked int64
+		if result := tx.Model(&Drone{}).Where("dock_id = ?", dockID).Count(&docked); result.Error != nil {
+			return result.Error
+		}
+		if docked >= int64(slots) {
+			return ErrDockFull
+		}
+		result := tx.Model(&Drone{}).Where("id = ? AND dock_id IS NULL", id).Update("dock_id", dockID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrConcurrentUpdate
+		}
+		return tx.Create(&Log{
+			DroneID:         drone.ID,
+			BatteryCapacity: drone.BatteryCapacity,
+			DroneState:      drone.State,
+			Description:     fmt.Sprintf("docked at dock %d", dockID),
+		}).Error
+	})
+}
+
+// Undock frees the dock slot of the drone, it fails with ErrNotDocked if the drone is not in a dock.
+func (d *droneRepo) Undock(id int) error {
+	return d.client.Transaction(func(tx *gorm.DB) error {
+		var drone Drone
+		if result := tx.First(&drone, id); result.Error != nil {
+			return result.Error
+		}
+		if drone.DockID == nil {
+			return ErrNotDocked
+		}
+		result := tx.Model(&Drone{}).Where("id = ? AND dock_id = ?", id, *drone.DockID).Update("dock_id", nil)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrConcurrentUpdate
+		}
+		return tx.Create(&Log{
+			DroneID:         drone.ID,
+			BatteryCapacity: drone.BatteryCapacity,
+			DroneState:      drone.State,
+			Description:     fmt.Sprintf("undocked from dock %d", *drone.DockID),
+		}).Error
+	})
+}
+
+// UpdatePosition saves the last known position of the drone, positions are reported too
+// often to be logged and they do not change the version of the drone.
+func (d *droneRepo) UpdatePosition(id int, latitude float64, longitude float64) error {
+	var drone Drone
+	if result := d.client.Select("id").First(&drone, id); result.Error != nil {
+		return result.Error
+	}
+	return d.client.Model(&Drone{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"latitude":  latitude,
+		"longitude": longitude,
+	}).Error
+}
+
+// SetDestination saves the destination of the delivery of the drone and logs it,
+// a nil latitude or longitude clears the destination.
+func (d *droneRepo) SetDestination(id int, latitude *float64, longitude *float64) error {
+	if latitude == nil || longitude == nil {
+		latitude, longitude = nil, nil
+	}
+	return d.client.Transaction(func(tx *gorm.DB) error {
+		var drone Drone
+		if result := tx.First(&drone, id); result.Error != nil {
+			return result.Error
+		}
+		if result := tx.Model(&Drone{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"destination_latitude":  latitude,
+			"destination_longitude": longitude,
+		}); result.Error != nil {
+			return result.Error
+		}
+		return tx.Create(&Log{
+			DroneID:         drone.ID,
+			BatteryCapacity: drone.BatteryCapacity,
+			DroneState:      drone.State,
+			Description:     destinationDescription(latitude, longitude),
+		}).Error
+	})
+}
+
+// UpdateSnapshot sets the battery, position, altitude and speed the reading reported, readings not newer than
+// the last one the drone was updated from are ignored. The reported state is not applied here as it goes through
+// the lifecycle of the drone, battery changes are logged.
+func (d *droneRepo) UpdateSnapshot(id int, reading Telemetry) error {
+	return d.client.Transaction(func(tx *gorm.DB) error {
+		var drone Drone
+		if result := tx.First(&drone, id); result.Error != nil {
+			return result.Error
+		}
+		if drone.TelemetryAt != nil && !reading.RecordedAt.After(*drone.TelemetryAt) {
+			return nil
+		}
+		battery := drone.BatteryCapacity
+		updates := map[string]interface{}{
+			"telemetry_at": reading.RecordedAt,
+		}
+		if reading.BatteryLevel != nil {
+			battery = int(math.Round(*reading.BatteryLevel))
+			updates["battery_capacity"] = battery
+		}
+		if reading.Latitude != nil && reading.Longitude != nil {
+			updates["latitude"], updates["longitude"] = *reading.Latitude, *reading.Longitude
+		}
+		if reading.Altitude != nil {
+			updates["altitude"] = *reading.Altitude
+		}
+		if reading.Speed != nil {
+			updates["speed"] = *reading.Speed
+		}
+		if result := tx.Model(&Drone{}).Where("id = ?", id).Updates(updates); result.Error != nil {
+			return result.Error
+		}
+		if battery == drone.BatteryCapacity {
+			return nil
+		}
+		return tx.Create(&Log{
+			DroneID:         drone.ID,
+			BatteryCapacity: battery,
+			DroneState:      drone.State,
+			Description:     snapshotDescription(reading),
+		}).Error
+	})
+}
+
+// SetGrounded grounds the drone or releases it, a change is logged and bumps the version
+// as it affects loading requests that are in progress.
+func (d *droneRepo) SetGrounded(id int, grounded bool) error {
+	return d.client.Transaction(func(tx *gorm.DB) error {
+		var drone Drone
+		if result := tx.First(&drone, id); result.Error != nil {
+			return result.Error
+		}
+		if drone.Grounded == grounded {
+			return nil
+		}
+		if result := tx.Model(&Drone{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"grounded": grounded,
+			"version":  gorm.Expr("version + 1"),
+		}); result.Error != nil {
+			return result.Error
+		}
+		return tx.Create(&Log{
+			DroneID:         drone.ID,
+			BatteryCapacity: drone.BatteryCapacity,
+			DroneState:      drone.State,
+			Description:     groundedDescription(grounded),
+		}).Error
+	})
+}
+
+func snapshotDescription(reading Telemetry) string {
+	return fmt.Sprintf("telemetry reported at %s", reading.RecordedAt.UTC().Format(time.RFC3339))
+}
+
+func destinationDescription(latitude *float64, longitude *float64) string {
+	if latitude == nil {
+		return "destination cleared"
+	}
+	return fmt.Sprintf("destination set to %v,%v", *latitude, *longitude)
+}
+
+func groundedDescription(grounded bool) string {
+	if grounded {
+		return "drone grounded"
+	}
+	return "drone released from the ground"
+}
+
+func sortedIDs(levels map[int]int) []int {
+	ids := make([]int, 0, len(levels))
+	for id := range levels {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// updateError tells why a conditional update of the drone did not affect it.
+func (d *droneRepo) updateError(tx *gorm.DB, id int) error {
+	var count int64
+	if result := tx.Model(&Drone{}).Where("id = ?", id).Count(&count); result.Error != nil {
+		return result.Error
+	}
+	if count == 0 {
+		return ErrRecordNotFound
+	}
+	return ErrConcurrentUpdate
 }
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"drone/v2/clock"
+	"drone/v2/settings"
 	"fmt"
 	"os"
 	"reflect"
@@ -14,8 +16,16 @@ import (
 var db *gorm.DB
 
 func TestMain(m *testing.M) {
-	var err error
-	db, err = Init()
+	config, err := settings.Load(os.Getenv(settings.ConfigFileEnv))
+	if err != nil {
+		fmt.Printf("error in loading test config: %v", err)
+	}
+	// the suite runs on an in-memory sqlite database unless a driver is chosen explicitly
+	if _, found := os.LookupEnv("DRONE_DB_DRIVER"); !found {
+		config.Database.Driver = settings.DriverSQLite
+		config.Database.Path = ":memory:"
+	}
+	db, err = Init(config.Database, clock.System)
 	if err != nil {
 		fmt.Printf("error in setup test database: %v", err)
 	}
@@ -64,6 +74,7 @@ func Test_droneRepo_Create(t *testing.T) {
 				Model:           "Lightweight",
 				BatteryCapacity: 100,
 				CurrentPayload:  0,
+				Version:         1,
 			},
 			wantMsgExp: "",
 		},
@@ -95,7 +106,7 @@ func Test_droneRepo_Create(t *testing.T) {
 			want:       1,
 			wantErr:    true,
 			wantObject: Drone{},
-			wantMsgExp: "duplicate key value violates unique constraint .*serial_number",
+			wantMsgExp: "duplicate key value violates unique constraint .*serial_number|UNIQUE constraint failed: .*serial_number",
 		},
 		{
 			name: "test can not create new drone with serial number more than 100 characters",
@@ -116,7 +127,7 @@ func Test_droneRepo_Create(t *testing.T) {
 			want:       1,
 			wantErr:    true,
 			wantObject: Drone{},
-			wantMsgExp: "value too long for type character varying.*",
+			wantMsgExp: "value too long for type character varying.*|CHECK constraint failed: chk_drones_serial_number",
 		},
 		// {
 		// 	name: "test can not create new drone with weight more than 500",
@@ -298,6 +309,7 @@ func Test_droneRepo_Get(t *testing.T) {
 				State:           "IDLE",
 				Model:           "Lightweight",
 				BatteryCapacity: 100,
+				Version:         1,
 			},
 			wantErr: false,
 			wantMsg: "",
@@ -345,7 +357,7 @@ func Test_droneRepo_AddMedication(t *testing.T) {
 	}
 	type args struct {
 		id         int
-		medication *Medication
+		item *LoadItem
 	}
 	tests := []struct {
 		name               string
@@ -353,8 +365,9 @@ func Test_droneRepo_AddMedication(t *testing.T) {
 		args               args
 		wantErr            bool
 		fixtures           []Drone
-		wantMedication     Medication
+		wantItem           LoadItem
 		medicatiionNUmbers int
+		wantPayload        float32
 	}{
 		{
 			name: "test add medication for drone that has empty mediactions",
@@ -363,10 +376,10 @@ func Test_droneRepo_AddMedication(t *testing.T) {
 			},
 			args: args{
 				id: 1,
-				medication: &Medication{
-					Name:   "medication",
-					Code:   "code 1",
-					Weight: 10,
+				item: &LoadItem{
+					MedicationCode: "code 1",
+					Quantity:       1,
+					Weight:         10,
 				},
 			},
 			fixtures: []Drone{
@@ -377,12 +390,13 @@ func Test_droneRepo_AddMedication(t *testing.T) {
 				},
 			},
 			wantErr: false,
-			wantMedication: Medication{
-				Name:   "medication",
-				Code:   "code 1",
-				Weight: 10,
+			wantItem: LoadItem{
+				MedicationCode: "code 1",
+				Quantity:       1,
+				Weight:         10,
 			},
 			medicatiionNUmbers: 1,
+			wantPayload:        10,
 		},
 		{
 			name: "test add medication for drone that has mediactions",
@@ -391,10 +405,10 @@ func Test_droneRepo_AddMedication(t *testing.T) {
 			},
 			args: args{
 				id: 1,
-				medication: &Medication{
-					Name:   "medication3",
-					Code:   "code 3",
-					Weight: 10,
+				item: &LoadItem{
+					MedicationCode: "code 3",
+					Quantity:       2,
+					Weight:         20,
 				},
 			},
 			fixtures: []Drone{
@@ -402,27 +416,28 @@ func Test_droneRepo_AddMedication(t *testing.T) {
 					SerialNumber: "ser 1",
 					State:        "IDLE",
 					Model:        "Lightweight",
-					Medications: []Medication{
+					Medications: []LoadItem{
 						{
-							Name:   "medication1",
-							Code:   "code 1",
-							Weight: 10,
+							MedicationCode: "code 1",
+							Quantity:       1,
+							Weight:         10,
 						},
 						{
-							Name:   "medication2",
-							Code:   "code 2",
-							Weight: 10,
+							MedicationCode: "code 1",
+							Quantity:       1,
+							Weight:         10,
 						},
 					},
 				},
 			},
 			wantErr: false,
-			wantMedication: Medication{
-				Name:   "medication3",
-				Code:   "code 3",
-				Weight: 10,
+			wantItem: LoadItem{
+				MedicationCode: "code 3",
+				Quantity:       2,
+				Weight:         20,
 			},
 			medicatiionNUmbers: 3,
+			wantPayload:        20,
 		},
 	}
 	for _, tt := range tests {
@@ -444,7 +459,7 @@ func Test_droneRepo_AddMedication(t *testing.T) {
 				client: trx,
 			}
 
-			if err := d.AddMedication(createDrone.ID, tt.args.medication); (err != nil) != tt.wantErr {
+			if err := d.AddMedication(createDrone.ID, createDrone.Version, tt.args.item); (err != nil) != tt.wantErr {
 				t.Errorf("droneRepo.AddMedication() error = %v, wantErr %v", err, tt.wantErr)
 			}
 
@@ -452,17 +467,201 @@ func Test_droneRepo_AddMedication(t *testing.T) {
 			if len(createDrone.Medications) != tt.medicatiionNUmbers {
 				t.Errorf("droneRepo.AddMedication() lenght error = %v, wantErr %v", len(createDrone.Medications), tt.medicatiionNUmbers)
 			}
-			if !foundMedication(tt.wantMedication, createDrone.Medications) {
-				t.Errorf("expected to found new medication %v in %v but not exist", tt.wantMedication, createDrone.Medications)
+			if !foundMedication(tt.wantItem, createDrone.Medications) {
+				t.Errorf("expected to found new medication %v in %v but not exist", tt.wantItem, createDrone.Medications)
 
 			}
+			if createDrone.CurrentPayload != tt.wantPayload {
+				t.Errorf("droneRepo.AddMedication() payload = %v, want %v", createDrone.CurrentPayload, tt.wantPayload)
+			}
 		})
 	}
 }
 
-func foundMedication(m Medication, medications []Medication) bool {
+func Test_droneRepo_AddMedication_StaleVersion(t *testing.T) {
+	// clear all record
+	db.Where("1 = 1").Delete(&Drone{})
+	trx := db.Begin()
+	defer trx.Rollback()
+	fixtures := []Drone{
+		{
+			SerialNumber: "ser 1",
+			State:        "LOADING",
+			Model:        "Lightweight",
+		},
+	}
+	if result := trx.Create(&fixtures); result.Error != nil {
+		t.Errorf("Can't create fixtures: %v", result.Error)
+	}
+	var createDrone Drone
+	trx.Where("serial_number = ?", fixtures[0].SerialNumber).Find(&createDrone)
+
+	d := &droneRepo{
+		client: trx,
+	}
+	if err := d.AddMedication(createDrone.ID, createDrone.Version, &LoadItem{MedicationCode: "code 1", Quantity: 1, Weight: 10}); err != nil {
+		t.Errorf("droneRepo.AddMedication() error = %v", err)
+	}
+	err := d.AddMedication(createDrone.ID, createDrone.Version, &LoadItem{MedicationCode: "code 2", Quantity: 1, Weight: 10})
+	if err != ErrConcurrentUpdate {
+		t.Errorf("droneRepo.AddMedication() error = %v, wantErr %v", err, ErrConcurrentUpdate)
+	}
+	err = d.AddMedication(createDrone.ID+1, 1, &LoadItem{MedicationCode: "code 2", Quantity: 1, Weight: 10})
+	if err != ErrRecordNotFound {
+		t.Errorf("droneRepo.AddMedication() error = %v, wantErr %v", err, ErrRecordNotFound)
+	}
+	trx.Preload("Medications").First(&createDrone, createDrone.ID)
+	if len(createDrone.Medications) != 1 || createDrone.CurrentPayload != 10 {
+		t.Errorf("droneRepo.AddMedication() = %v, want one medication with payload 10", createDrone)
+	}
+}
+
+func Test_droneRepo_RemoveMedicationAndUnload(t *testing.T) {
+	// clear all record
+	db.Where("1 = 1").Delete(&Drone{})
+	trx := db.Begin()
+	defer trx.Rollback()
+	fixtures := []Drone{
+		{
+			SerialNumber: "ser 1",
+			State:        "LOADED",
+			Model:        "Lightweight",
+		},
+	}
+	if result := trx.Create(&fixtures); result.Error != nil {
+		t.Errorf("Can't create fixtures: %v", result.Error)
+	}
+	d := &droneRepo{
+		client: trx,
+	}
+	id := fixtures[0].ID
+	d.AddMedication(id, 1, &LoadItem{MedicationCode: "code 1", Quantity: 1, Weight: 10})
+	d.AddMedication(id, 2, &LoadItem{MedicationCode: "code 2", Quantity: 2, Weight: 20})
+
+	if err := d.RemoveMedication(id, 1, "code 1"); err != ErrConcurrentUpdate {
+		t.Errorf("droneRepo.RemoveMedication() error = %v, wantErr %v", err, ErrConcurrentUpdate)
+	}
+	if err := d.RemoveMedication(id, 3, "code 3"); err != ErrRecordNotFound {
+		t.Errorf("droneRepo.RemoveMedication() error = %v, wantErr %v", err, ErrRecordNotFound)
+	}
+	if err := d.RemoveMedication(id, 3, "code 1"); err != nil {
+		t.Errorf("droneRepo.RemoveMedication() error = %v", err)
+	}
+	drone, _ := d.Get(id)
+	if len(drone.Medications) != 1 || drone.CurrentPayload != 20 {
+		t.Errorf("droneRepo.RemoveMedication() = %v, want one medication with payload 20", drone)
+	}
+	if err := d.Unload(id, drone.Version); err != nil {
+		t.Errorf("droneRepo.Unload() error = %v", err)
+	}
+	drone, _ = d.Get(id)
+	if len(drone.Medications) != 0 || drone.CurrentPayload != 0 {
+		t.Errorf("droneRepo.Unload() = %v, want empty drone", drone)
+	}
+	var logs []Log
+	trx.Where("drone_id = ?", id).Find(&logs)
+	if len(logs) != 2 {
+		t.Errorf("droneRepo.Unload() logs = %v, want 2 unload logs", logs)
+	}
+}
+
+func Test_droneRepo_List(t *testing.T) {
+	// clear all record
+	db.Where("1 = 1").Delete(&Drone{})
+	trx := db.Begin()
+	defer trx.Rollback()
+	fixtures := []Drone{
+		{SerialNumber: "ser 1", State: "IDLE", Model: "Lightweight", BatteryCapacity: 100},
+		{SerialNumber: "ser 2", State: "LOADED", Model: "Heavyweight", BatteryCapacity: 40},
+		{SerialNumber: "ser 3", State: "IDLE", Model: "Lightweight", BatteryCapacity: 80},
+	}
+	if result := trx.Create(&fixtures); result.Error != nil {
+		t.Errorf("Can't create fixtures: %v", result.Error)
+	}
+	d := &droneRepo{
+		client: trx,
+	}
+	tests := []struct {
+		name       string
+		filter     DroneFilter
+		wantSerial []string
+		wantTotal  int64
+	}{
+		{
+			name:       "all drones",
+			filter:     DroneFilter{},
+			wantSerial: []string{"ser 1", "ser 2", "ser 3"},
+			wantTotal:  3,
+		},
+		{
+			name:       "filter by state and battery",
+			filter:     DroneFilter{State: "IDLE", MinBattery: 90},
+			wantSerial: []string{"ser 1"},
+			wantTotal:  1,
+		},
+		{
+			name:       "sorted page",
+			filter:     DroneFilter{Order: "battery_capacity desc", Offset: 1, Limit: 1},
+			wantSerial: []string{"ser 3"},
+			wantTotal:  3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, total, err := d.List(tt.filter)
+			if err != nil {
+				t.Fatalf("droneRepo.List() error = %v", err)
+			}
+			serials := []string{}
+			for _, drone := range got {
+				serials = append(serials, drone.SerialNumber)
+			}
+			if !reflect.DeepEqual(serials, tt.wantSerial) || total != tt.wantTotal {
+				t.Errorf("droneRepo.List() = %v %v, want %v %v", serials, total, tt.wantSerial, tt.wantTotal)
+			}
+		})
+	}
+}
+
+func Test_droneRepo_UpdateAndDecommission(t *testing.T) {
+	// clear all record
+	db.Where("1 = 1").Delete(&Drone{})
+	trx := db.Begin()
+	defer trx.Rollback()
+	fixtures := []Drone{
+		{SerialNumber: "ser 1", State: "IDLE", Model: "Lightweight", Weight: 100},
+	}
+	if result := trx.Create(&fixtures); result.Error != nil {
+		t.Errorf("Can't create fixtures: %v", result.Error)
+	}
+	d := &droneRepo{
+		client: trx,
+	}
+	id := fixtures[0].ID
+	if err := d.Update(&Drone{ID: id, Version: 1, Model: "Heavyweight", Weight: 400}); err != nil {
+		t.Errorf("droneRepo.Update() error = %v", err)
+	}
+	if err := d.Update(&Drone{ID: id, Version: 1, Model: "Heavyweight", Weight: 300}); err != ErrConcurrentUpdate {
+		t.Errorf("droneRepo.Update() error = %v, wantErr %v", err, ErrConcurrentUpdate)
+	}
+	drone, _ := d.Get(id)
+	if drone.Model != "Heavyweight" || drone.Weight != 400 {
+		t.Errorf("droneRepo.Update() = %v, want Heavyweight with weight 400", drone)
+	}
+	if err := d.Decommission(id, drone.Version); err != nil {
+		t.Errorf("droneRepo.Decommission() error = %v", err)
+	}
+	if _, err := d.Get(id); err != ErrRecordNotFound {
+		t.Errorf("droneRepo.Get() error = %v, wantErr %v", err, ErrRecordNotFound)
+	}
+	if _, total, _ := d.List(DroneFilter{}); total != 0 {
+		t.Errorf("droneRepo.List() total = %v, want decommissioned drone to be hidden", total)
+	}
+}
+
+func foundMedication(m LoadItem, medications []LoadItem) bool {
 	for _, o := range medications {
-		if o.Name == m.Name {
+		if o.MedicationCode == m.MedicationCode && o.Quantity == m.Quantity {
 			return true
 		}
 	}
@@ -586,12 +785,14 @@ func Test_droneRepo_AvailableDroneForLoading(t *testing.T) {
 					State:           "IDLE",
 					Model:           "Lightweight",
 					BatteryCapacity: 100,
+					Version:         1,
 				},
 				{
 					SerialNumber:    "ser 7",
 					State:           "IDLE",
 					Model:           "Lightweight",
 					BatteryCapacity: 100,
+					Version:         1,
 				},
 			},
 		},
@@ -709,8 +910,153 @@ func Test_droneRepo_CheckBatteryLevel(t *testing.T) {
 	}
 }
 
-//FIXME: import cycle
-func Test_droneRepo_ReduceBatteries(t *testing.T) {
+func Test_droneRepo_RecalculatePayload(t *testing.T) {
+	tests := []struct {
+		name     string
+		fixtures []Drone
+		want     float32
+	}{
+		{
+			name: "test recalculate payload of drone without medications",
+			fixtures: []Drone{
+				{
+					SerialNumber:   "ser 1",
+					State:          "IDLE",
+					Model:          "Lightweight",
+					CurrentPayload: 50,
+				},
+			},
+			want: 0,
+		},
+		{
+			name: "test recalculate payload from attached medications",
+			fixtures: []Drone{
+				{
+					SerialNumber: "ser 1",
+					State:        "LOADING",
+					Model:        "Lightweight",
+					Medications: []LoadItem{
+						{
+							MedicationCode: "code 1",
+							Quantity:       2,
+							Weight:         20,
+						},
+						{
+							MedicationCode: "code 2",
+							Quantity:       1,
+							Weight:         15,
+						},
+					},
+				},
+			},
+			want: 35,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// clear all record
+			db.Where("1 = 1").Delete(&Drone{})
+			trx := db.Begin()
+			defer trx.Rollback()
+			var createDrone Drone
+			if result := trx.Create(&tt.fixtures); result.Error != nil {
+				t.Errorf("Can't create fixtures: %v", result.Error)
+			}
+			trx.Where("serial_number = ?", tt.fixtures[0].SerialNumber).Find(&createDrone)
+
+			d := &droneRepo{
+				client: trx,
+			}
+			got, err := d.RecalculatePayload(createDrone.ID)
+			if err != nil {
+				t.Errorf("droneRepo.RecalculatePayload() error = %v", err)
+			}
+			trx.First(&createDrone, createDrone.ID)
+			if got != tt.want || createDrone.CurrentPayload != tt.want {
+				t.Errorf("droneRepo.RecalculatePayload() = %v, saved %v, want %v", got, createDrone.CurrentPayload, tt.want)
+			}
+		})
+	}
+}
+
+func Test_droneRepo_ChangeDroneState(t *testing.T) {
+	tests := []struct {
+		name      string
+		fixtures  []Drone
+		from      string
+		to        string
+		wantErr   error
+		wantState string
+		wantLogs  int
+	}{
+		{
+			name: "test change drone state",
+			fixtures: []Drone{
+				{
+					SerialNumber: "ser 1",
+					State:        "IDLE",
+					Model:        "Lightweight",
+				},
+			},
+			from:      "IDLE",
+			to:        "LOADING",
+			wantErr:   nil,
+			wantState: "LOADING",
+			wantLogs:  1,
+		},
+		{
+			name: "test can not change drone state when drone is not in from state",
+			fixtures: []Drone{
+				{
+					SerialNumber: "ser 1",
+					State:        "LOADED",
+					Model:        "Lightweight",
+				},
+			},
+			from:      "IDLE",
+			to:        "LOADING",
+			wantErr:   ErrConcurrentUpdate,
+			wantState: "LOADED",
+			wantLogs:  0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// clear all record
+			db.Where("1 = 1").Delete(&Drone{})
+			db.Where("1 = 1").Delete(&Log{})
+			trx := db.Begin()
+			defer trx.Rollback()
+			var createDrone Drone
+			if len(tt.fixtures) > 0 {
+				result := trx.Create(&tt.fixtures)
+				if result.Error != nil {
+					t.Errorf("Can't create fixtures: %v", result.Error)
+				}
+				trx.Where("serial_number = ?", tt.fixtures[0].SerialNumber).Find(&createDrone)
+			}
+
+			d := &droneRepo{
+				client: trx,
+			}
+			if err := d.ChangeDroneState(createDrone.ID, tt.from, tt.to); err != tt.wantErr {
+				t.Errorf("droneRepo.ChangeDroneState() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var got Drone
+			trx.First(&got, createDrone.ID)
+			if got.State != tt.wantState {
+				t.Errorf("droneRepo.ChangeDroneState() state = %v, want %v", got.State, tt.wantState)
+			}
+			var logs int64
+			trx.Model(&Log{}).Where("drone_id = ? AND drone_state = ?", createDrone.ID, tt.to).Count(&logs)
+			if int(logs) != tt.wantLogs {
+				t.Errorf("droneRepo.ChangeDroneState() logs = %v, want %v", logs, tt.wantLogs)
+			}
+		})
+	}
+}
+
+func Test_droneRepo_UpdateBatteries(t *testing.T) {
 	type fields struct {
 		client *gorm.DB
 	}
@@ -718,10 +1064,11 @@ func Test_droneRepo_ReduceBatteries(t *testing.T) {
 		name          string
 		fields        fields
 		fixtures      []Drone
+		levels        []int
 		wantBatteries []int
 	}{
 		{
-			name: "test reduce batteries",
+			name: "test update batteries",
 			fields: fields{
 				client: db,
 			},
@@ -733,12 +1080,13 @@ func Test_droneRepo_ReduceBatteries(t *testing.T) {
 				},
 				{
 					SerialNumber:    "ser 2",
-					State:           "IDLE",
+					State:           "DELIVERING",
 					Model:           "Lightweight",
 					BatteryCapacity: 40,
 				},
 			},
-			wantBatteries: []int{99, 39},
+			levels:        []int{100, 31},
+			wantBatteries: []int{100, 31},
 		},
 	}
 	for _, tt := range tests {
@@ -756,14 +1104,19 @@ func Test_droneRepo_ReduceBatteries(t *testing.T) {
 
 			d := &droneRepo{
 				client: trx,
-				// logRepo: NewLogRepoMock(),
 			}
-			d.ReduceBatteries()
+			levels := map[int]int{}
+			for i, level := range tt.levels {
+				levels[tt.fixtures[i].ID] = level
+			}
+			if err := d.UpdateBatteries(levels, ""); err != nil {
+				t.Errorf("droneRepo.UpdateBatteries() error = %v", err)
+			}
 			var createDrones []Drone
-			trx.Find(&createDrones)
-			for i, _ := range createDrones {
+			trx.Order("id").Find(&createDrones)
+			for i := range createDrones {
 				if tt.wantBatteries[i] != createDrones[i].BatteryCapacity {
-					t.Errorf("ReduceBatteries = %v, want %v", tt.wantBatteries[i], createDrones[i].BatteryCapacity)
+					t.Errorf("UpdateBatteries = %v, want %v", createDrones[i].BatteryCapacity, tt.wantBatteries[i])
 				}
 			}
 		})
@@ -7,30 +7,61 @@ import (
 )
 
 type Medication struct {
-	Name    string `json:"name" gorm:"uniqueIndex"`
-	Code    string `json:"code" gorm:"primaryKey"`
-	Weight  int    `json:"weight"`
-	Image   []byte `json:"image"`
-	DroneID int    `gorm:"foreignKey:DroneID"`
+	Name   string  `json:"name" gorm:"uniqueIndex"`
+	Code   string  `json:"code" gorm:"primaryKey"`
+	Weight float32 `json:"weight"`
+	Image  string  `json:"image"`
 }
 
-func (Medication) TableName() string {
-	return `"drone"."medications"`
+// LoadItem is a quantity of a catalog medication loaded onto a drone,
+// Weight is the total weight of the item (medication weight * quantity).
+type LoadItem struct {
+	ID             int     `json:"id" gorm:"primaryKey"`
+	DroneID        int     `json:"drone_id" gorm:"index"`
+	MedicationCode string  `json:"code" gorm:"index"`
+	Quantity       int     `json:"quantity" gorm:"default:1"`
+	LotNumber      string  `json:"lot_number"`
+	Weight         float32 `json:"weight"`
 }
 
 type Drone struct {
 	ID              int     `json:"id" gorm:"primaryKey"`
-	SerialNumber    string  `json:"serial_number" gorm:"type:varchar(100);uniqueIndex"`
+	SerialNumber    string  `json:"serial_number" gorm:"type:varchar(100);uniqueIndex;check:chk_drones_serial_number,length(serial_number) <= 100"`
 	Weight          float32 `json:"weight"`
 	State           string  `json:"state" gorm:"default:IDLE"`
 	Model           string  `json:"model"`
 	BatteryCapacity int     `json:"battery_capactiy" gorm:"default:100"`
-	Medications     []Medication
+	Medications     []LoadItem
 	CurrentPayload  float32 `json:"current_payload" gorm:"default:0"`
+	Version         int     `json:"version" gorm:"default:1"`
+	DockID          *int    `json:"dock_id" gorm:"index"`
+	// Latitude and Longitude are the last known position of the drone in degrees
+	Latitude      float64 `json:"latitude"`
+	Longitude     float64 `json:"longitude"`
+	HomeLatitude  float64 `json:"home_latitude"`
+	HomeLongitude float64 `json:"home_longitude"`
+	// the destination of the delivery the drone is on, nil when it has none
+	DestinationLatitude  *float64 `json:"destination_latitude"`
+	DestinationLongitude *float64 `json:"destination_longitude"`
+	// Altitude in meters and Speed in km/h are reported by the drone telemetry,
+	// TelemetryAt is the time of the reading the drone was last updated from
+	Altitude    float64    `json:"altitude"`
+	Speed       float64    `json:"speed"`
+	TelemetryAt *time.Time `json:"telemetry_at"`
+	// Grounded drones can not be loaded or sent off until their critical battery alert is resolved
+	Grounded  bool           `json:"grounded" gorm:"default:false"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
-func (Drone) TableName() string {
-	return `"drone"."drones"`
+// Dock is a charging station with a number of slots, drones docked in it are charged
+// by ChargeRate percent of their battery every minute.
+type Dock struct {
+	ID         int     `json:"id" gorm:"primaryKey"`
+	Name       string  `json:"name" gorm:"uniqueIndex"`
+	Latitude   float64 `json:"latitude"`
+	Longitude  float64 `json:"longitude"`
+	Slots      int     `json:"slots"`
+	ChargeRate float64 `json:"charge_rate"`
 }
 
 type Log struct {
@@ -41,8 +72,97 @@ type Log struct {
 	DroneID         int
 	BatteryCapacity int
 	DroneState      string
+	Description     string
 }
 
-func (Log) TableName() string {
-	return `"drone"."logs"`
+// Order is a delivery of medications to a destination, it is loaded onto DroneID once assigned.
+type Order struct {
+	ID          int    `json:"id" gorm:"primaryKey"`
+	Destination string `json:"destination"`
+	Priority    int    `json:"priority" gorm:"default:0"`
+	Status      string `json:"status" gorm:"index;default:PENDING"`
+	DroneID     *int   `json:"drone_id" gorm:"index"`
+	// the coordinates of the destination, nil when the order has only an address
+	DestinationLatitude  *float64    `json:"destination_latitude"`
+	DestinationLongitude *float64    `json:"destination_longitude"`
+	Items                []OrderItem `json:"items"`
+	CreatedAt            time.Time   `json:"created_at"`
+	UpdatedAt            time.Time   `json:"updated_at"`
+}
+
+// OrderItem is a requested quantity of a catalog medication, JobID is the loading job
+// of the item once the order is assigned to a drone.
+type OrderItem struct {
+	ID             int    `json:"id" gorm:"primaryKey"`
+	OrderID        int    `json:"-" gorm:"index"`
+	MedicationCode string `json:"code"`
+	Quantity       int    `json:"quantity" gorm:"default:1"`
+	JobID          int    `json:"job_id"`
+}
+
+// Telemetry is a reading reported by a drone at RecordedAt, the values the drone did not report are nil.
+// Old readings are downsampled into one reading per drone and time bucket, Samples counts the readings merged into it.
+type Telemetry struct {
+	ID           int       `json:"id" gorm:"primaryKey"`
+	DroneID      int       `json:"drone_id" gorm:"index:idx_telemetries_drone_time,priority:1"`
+	RecordedAt   time.Time `json:"recorded_at" gorm:"index:idx_telemetries_drone_time,priority:2;index"`
+	BatteryLevel *float64  `json:"battery_level"`
+	Latitude     *float64  `json:"latitude"`
+	Longitude    *float64  `json:"longitude"`
+	Altitude     *float64  `json:"altitude"`
+	Speed        *float64  `json:"speed"`
+	State        string    `json:"state"`
+	Samples      int       `json:"samples" gorm:"default:1"`
+}
+
+// Webhook is a receiver URL subscribed to the events in Events, a comma separated list where empty
+// means every event. The deliveries to it are signed with Secret.
+type Webhook struct {
+	ID        int            `json:"id" gorm:"primaryKey"`
+	URL       string         `json:"url"`
+	Secret    string         `json:"-"`
+	Events    string         `json:"events"`
+	CreatedAt time.Time      `json:"created_at"`
+	DeletedAt gorm.DeletedAt `json:"-"`
+}
+
+// WebhookDelivery is an event sent to a webhook, it is due for an attempt at NextAttemptAt
+// and NextAttemptAt is nil once it succeeded or was given up.
+type WebhookDelivery struct {
+	ID            int              `json:"id" gorm:"primaryKey"`
+	WebhookID     int              `json:"webhook_id" gorm:"index"`
+	EventID       int64            `json:"event_id"`
+	EventType     string           `json:"event_type"`
+	Payload       string           `json:"payload"`
+	Status        string           `json:"status" gorm:"default:PENDING"`
+	AttemptCount  int              `json:"attempt_count"`
+	NextAttemptAt *time.Time       `json:"next_attempt_at" gorm:"index"`
+	Attempts      []WebhookAttempt `json:"attempts" gorm:"foreignKey:DeliveryID"`
+	CreatedAt     time.Time        `json:"created_at"`
+	UpdatedAt     time.Time        `json:"updated_at"`
+}
+
+// WebhookAttempt is one request of a delivery, StatusCode is 0 when the receiver did not answer.
+type WebhookAttempt struct {
+	ID          int       `json:"id" gorm:"primaryKey"`
+	DeliveryID  int       `json:"-" gorm:"index"`
+	AttemptedAt time.Time `json:"attempted_at"`
+	StatusCode  int       `json:"status_code"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// Alert is an alert rule that fired for a drone, the rule does not fire again for the drone
+// until the alert is resolved. Status is OPEN, ACKNOWLEDGED or RESOLVED.
+type Alert struct {
+	ID             int        `json:"id" gorm:"primaryKey"`
+	DroneID        int        `json:"drone_id" gorm:"index"`
+	Rule           string     `json:"rule"`
+	Severity       string     `json:"severity"`
+	Message        string     `json:"message"`
+	BatteryLevel   int        `json:"battery_level"`
+	DroneState     string     `json:"drone_state"`
+	Status         string     `json:"status" gorm:"index;default:OPEN"`
+	CreatedAt      time.Time  `json:"created_at"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at"`
+	ResolvedAt     *time.Time `json:"resolved_at"`
 }
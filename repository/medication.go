@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+)
+
+type IMedicationRepository interface {
+	Create(medication *Medication) (string, error)
+	Get(code string) (Medication, error)
+	List() ([]Medication, error)
+	Update(medication *Medication) error
+	Delete(code string) error
+}
+
+type medicationRepo struct {
+	client *gorm.DB
+}
+
+func NewMedicationRepo(client *gorm.DB) IMedicationRepository {
+	return &medicationRepo{
+		client: client,
+	}
+}
+
+func (m *medicationRepo) Create(medication *Medication) (string, error) {
+	if result := m.client.Create(medication); result.Error != nil {
+		return "", result.Error
+	}
+	return medication.Code, nil
+}
+
+func (m *medicationRepo) Get(code string) (Medication, error) {
+	var medication Medication
+	if result := m.client.Where("code = ?", code).First(&medication); result.Error != nil {
+		return Medication{}, result.Error
+	}
+	return medication, nil
+}
+
+func (m *medicationRepo) List() ([]Medication, error) {
+	medications := []Medication{}
+	if result := m.client.Order("code").Find(&medications); result.Error != nil {
+		return nil, result.Error
+	}
+	return medications, nil
+}
+
+func (m *medicationRepo) Update(medication *Medication) error {
+	result := m.client.Model(&Medication{}).Where("code = ?", medication.Code).Updates(map[string]interface{}{
+		"name":   medication.Name,
+		"weight": medication.Weight,
+		"image":  medication.Image,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+func (m *medicationRepo) Delete(code string) error {
+	result := m.client.Where("code = ?", code).Delete(&Medication{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_medicationRepo_Create(t *testing.T) {
+	tests := []struct {
+		name       string
+		fixtures   []Medication
+		medication *Medication
+		want       string
+		wantErr    bool
+	}{
+		{
+			name:     "test create medication in catalog",
+			fixtures: []Medication{},
+			medication: &Medication{
+				Name:   "medication",
+				Code:   "code 1",
+				Weight: 10,
+				Image:  "http://test/image",
+			},
+			want:    "code 1",
+			wantErr: false,
+		},
+		{
+			name: "test can not create medication with dublicate code",
+			fixtures: []Medication{
+				{
+					Name:   "medication 1",
+					Code:   "code 1",
+					Weight: 10,
+				},
+			},
+			medication: &Medication{
+				Name:   "medication 2",
+				Code:   "code 1",
+				Weight: 10,
+			},
+			want:    "",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// clear all record
+			db.Where("1 = 1").Delete(&Medication{})
+			trx := db.Begin()
+			defer trx.Rollback()
+			if len(tt.fixtures) > 0 {
+				if result := trx.Create(&tt.fixtures); result.Error != nil {
+					t.Errorf("Can't create fixtures: %v", result.Error)
+				}
+			}
+			m := &medicationRepo{
+				client: trx,
+			}
+			got, err := m.Create(tt.medication)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("medicationRepo.Create() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("medicationRepo.Create() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_medicationRepo_Get(t *testing.T) {
+	tests := []struct {
+		name     string
+		fixtures []Medication
+		code     string
+		want     Medication
+		wantErr  error
+	}{
+		{
+			name:     "test get medication that not exist",
+			fixtures: []Medication{},
+			code:     "code 1",
+			want:     Medication{},
+			wantErr:  ErrRecordNotFound,
+		},
+		{
+			name: "test get medication that exist",
+			fixtures: []Medication{
+				{
+					Name:   "medication",
+					Code:   "code 1",
+					Weight: 10,
+				},
+			},
+			code: "code 1",
+			want: Medication{
+				Name:   "medication",
+				Code:   "code 1",
+				Weight: 10,
+			},
+			wantErr: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// clear all record
+			db.Where("1 = 1").Delete(&Medication{})
+			trx := db.Begin()
+			defer trx.Rollback()
+			if len(tt.fixtures) > 0 {
+				if result := trx.Create(&tt.fixtures); result.Error != nil {
+					t.Errorf("Can't create fixtures: %v", result.Error)
+				}
+			}
+			m := &medicationRepo{
+				client: trx,
+			}
+			got, err := m.Get(tt.code)
+			if err != tt.wantErr {
+				t.Errorf("medicationRepo.Get() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("medicationRepo.Get() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_medicationRepo_UpdateAndDelete(t *testing.T) {
+	// clear all record
+	db.Where("1 = 1").Delete(&Medication{})
+	trx := db.Begin()
+	defer trx.Rollback()
+	fixtures := []Medication{
+		{
+			Name:   "medication",
+			Code:   "code 1",
+			Weight: 10,
+		},
+	}
+	if result := trx.Create(&fixtures); result.Error != nil {
+		t.Errorf("Can't create fixtures: %v", result.Error)
+	}
+	m := &medicationRepo{
+		client: trx,
+	}
+	if err := m.Update(&Medication{Name: "medication updated", Code: "code 1", Weight: 20}); err != nil {
+		t.Errorf("medicationRepo.Update() error = %v", err)
+	}
+	got, _ := m.Get("code 1")
+	if got.Name != "medication updated" || got.Weight != 20 {
+		t.Errorf("medicationRepo.Update() = %v, want updated name and weight", got)
+	}
+	if err := m.Update(&Medication{Name: "medication", Code: "code 2", Weight: 20}); err != ErrRecordNotFound {
+		t.Errorf("medicationRepo.Update() error = %v, wantErr %v", err, ErrRecordNotFound)
+	}
+	if err := m.Delete("code 1"); err != nil {
+		t.Errorf("medicationRepo.Delete() error = %v", err)
+	}
+	if err := m.Delete("code 1"); err != ErrRecordNotFound {
+		t.Errorf("medicationRepo.Delete() error = %v, wantErr %v", err, ErrRecordNotFound)
+	}
+	medications, _ := m.List()
+	if len(medications) != 0 {
+		t.Errorf("medicationRepo.List() = %v, want empty catalog", medications)
+	}
+}
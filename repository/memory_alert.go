@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"drone/v2/clock"
+	"sync"
+)
+
+type memoryAlertRepo struct {
+	mu     sync.Mutex
+	lastID int
+	alerts []Alert
+	clock  clock.Clock
+}
+
+func NewMemoryAlertRepo(clock clock.Clock) IAlertRepository {
+	return &memoryAlertRepo{
+		clock: clock,
+	}
+}
+
+func (a *memoryAlertRepo) Create(alert *Alert) (int, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.lastID++
+	alert.ID = a.lastID
+	if alert.Status == "" {
+		alert.Status = "OPEN"
+	}
+	alert.CreatedAt = a.clock.Now()
+	a.alerts = append(a.alerts, copyAlert(*alert))
+	return alert.ID, nil
+}
+
+func (a *memoryAlertRepo) Get(id int) (Alert, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, alert := range a.alerts {
+		if alert.ID == id {
+			return copyAlert(alert), nil
+		}
+	}
+	return Alert{}, ErrRecordNotFound
+}
+
+func (a *memoryAlertRepo) List(filter AlertFilter) ([]Alert, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	alerts := []Alert{}
+	for i := len(a.alerts) - 1; i >= 0; i-- {
+		alert := a.alerts[i]
+		if (filter.DroneID != 0 && alert.DroneID != filter.DroneID) || (filter.Status != "" && alert.Status != filter.Status) {
+			continue
+		}
+		alerts = append(alerts, copyAlert(alert))
+	}
+	return alerts, nil
+}
+
+func (a *memoryAlertRepo) FindOpen(droneID int, rule string) (Alert, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, alert := range a.alerts {
+		if alert.DroneID == droneID && alert.Rule == rule && alert.Status != "RESOLVED" {
+			return copyAlert(alert), nil
+		}
+	}
+	return Alert{}, ErrRecordNotFound
+}
+
+func (a *memoryAlertRepo) Update(alert Alert, from string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for i := range a.alerts {
+		stored := &a.alerts[i]
+		if stored.ID != alert.ID {
+			continue
+		}
+		if stored.Status != from {
+			return ErrAlertChanged
+		}
+		stored.Status = alert.Status
+		stored.AcknowledgedAt = copyTime(alert.AcknowledgedAt)
+		stored.ResolvedAt = copyTime(alert.ResolvedAt)
+		return nil
+	}
+	return ErrRecordNotFound
+}
+
+func copyAlert(alert Alert) Alert {
+	alert.AcknowledgedAt = copyTime(alert.AcknowledgedAt)
+	alert.ResolvedAt = copyTime(alert.ResolvedAt)
+	return alert
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+var ErrDuplicateDock = errors.New("dock name already exists")
+
+type memoryDockRepo struct {
+	mu     sync.Mutex
+	lastID int
+	docks  map[int]Dock
+}
+
+func NewMemoryDockRepo() IDockRepository {
+	return &memoryDockRepo{
+		docks: map[int]Dock{},
+	}
+}
+
+func (d *memoryDockRepo) Create(dock *Dock) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, o := range d.docks {
+		if o.Name == dock.Name {
+			return 0, ErrDuplicateDock
+		}
+	}
+	d.lastID++
+	dock.ID = d.lastID
+	d.docks[dock.ID] = *dock
+	return dock.ID, nil
+}
+
+func (d *memoryDockRepo) Get(id int) (Dock, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	dock, found := d.docks[id]
+	if !found {
+		return Dock{}, ErrRecordNotFound
+	}
+	return dock, nil
+}
+
+func (d *memoryDockRepo) List() ([]Dock, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	docks := make([]Dock, 0, len(d.docks))
+	for _, dock := range d.docks {
+		docks = append(docks, dock)
+	}
+	sort.Slice(docks, func(i, j int) bool {
+		return docks[i].ID < docks[j].ID
+	})
+	return docks, nil
+}
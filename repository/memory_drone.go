@@ -0,0 +1,481 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrDuplicateSerialNumber = errors.New("drone serial number already exists")
+	ErrSerialNumberTooLong   = errors.New("drone serial number is longer than 100 characters")
+)
+
+// memoryDroneRepo keeps the drones in memory with the same behaviour as droneRepo,
+// decommissioned drones are kept hidden so their serial numbers stay taken.
+type memoryDroneRepo struct {
+	mu         sync.Mutex
+	lastID     int
+	lastItemID int
+	drones     map[int]*Drone
+	deleted    map[int]bool
+	logRepo    ILogRepository
+}
+
+func NewMemoryDroneRepo(logRepo ILogRepository) IDroneRepository {
+	return &memoryDroneRepo{
+		drones:  map[int]*Drone{},
+		deleted: map[int]bool{},
+		logRepo: logRepo,
+	}
+}
+
+func (d *memoryDroneRepo) Create(drone *Drone) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(drone.SerialNumber) > 100 {
+		return 0, ErrSerialNumberTooLong
+	}
+	for id, o := range d.drones {
+		if o.SerialNumber == drone.SerialNumber && id != drone.ID {
+			return 0, ErrDuplicateSerialNumber
+		}
+	}
+	if drone.ID == 0 {
+		d.lastID++
+		drone.ID = d.lastID
+	} else if drone.ID > d.lastID {
+		d.lastID = drone.ID
+	}
+	if drone.State == "" {
+		drone.State = "IDLE"
+	}
+	if drone.BatteryCapacity == 0 {
+		drone.BatteryCapacity = 100
+	}
+	if drone.Version == 0 {
+		drone.Version = 1
+	}
+	for i := range drone.Medications {
+		d.lastItemID++
+		drone.Medications[i].ID = d.lastItemID
+		drone.Medications[i].DroneID = drone.ID
+	}
+	stored := copyDrone(*drone)
+	d.drones[drone.ID] = &stored
+	delete(d.deleted, drone.ID)
+	return drone.ID, nil
+}
+
+func (d *memoryDroneRepo) Get(id int) (Drone, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	drone, err := d.get(id)
+	if err != nil {
+		return Drone{}, err
+	}
+	return copyDrone(*drone), nil
+}
+
+func (d *memoryDroneRepo) List(filter DroneFilter) ([]Drone, int64, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	less, err := droneOrder(filter.Order)
+	if err != nil {
+		return nil, 0, err
+	}
+	matched := []Drone{}
+	for id, drone := range d.drones {
+		if d.deleted[id] ||
+			(filter.SerialNumber != "" && drone.SerialNumber != filter.SerialNumber) ||
+			(filter.State != "" && drone.State != filter.State) ||
+			(filter.Model != "" && drone.Model != filter.Model) ||
+			(filter.MinBattery > 0 && drone.BatteryCapacity < filter.MinBattery) ||
+			(filter.DockID > 0 && (drone.DockID == nil || *drone.DockID != filter.DockID)) {
+			continue
+		}
+		matched = append(matched, copyDrone(*drone))
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		return less(matched[i], matched[j])
+	})
+	total := int64(len(matched))
+	if filter.Offset >= len(matched) {
+		return []Drone{}, total, nil
+	}
+	matched = matched[filter.Offset:]
+	if filter.Limit > 0 && filter.Limit < len(matched) {
+		matched = matched[:filter.Limit]
+	}
+	return matched, total, nil
+}
+
+func (d *memoryDroneRepo) Update(drone *Drone) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	stored, err := d.getVersion(drone.ID, drone.Version)
+	if err != nil {
+		return err
+	}
+	stored.Model = drone.Model
+	stored.Weight = drone.Weight
+	stored.HomeLatitude = drone.HomeLatitude
+	stored.HomeLongitude = drone.HomeLongitude
+	stored.Version++
+	return nil
+}
+
+func (d *memoryDroneRepo) Decommission(id int, version int) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	drone, err := d.getVersion(id, version)
+	if err != nil {
+		return err
+	}
+	d.deleted[id] = true
+	return d.log(drone, "drone decommissioned")
+}
+
+func (d *memoryDroneRepo) AddMedication(id int, version int, item *LoadItem) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	drone, err := d.getVersion(id, version)
+	if err != nil {
+		return err
+	}
+	d.lastItemID++
+	item.ID = d.lastItemID
+	item.DroneID = id
+	drone.Medications = append(drone.Medications, *item)
+	drone.CurrentPayload += item.Weight
+	drone.Version++
+	return nil
+}
+
+func (d *memoryDroneRepo) RecalculatePayload(id int) (float32, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	drone, err := d.get(id)
+	if err != nil {
+		return 0, err
+	}
+	var payload float32
+	for _, item := range drone.Medications {
+		payload += item.Weight
+	}
+	drone.CurrentPayload = payload
+	drone.Version++
+	return payload, nil
+}
+
+func (d *memoryDroneRepo) RemoveMedication(id int, version int, code string) error {
+	return d.removeItems(id, version, fmt.Sprintf("medication %s unloaded", code), func(item LoadItem) bool {
+		return item.MedicationCode == code
+	})
+}
+
+func (d *memoryDroneRepo) RemoveItems(id int, version int, itemIDs []int) error {
+	removed := map[int]bool{}
+	for _, itemID := range itemIDs {
+		removed[itemID] = true
+	}
+	return d.removeItems(id, version, fmt.Sprintf("%d load items unloaded", len(itemIDs)), func(item LoadItem) bool {
+		return removed[item.ID]
+	})
+}
+
+func (d *memoryDroneRepo) Unload(id int, version int) error {
+	return d.removeItems(id, version, "all medications unloaded", func(item LoadItem) bool {
+		return true
+	})
+}
+
+func (d *memoryDroneRepo) removeItems(id int, version int, description string, match func(item LoadItem) bool) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	drone, err := d.get(id)
+	if err != nil {
+		return err
+	}
+	kept := []LoadItem{}
+	var weight float32
+	for _, item := range drone.Medications {
+		if match(item) {
+			weight += item.Weight
+			continue
+		}
+		kept = append(kept, item)
+	}
+	if len(kept) == len(drone.Medications) {
+		return ErrRecordNotFound
+	}
+	if drone.Version != version {
+		return ErrConcurrentUpdate
+	}
+	drone.Medications = kept
+	drone.CurrentPayload -= weight
+	drone.Version++
+	return d.log(drone, description)
+}
+
+func (d *memoryDroneRepo) CheckLoadingMedication(id int) (string, error) {
+	drone, err := d.Get(id)
+	if err != nil {
+		return "", err
+	}
+	return drone.State, nil
+}
+
+func (d *memoryDroneRepo) AvailableDroneForLoading() []Drone {
+	drones, _, _ := d.List(DroneFilter{State: "IDLE"})
+	available := []Drone{}
+	for _, drone := range drones {
+		if !drone.Grounded {
+			available = append(available, drone)
+		}
+	}
+	return available
+}
+
+func (d *memoryDroneRepo) CheckBatteryLevel(id int) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	drone, err := d.get(id)
+	if err != nil {
+		return 0, err
+	}
+	return drone.BatteryCapacity, nil
+}
+
+func (d *memoryDroneRepo) UpdateBatteries(levels map[int]int, description string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, id := range sortedIDs(levels) {
+		drone, err := d.get(id)
+		if err != nil {
+			continue
+		}
+		drone.BatteryCapacity = levels[id]
+		if err := d.log(drone, description); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *memoryDroneRepo) ChangeDroneState(id int, from string, to string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	drone, err := d.get(id)
+	if err != nil {
+		return err
+	}
+	if drone.State != from {
+		return ErrConcurrentUpdate
+	}
+	drone.State = to
+	drone.Version++
+	return d.log(drone, fmt.Sprintf("state changed from %s to %s", from, to))
+}
+
+func (d *memoryDroneRepo) Dock(id int, dockID int, slots int) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	drone, err := d.get(id)
+	if err != nil {
+		return err
+	}
+	if drone.DockID != nil {
+		return ErrAlreadyDocked
+	}
+	docked := 0
+	for o, other := range d.drones {
+		if !d.deleted[o] && other.DockID != nil && *other.DockID == dockID {
+			docked++
+		}
+	}
+	if docked >= slots {
+		return ErrDockFull
+	}
+	drone.DockID = &dockID
+	return d.log(drone, fmt.Sprintf("docked at dock %d", dockID))
+}
+
+func (d *memoryDroneRepo) Undock(id int) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	drone, err := d.get(id)
+	if err != nil {
+		return err
+	}
+	if drone.DockID == nil {
+		return ErrNotDocked
+	}
+	dockID := *drone.DockID
+	drone.DockID = nil
+	return d.log(drone, fmt.Sprintf("undocked from dock %d", dockID))
+}
+
+func (d *memoryDroneRepo) UpdatePosition(id int, latitude float64, longitude float64) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	drone, err := d.get(id)
+	if err != nil {
+		return err
+	}
+	drone.Latitude = latitude
+	drone.Longitude = longitude
+	return nil
+}
+
+func (d *memoryDroneRepo) SetDestination(id int, latitude *float64, longitude *float64) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	drone, err := d.get(id)
+	if err != nil {
+		return err
+	}
+	drone.DestinationLatitude, drone.DestinationLongitude = nil, nil
+	if latitude != nil && longitude != nil {
+		lat, lng := *latitude, *longitude
+		drone.DestinationLatitude, drone.DestinationLongitude = &lat, &lng
+	}
+	return d.log(drone, destinationDescription(drone.DestinationLatitude, drone.DestinationLongitude))
+}
+
+func (d *memoryDroneRepo) UpdateSnapshot(id int, reading Telemetry) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	drone, err := d.get(id)
+	if err != nil {
+		return err
+	}
+	if drone.TelemetryAt != nil && !reading.RecordedAt.After(*drone.TelemetryAt) {
+		return nil
+	}
+	battery := drone.BatteryCapacity
+	recordedAt := reading.RecordedAt
+	if reading.BatteryLevel != nil {
+		drone.BatteryCapacity = int(math.Round(*reading.BatteryLevel))
+	}
+	if reading.Latitude != nil && reading.Longitude != nil {
+		drone.Latitude, drone.Longitude = *reading.Latitude, *reading.Longitude
+	}
+	if reading.Altitude != nil {
+		drone.Altitude = *reading.Altitude
+	}
+	if reading.Speed != nil {
+		drone.Speed = *reading.Speed
+	}
+	drone.TelemetryAt = &recordedAt
+	if drone.BatteryCapacity == battery {
+		return nil
+	}
+	return d.log(drone, snapshotDescription(reading))
+}
+
+func (d *memoryDroneRepo) SetGrounded(id int, grounded bool) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	drone, err := d.get(id)
+	if err != nil {
+		return err
+	}
+	if drone.Grounded == grounded {
+		return nil
+	}
+	drone.Grounded = grounded
+	drone.Version++
+	return d.log(drone, groundedDescription(grounded))
+}
+
+func (d *memoryDroneRepo) get(id int) (*Drone, error) {
+	drone, found := d.drones[id]
+	if !found || d.deleted[id] {
+		return nil, ErrRecordNotFound
+	}
+	return drone, nil
+}
+
+// getVersion returns the drone only if it still has the given version.
+func (d *memoryDroneRepo) getVersion(id int, version int) (*Drone, error) {
+	drone, err := d.get(id)
+	if err != nil {
+		return nil, err
+	}
+	if drone.Version != version {
+		return nil, ErrConcurrentUpdate
+	}
+	return drone, nil
+}
+
+func (d *memoryDroneRepo) log(drone *Drone, description string) error {
+	if d.logRepo == nil {
+		return nil
+	}
+	return d.logRepo.Create(Log{
+		DroneID:         drone.ID,
+		BatteryCapacity: drone.BatteryCapacity,
+		DroneState:      drone.State,
+		Description:     description,
+	})
+}
+
+func copyDrone(drone Drone) Drone {
+	drone.Medications = append([]LoadItem{}, drone.Medications...)
+	return drone
+}
+
+// droneOrder builds the comparison of an order clause like "battery_capacity desc",
+// drones that compare equal are ordered by id.
+func droneOrder(order string) (func(a Drone, b Drone) bool, error) {
+	column, direction := order, "asc"
+	if fields := strings.Fields(order); len(fields) == 2 {
+		column, direction = fields[0], strings.ToLower(fields[1])
+	}
+	var compare func(a Drone, b Drone) int
+	switch column {
+	case "", "id":
+		compare = func(a Drone, b Drone) int { return a.ID - b.ID }
+	case "serial_number":
+		compare = func(a Drone, b Drone) int { return strings.Compare(a.SerialNumber, b.SerialNumber) }
+	case "model":
+		compare = func(a Drone, b Drone) int { return strings.Compare(a.Model, b.Model) }
+	case "state":
+		compare = func(a Drone, b Drone) int { return strings.Compare(a.State, b.State) }
+	case "weight":
+		compare = func(a Drone, b Drone) int { return compareFloat(a.Weight, b.Weight) }
+	case "battery_capacity":
+		compare = func(a Drone, b Drone) int { return a.BatteryCapacity - b.BatteryCapacity }
+	case "current_payload":
+		compare = func(a Drone, b Drone) int { return compareFloat(a.CurrentPayload, b.CurrentPayload) }
+	default:
+		return nil, errors.New(fmt.Sprintf("can not order drones by %s", column))
+	}
+	if direction != "asc" && direction != "desc" {
+		return nil, errors.New(fmt.Sprintf("can not order drones in %s direction", direction))
+	}
+	return func(a Drone, b Drone) bool {
+		result := compare(a, b)
+		if result == 0 {
+			return a.ID < b.ID
+		}
+		if direction == "desc" {
+			return result > 0
+		}
+		return result < 0
+	}, nil
+}
+
+func compareFloat(a float32, b float32) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"drone/v2/clock"
+	"sync"
+)
+
+type memoryLogRepo struct {
+	mu     sync.Mutex
+	lastID int
+	logs   []Log
+	clock  clock.Clock
+}
+
+func NewMemoryLogRepository(clock clock.Clock) ILogRepository {
+	return &memoryLogRepo{clock: clock}
+}
+
+func (l *memoryLogRepo) Create(log Log) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if log.ID == 0 {
+		l.lastID++
+		log.ID = l.lastID
+	} else if log.ID > l.lastID {
+		l.lastID = log.ID
+	}
+	now := l.clock.Now()
+	if log.CreatedAt.IsZero() {
+		log.CreatedAt = now
+	}
+	log.UpdatedAt = now
+	l.logs = append(l.logs, log)
+	return nil
+}
+
+func (l *memoryLogRepo) List() ([]Log, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]Log{}, l.logs...), nil
+}
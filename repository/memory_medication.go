@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+var ErrDuplicateMedication = errors.New("medication code or name already exists")
+
+type memoryMedicationRepo struct {
+	mu          sync.Mutex
+	medications map[string]Medication
+}
+
+func NewMemoryMedicationRepo() IMedicationRepository {
+	return &memoryMedicationRepo{
+		medications: map[string]Medication{},
+	}
+}
+
+func (m *memoryMedicationRepo) Create(medication *Medication) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for code, o := range m.medications {
+		if code == medication.Code || o.Name == medication.Name {
+			return "", ErrDuplicateMedication
+		}
+	}
+	m.medications[medication.Code] = *medication
+	return medication.Code, nil
+}
+
+func (m *memoryMedicationRepo) Get(code string) (Medication, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	medication, found := m.medications[code]
+	if !found {
+		return Medication{}, ErrRecordNotFound
+	}
+	return medication, nil
+}
+
+func (m *memoryMedicationRepo) List() ([]Medication, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	medications := make([]Medication, 0, len(m.medications))
+	for _, medication := range m.medications {
+		medications = append(medications, medication)
+	}
+	sort.Slice(medications, func(i, j int) bool {
+		return medications[i].Code < medications[j].Code
+	})
+	return medications, nil
+}
+
+func (m *memoryMedicationRepo) Update(medication *Medication) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, found := m.medications[medication.Code]; !found {
+		return ErrRecordNotFound
+	}
+	for code, o := range m.medications {
+		if code != medication.Code && o.Name == medication.Name {
+			return ErrDuplicateMedication
+		}
+	}
+	m.medications[medication.Code] = *medication
+	return nil
+}
+
+func (m *memoryMedicationRepo) Delete(code string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, found := m.medications[code]; !found {
+		return ErrRecordNotFound
+	}
+	delete(m.medications, code)
+	return nil
+}
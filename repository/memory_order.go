@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"drone/v2/clock"
+	"sort"
+	"sync"
+)
+
+type memoryOrderRepo struct {
+	mu         sync.Mutex
+	lastID     int
+	lastItemID int
+	orders     map[int]*Order
+	clock      clock.Clock
+}
+
+func NewMemoryOrderRepo(clock clock.Clock) IOrderRepository {
+	return &memoryOrderRepo{
+		orders: map[int]*Order{},
+		clock:  clock,
+	}
+}
+
+func (o *memoryOrderRepo) Create(order *Order) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.lastID++
+	order.ID = o.lastID
+	if order.Status == "" {
+		order.Status = "PENDING"
+	}
+	now := o.clock.Now()
+	order.CreatedAt = now
+	order.UpdatedAt = now
+	for i := range order.Items {
+		o.lastItemID++
+		order.Items[i].ID = o.lastItemID
+		order.Items[i].OrderID = order.ID
+		if order.Items[i].Quantity == 0 {
+			order.Items[i].Quantity = 1
+		}
+	}
+	stored := copyOrder(*order)
+	o.orders[order.ID] = &stored
+	return order.ID, nil
+}
+
+func (o *memoryOrderRepo) Get(id int) (Order, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	order, found := o.orders[id]
+	if !found {
+		return Order{}, ErrRecordNotFound
+	}
+	return copyOrder(*order), nil
+}
+
+func (o *memoryOrderRepo) List(status string) ([]Order, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	orders := []Order{}
+	for _, order := range o.orders {
+		if order.Status == status {
+			orders = append(orders, copyOrder(*order))
+		}
+	}
+	sort.Slice(orders, func(i, j int) bool {
+		if orders[i].Priority != orders[j].Priority {
+			return orders[i].Priority > orders[j].Priority
+		}
+		return orders[i].ID < orders[j].ID
+	})
+	return orders, nil
+}
+
+func (o *memoryOrderRepo) Assign(id int, status string, droneID int, jobs map[int]int) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	order, found := o.orders[id]
+	if !found {
+		return ErrRecordNotFound
+	}
+	order.Status = status
+	order.DroneID = &droneID
+	for i, item := range order.Items {
+		if jobID, found := jobs[item.ID]; found {
+			order.Items[i].JobID = jobID
+		}
+	}
+	order.UpdatedAt = o.clock.Now()
+	return nil
+}
+
+func (o *memoryOrderRepo) UpdateStatus(id int, from string, to string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	order, found := o.orders[id]
+	if !found {
+		return ErrRecordNotFound
+	}
+	if order.Status != from {
+		return ErrOrderChanged
+	}
+	order.Status = to
+	order.UpdatedAt = o.clock.Now()
+	return nil
+}
+
+func copyOrder(order Order) Order {
+	order.Items = append([]OrderItem{}, order.Items...)
+	return order
+}
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+type memoryTelemetryRepo struct {
+	mu       sync.Mutex
+	lastID   int
+	readings []Telemetry
+}
+
+func NewMemoryTelemetryRepo() ITelemetryRepository {
+	return &memoryTelemetryRepo{}
+}
+
+func (t *memoryTelemetryRepo) Create(readings []Telemetry) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for i := range readings {
+		t.lastID++
+		readings[i].ID = t.lastID
+		if readings[i].Samples == 0 {
+			readings[i].Samples = 1
+		}
+		t.readings = append(t.readings, readings[i])
+	}
+	return nil
+}
+
+func (t *memoryTelemetryRepo) List(filter TelemetryFilter) ([]Telemetry, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	readings := []Telemetry{}
+	for _, reading := range t.readings {
+		if reading.DroneID != filter.DroneID ||
+			(!filter.From.IsZero() && reading.RecordedAt.Before(filter.From)) ||
+			(!filter.To.IsZero() && !reading.RecordedAt.Before(filter.To)) {
+			continue
+		}
+		readings = append(readings, reading)
+	}
+	sort.SliceStable(readings, func(i, j int) bool {
+		if !readings[i].RecordedAt.Equal(readings[j].RecordedAt) {
+			return readings[i].RecordedAt.Before(readings[j].RecordedAt)
+		}
+		return readings[i].ID < readings[j].ID
+	})
+	if filter.Limit > 0 && len(readings) > filter.Limit {
+		readings = readings[:filter.Limit]
+	}
+	return readings, nil
+}
+
+func (t *memoryTelemetryRepo) DeleteBefore(before time.Time) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	kept := t.readings[:0]
+	for _, reading := range t.readings {
+		if !reading.RecordedAt.Before(before) {
+			kept = append(kept, reading)
+		}
+	}
+	deleted := len(t.readings) - len(kept)
+	t.readings = kept
+	return deleted, nil
+}
+
+func (t *memoryTelemetryRepo) Downsample(from, before time.Time, bucket time.Duration) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	page := func(cursor telemetryCursor) ([]Telemetry, error) {
+		readings := []Telemetry{}
+		for _, reading := range t.readings {
+			if !reading.RecordedAt.Before(before) || reading.RecordedAt.Before(from) {
+				continue
+			}
+			if reading.DroneID < cursor.droneID || (reading.DroneID == cursor.droneID && reading.RecordedAt.Before(cursor.recordedAt)) {
+				continue
+			}
+			readings = append(readings, reading)
+		}
+		sort.SliceStable(readings, func(i, j int) bool {
+			if readings[i].DroneID != readings[j].DroneID {
+				return readings[i].DroneID < readings[j].DroneID
+			}
+			if !readings[i].RecordedAt.Equal(readings[j].RecordedAt) {
+				return readings[i].RecordedAt.Before(readings[j].RecordedAt)
+			}
+			return readings[i].ID < readings[j].ID
+		})
+		if len(readings) > telemetryBatchSize {
+			readings = readings[:telemetryBatchSize]
+		}
+		return readings, nil
+	}
+	merge := func(merged []Telemetry, replaced []int) error {
+		removed := map[int]bool{}
+		for _, id := range replaced {
+			removed[id] = true
+		}
+		kept := []Telemetry{}
+		for _, reading := range t.readings {
+			if !removed[reading.ID] {
+				kept = append(kept, reading)
+			}
+		}
+		for _, reading := range merged {
+			t.lastID++
+			reading.ID = t.lastID
+			kept = append(kept, reading)
+		}
+		t.readings = kept
+		return nil
+	}
+	return downsample(bucket, page, merge)
+}
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"sync"
+	"testing"
+)
+
+func Test_memoryDroneRepo_ParallelAddMedication(t *testing.T) {
+	d := NewMemoryDroneRepo(nil)
+	id, _ := d.Create(&Drone{SerialNumber: "serial 1", Weight: 500})
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	added := 0
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			drone, _ := d.Get(id)
+			if d.AddMedication(id, drone.Version, &LoadItem{MedicationCode: "code", Weight: 10}) == nil {
+				mu.Lock()
+				added++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	drone, _ := d.Get(id)
+	if len(drone.Medications) != added || drone.CurrentPayload != float32(added*10) || drone.Version != added+1 {
+		t.Errorf("memoryDroneRepo.AddMedication() = %v, want %v medications", drone, added)
+	}
+}
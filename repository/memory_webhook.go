@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"drone/v2/clock"
+	"sort"
+	"sync"
+	"time"
+)
+
+type memoryWebhookRepo struct {
+	mu             sync.Mutex
+	lastID         int
+	lastDeliveryID int
+	lastAttemptID  int
+	webhooks       map[int]Webhook
+	deliveries     []WebhookDelivery
+	clock          clock.Clock
+}
+
+func NewMemoryWebhookRepo(clock clock.Clock) IWebhookRepository {
+	return &memoryWebhookRepo{
+		webhooks: map[int]Webhook{},
+		clock:    clock,
+	}
+}
+
+func (w *memoryWebhookRepo) Create(webhook *Webhook) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.lastID++
+	webhook.ID = w.lastID
+	webhook.CreatedAt = w.clock.Now()
+	w.webhooks[webhook.ID] = *webhook
+	return webhook.ID, nil
+}
+
+func (w *memoryWebhookRepo) Get(id int) (Webhook, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	webhook, found := w.webhooks[id]
+	if !found {
+		return Webhook{}, ErrRecordNotFound
+	}
+	return webhook, nil
+}
+
+func (w *memoryWebhookRepo) List() ([]Webhook, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	webhooks := make([]Webhook, 0, len(w.webhooks))
+	for _, webhook := range w.webhooks {
+		webhooks = append(webhooks, webhook)
+	}
+	sort.Slice(webhooks, func(i, j int) bool {
+		return webhooks[i].ID < webhooks[j].ID
+	})
+	return webhooks, nil
+}
+
+func (w *memoryWebhookRepo) Delete(id int) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, found := w.webhooks[id]; !found {
+		return ErrRecordNotFound
+	}
+	delete(w.webhooks, id)
+	return nil
+}
+
+func (w *memoryWebhookRepo) CreateDeliveries(deliveries []WebhookDelivery) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	now := w.clock.Now()
+	for i := range deliveries {
+		w.lastDeliveryID++
+		deliveries[i].ID = w.lastDeliveryID
+		if deliveries[i].Status == "" {
+			deliveries[i].Status = "PENDING"
+		}
+		deliveries[i].CreatedAt = now
+		deliveries[i].UpdatedAt = now
+		w.deliveries = append(w.deliveries, copyDelivery(deliveries[i]))
+	}
+	return nil
+}
+
+func (w *memoryWebhookRepo) ListDeliveries(webhookID int) ([]WebhookDelivery, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	deliveries := []WebhookDelivery{}
+	for i := len(w.deliveries) - 1; i >= 0; i-- {
+		if w.deliveries[i].WebhookID == webhookID {
+			deliveries = append(deliveries, copyDelivery(w.deliveries[i]))
+		}
+	}
+	return deliveries, nil
+}
+
+func (w *memoryWebhookRepo) DueDeliveries(now time.Time, limit int) ([]WebhookDelivery, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	deliveries := []WebhookDelivery{}
+	for _, delivery := range w.deliveries {
+		if _, found := w.webhooks[delivery.WebhookID]; !found {
+			continue
+		}
+		if delivery.NextAttemptAt != nil && !delivery.NextAttemptAt.After(now) {
+			delivery = copyDelivery(delivery)
+			delivery.Attempts = nil
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	sort.SliceStable(deliveries, func(i, j int) bool {
+		if !deliveries[i].NextAttemptAt.Equal(*deliveries[j].NextAttemptAt) {
+			return deliveries[i].NextAttemptAt.Before(*deliveries[j].NextAttemptAt)
+		}
+		return deliveries[i].ID < deliveries[j].ID
+	})
+	if limit > 0 && len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, nil
+}
+
+func (w *memoryWebhookRepo) RecordAttempt(delivery WebhookDelivery, attempt WebhookAttempt) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for i := range w.deliveries {
+		stored := &w.deliveries[i]
+		if stored.ID != delivery.ID {
+			continue
+		}
+		stored.Status = delivery.Status
+		stored.AttemptCount = delivery.AttemptCount
+		stored.NextAttemptAt = copyTime(delivery.NextAttemptAt)
+		stored.UpdatedAt = w.clock.Now()
+		w.lastAttemptID++
+		attempt.ID = w.lastAttemptID
+		attempt.DeliveryID = delivery.ID
+		stored.Attempts = append(stored.Attempts, attempt)
+		return nil
+	}
+	return ErrRecordNotFound
+}
+
+func copyDelivery(delivery WebhookDelivery) WebhookDelivery {
+	delivery.Attempts = append([]WebhookAttempt{}, delivery.Attempts...)
+	delivery.NextAttemptAt = copyTime(delivery.NextAttemptAt)
+	return delivery
+}
+
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	copied := *t
+	return &copied
+}
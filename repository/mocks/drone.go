@@ -18,16 +18,56 @@ func (d *droneRepoMock) Create(drone *repo.Drone) (int, error) {
 }
 
 func (d *droneRepoMock) Get(id int) (repo.Drone, error) {
-	return repo.Drone{}, nil
+	return repo.Drone{
+		ID:              id,
+		Weight:          500,
+		State:           "IDLE",
+		BatteryCapacity: 100,
+	}, nil
 }
 
-func (d *droneRepoMock) AddMedication(id int, medication *repo.Medication) error {
+func (d *droneRepoMock) List(filter repo.DroneFilter) ([]repo.Drone, int64, error) {
+	drones := d.AvailableDroneForLoading()
+	return drones, int64(len(drones)), nil
+}
+
+func (d *droneRepoMock) Update(drone *repo.Drone) error {
+	return nil
+}
+
+func (d *droneRepoMock) Decommission(id int, version int) error {
+	return nil
+}
+
+func (d *droneRepoMock) AddMedication(id int, version int, item *repo.LoadItem) error {
+	return nil
+}
+
+func (d *droneRepoMock) RecalculatePayload(id int) (float32, error) {
+	return 0, nil
+}
+
+func (d *droneRepoMock) RemoveMedication(id int, version int, code string) error {
+	if code != "code" {
+		return repo.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (d *droneRepoMock) RemoveItems(id int, version int, itemIDs []int) error {
+	return nil
+}
+
+func (d *droneRepoMock) Unload(id int, version int) error {
 	return nil
 }
 
 var state = []string{"IDLE", "LOADING", "LOADED"}
 
 func (d *droneRepoMock) CheckLoadingMedication(id int) (string, error) {
+	if id < 1 || id > len(state) {
+		return "", repo.ErrRecordNotFound
+	}
 	return state[id-1], nil
 }
 
@@ -39,22 +79,47 @@ func (d *droneRepoMock) AvailableDroneForLoading() []repo.Drone {
 			Weight:       120,
 		},
 		{
-			ID:           2,
-			SerialNumber: "test serial 2",
-			Weight:       120,
+			ID:             2,
+			SerialNumber:   "test serial 2",
+			Weight:         120,
+			CurrentPayload: 100,
 		},
 	}
 }
 func (d *droneRepoMock) CheckBatteryLevel(id int) (int, error) {
 	return 25, nil
 }
-func (d *droneRepoMock) ReduceBatteries() {
-	// reduce all drones batteries
+func (d *droneRepoMock) UpdateBatteries(levels map[int]int, description string) error {
+	return nil
+}
+
+func (d *droneRepoMock) ChangeDroneState(id int, from string, to string) error {
+	return nil
+}
+
+func (d *droneRepoMock) Dock(id int, dockID int, slots int) error {
+	return nil
+}
+
+func (d *droneRepoMock) Undock(id int) error {
+	return nil
+}
+
+func (d *droneRepoMock) UpdatePosition(id int, latitude float64, longitude float64) error {
+	return nil
+}
+
+func (d *droneRepoMock) SetDestination(id int, latitude *float64, longitude *float64) error {
+	return nil
 }
 
-// func (d *droneRepoMock) chnageDroneStatus(id int, state string) error {
-// 	return nil
-// }
+func (d *droneRepoMock) UpdateSnapshot(id int, reading repo.Telemetry) error {
+	return nil
+}
+
+func (d *droneRepoMock) SetGrounded(id int, grounded bool) error {
+	return nil
+}
 
 type droneRepoFailMock struct {
 }
@@ -68,13 +133,41 @@ func (d *droneRepoFailMock) Create(drone *repo.Drone) (int, error) {
 }
 
 func (d *droneRepoFailMock) Get(id int) (repo.Drone, error) {
-	return repo.Drone{}, nil
+	return repo.Drone{}, errors.New(fmt.Sprintf("can not found drone for this id %d", id))
 }
 
-func (d *droneRepoFailMock) AddMedication(id int, medication *repo.Medication) error {
+func (d *droneRepoFailMock) AddMedication(id int, version int, item *repo.LoadItem) error {
 	return nil
 }
 
+func (d *droneRepoFailMock) RecalculatePayload(id int) (float32, error) {
+	return 0, errors.New(fmt.Sprintf("can not found drone for this id %d", id))
+}
+
+func (d *droneRepoFailMock) List(filter repo.DroneFilter) ([]repo.Drone, int64, error) {
+	return nil, 0, errors.New("can not list drones")
+}
+
+func (d *droneRepoFailMock) Update(drone *repo.Drone) error {
+	return errors.New(fmt.Sprintf("can not found drone for this id %d", drone.ID))
+}
+
+func (d *droneRepoFailMock) Decommission(id int, version int) error {
+	return errors.New(fmt.Sprintf("can not found drone for this id %d", id))
+}
+
+func (d *droneRepoFailMock) RemoveMedication(id int, version int, code string) error {
+	return errors.New(fmt.Sprintf("can not found drone for this id %d", id))
+}
+
+func (d *droneRepoFailMock) RemoveItems(id int, version int, itemIDs []int) error {
+	return errors.New(fmt.Sprintf("can not found drone for this id %d", id))
+}
+
+func (d *droneRepoFailMock) Unload(id int, version int) error {
+	return errors.New(fmt.Sprintf("can not found drone for this id %d", id))
+}
+
 func (d *droneRepoFailMock) CheckLoadingMedication(id int) (string, error) {
 	return "", errors.New(fmt.Sprintf("can not found drone for this id %d", id))
 }
@@ -87,10 +180,34 @@ func (d *droneRepoFailMock) CheckBatteryLevel(id int) (int, error) {
 	return 0.0, errors.New(fmt.Sprintf("can not found drone for this id %d", id))
 }
 
-func (d *droneRepoFailMock) ReduceBatteries() {
-	// reduce all drones batteries
+func (d *droneRepoFailMock) UpdateBatteries(levels map[int]int, description string) error {
+	return errors.New("can not update drones batteries")
 }
 
-// func (d *droneRepoFailMock) chnageDroneStatus(id int, state string) error {
-// 	return nil
-// }
+func (d *droneRepoFailMock) ChangeDroneState(id int, from string, to string) error {
+	return repo.ErrConcurrentUpdate
+}
+
+func (d *droneRepoFailMock) Dock(id int, dockID int, slots int) error {
+	return repo.ErrDockFull
+}
+
+func (d *droneRepoFailMock) Undock(id int) error {
+	return repo.ErrNotDocked
+}
+
+func (d *droneRepoFailMock) UpdatePosition(id int, latitude float64, longitude float64) error {
+	return errors.New(fmt.Sprintf("can not found drone for this id %d", id))
+}
+
+func (d *droneRepoFailMock) SetDestination(id int, latitude *float64, longitude *float64) error {
+	return errors.New(fmt.Sprintf("can not found drone for this id %d", id))
+}
+
+func (d *droneRepoFailMock) UpdateSnapshot(id int, reading repo.Telemetry) error {
+	return errors.New(fmt.Sprintf("can not found drone for this id %d", id))
+}
+
+func (d *droneRepoFailMock) SetGrounded(id int, grounded bool) error {
+	return errors.New(fmt.Sprintf("can not found drone for this id %d", id))
+}
@@ -0,0 +1,51 @@
+package mocks
+
+import (
+	repo "drone/v2/repository"
+)
+
+type medicationRepoMock struct {
+}
+
+func NewMedicationRepoMock() repo.IMedicationRepository {
+	return &medicationRepoMock{}
+}
+
+func (m *medicationRepoMock) Create(medication *repo.Medication) (string, error) {
+	return medication.Code, nil
+}
+
+func (m *medicationRepoMock) Get(code string) (repo.Medication, error) {
+	if code != "code" {
+		return repo.Medication{}, repo.ErrRecordNotFound
+	}
+	return repo.Medication{
+		Name:   "medication",
+		Code:   code,
+		Weight: 10,
+	}, nil
+}
+
+func (m *medicationRepoMock) List() ([]repo.Medication, error) {
+	return []repo.Medication{
+		{
+			Name:   "medication",
+			Code:   "code",
+			Weight: 10,
+		},
+	}, nil
+}
+
+func (m *medicationRepoMock) Update(medication *repo.Medication) error {
+	if medication.Code != "code" {
+		return repo.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (m *medicationRepoMock) Delete(code string) error {
+	if code != "code" {
+		return repo.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// ErrOrderChanged is returned when an order is not in the status it was read with anymore.
+var ErrOrderChanged = errors.New("order was modified by another request")
+
+type IOrderRepository interface {
+	Create(order *Order) (int, error)
+	Get(id int) (Order, error)
+	List(status string) ([]Order, error)
+	Assign(id int, status string, droneID int, jobs map[int]int) error
+	UpdateStatus(id int, from string, to string) error
+}
+
+type orderRepo struct {
+	client *gorm.DB
+}
+
+func NewOrderRepo(client *gorm.DB) IOrderRepository {
+	return &orderRepo{
+		client: client,
+	}
+}
+
+// Create saves the order with its items.
+func (o *orderRepo) Create(order *Order) (int, error) {
+	if result := o.client.Create(order); result.Error != nil {
+		return 0, result.Error
+	}
+	return order.ID, nil
+}
+
+func (o *orderRepo) Get(id int) (Order, error) {
+	var order Order
+	result := o.client.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).First(&order, id)
+	if result.Error != nil {
+		return Order{}, result.Error
+	}
+	return order, nil
+}
+
+// List returns the orders in the status with the highest priority first, then the oldest first.
+func (o *orderRepo) List(status string) ([]Order, error) {
+	orders := []Order{}
+	result := o.client.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Where("status = ?", status).Order("priority desc, id").Find(&orders)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return orders, nil
+}
+
+// Assign sets the drone the order is loaded onto, its status and the loading job of every item by item id.
+func (o *orderRepo) Assign(id int, status string, droneID int, jobs map[int]int) error {
+	return o.client.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&Order{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"status":   status,
+			"drone_id": droneID,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrRecordNotFound
+		}
+		for _, itemID := range sortedIDs(jobs) {
+			result := tx.Model(&OrderItem{}).Where("id = ? AND order_id = ?", itemID, id).Update("job_id", jobs[itemID])
+			if result.Error != nil {
+				return result.Error
+			}
+		}
+		return nil
+	})
+}
+
+// UpdateStatus moves the order from one status to another, it fails with ErrOrderChanged if the order is not in from status.
+func (o *orderRepo) UpdateStatus(id int, from string, to string) error {
+	return o.client.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&Order{}).Where("id = ? AND status = ?", id, from).Update("status", to)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			var count int64
+			if result := tx.Model(&Order{}).Where("id = ?", id).Count(&count); result.Error != nil {
+				return result.Error
+			}
+			if count == 0 {
+				return ErrRecordNotFound
+			}
+			return ErrOrderChanged
+		}
+		return nil
+	})
+}
@@ -0,0 +1,74 @@
+package repotest
+
+import (
+	repo "drone/v2/repository"
+	"testing"
+	"time"
+)
+
+// RunAlerts checks the contract of IAlertRepository.
+func RunAlerts(t *testing.T, factory Factory) {
+	t.Run("CreateGetList", func(t *testing.T) {
+		r := factory(t)
+		alerts, err := r.Alerts.List(repo.AlertFilter{})
+		if err != nil || alerts == nil || len(alerts) != 0 {
+			t.Errorf("List() of empty storage = %v, %v, want an empty list", alerts, err)
+		}
+		alert := repo.Alert{DroneID: 1, Rule: "low-battery", Severity: "WARNING", Message: "battery is low", BatteryLevel: 20, DroneState: "LOADED"}
+		id, err := r.Alerts.Create(&alert)
+		if err != nil || id == 0 || id != alert.ID {
+			t.Fatalf("Create() = %v, %v, want the id of the alert", id, err)
+		}
+		otherID, _ := r.Alerts.Create(&repo.Alert{DroneID: 2, Rule: "low-battery", Status: "ACKNOWLEDGED"})
+		got, err := r.Alerts.Get(id)
+		if err != nil || got.Status != "OPEN" || got.Rule != alert.Rule || got.BatteryLevel != 20 || got.DroneState != "LOADED" ||
+			!got.CreatedAt.Equal(r.Clock.Now()) || got.AcknowledgedAt != nil || got.ResolvedAt != nil {
+			t.Errorf("Get() = %+v, %v, want %+v OPEN and created now", got, err, alert)
+		}
+		_, err = r.Alerts.Get(missingID)
+		wantError(t, "Get() of a missing alert", err, repo.ErrRecordNotFound)
+
+		if alerts, _ := r.Alerts.List(repo.AlertFilter{}); len(alerts) != 2 || alerts[0].ID != otherID {
+			t.Errorf("List() = %+v, want the 2 alerts, the newest first", alerts)
+		}
+		if alerts, _ := r.Alerts.List(repo.AlertFilter{DroneID: 1}); len(alerts) != 1 || alerts[0].ID != id {
+			t.Errorf("List() of drone 1 = %+v, want its alert", alerts)
+		}
+		if alerts, _ := r.Alerts.List(repo.AlertFilter{Status: "ACKNOWLEDGED"}); len(alerts) != 1 || alerts[0].ID != otherID {
+			t.Errorf("List() of ACKNOWLEDGED alerts = %+v, want the other alert", alerts)
+		}
+	})
+	t.Run("FindOpenUpdate", func(t *testing.T) {
+		r := factory(t)
+		now := r.Clock.Now()
+		later := now.Add(time.Minute)
+		id, _ := r.Alerts.Create(&repo.Alert{DroneID: 1, Rule: "low-battery"})
+		r.Alerts.Create(&repo.Alert{DroneID: 1, Rule: "fast-drain"})
+
+		if open, err := r.Alerts.FindOpen(1, "low-battery"); err != nil || open.ID != id {
+			t.Errorf("FindOpen() = %+v, %v, want the alert of the rule", open, err)
+		}
+		_, err := r.Alerts.FindOpen(2, "low-battery")
+		wantError(t, "FindOpen() of another drone", err, repo.ErrRecordNotFound)
+
+		acknowledged := repo.Alert{ID: id, Status: "ACKNOWLEDGED", AcknowledgedAt: &now}
+		wantError(t, "Update() to ACKNOWLEDGED", r.Alerts.Update(acknowledged, "OPEN"), nil)
+		wantError(t, "Update() from a stale status", r.Alerts.Update(acknowledged, "OPEN"), repo.ErrAlertChanged)
+		missing := acknowledged
+		missing.ID = missingID
+		wantError(t, "Update() of a missing alert", r.Alerts.Update(missing, "OPEN"), repo.ErrRecordNotFound)
+		if open, err := r.Alerts.FindOpen(1, "low-battery"); err != nil || open.ID != id {
+			t.Errorf("FindOpen() of an acknowledged alert = %+v, %v, want it still open", open, err)
+		}
+
+		resolved := acknowledged
+		resolved.Status, resolved.ResolvedAt = "RESOLVED", &later
+		wantError(t, "Update() to RESOLVED", r.Alerts.Update(resolved, "ACKNOWLEDGED"), nil)
+		got, _ := r.Alerts.Get(id)
+		if got.Status != "RESOLVED" || got.AcknowledgedAt == nil || !got.AcknowledgedAt.Equal(now) || got.ResolvedAt == nil || !got.ResolvedAt.Equal(later) {
+			t.Errorf("Get() after Update() = %+v, want it RESOLVED with both times", got)
+		}
+		_, err = r.Alerts.FindOpen(1, "low-battery")
+		wantError(t, "FindOpen() of a resolved alert", err, repo.ErrRecordNotFound)
+	})
+}
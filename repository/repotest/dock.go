@@ -0,0 +1,45 @@
+package repotest
+
+import (
+	repo "drone/v2/repository"
+	"reflect"
+	"testing"
+)
+
+// RunDocks checks the contract of IDockRepository.
+func RunDocks(t *testing.T, factory Factory) {
+	t.Run("CreateAndGet", func(t *testing.T) {
+		r := factory(t)
+		dock := repo.Dock{Name: "dock 1", Latitude: 30.04, Longitude: 31.23, Slots: 4, ChargeRate: 2.5}
+		id, err := r.Docks.Create(&dock)
+		if err != nil || id == 0 || id != dock.ID {
+			t.Fatalf("Create() = %v, %v, want the id of the dock", id, err)
+		}
+		if got, err := r.Docks.Get(id); err != nil || !reflect.DeepEqual(got, dock) {
+			t.Errorf("Get() = %+v, %v, want %+v", got, err, dock)
+		}
+		if _, err := r.Docks.Create(&repo.Dock{Name: "dock 1", Slots: 1}); err == nil {
+			t.Errorf("Create() with a duplicate name did not fail")
+		}
+		_, err = r.Docks.Get(missingID)
+		wantError(t, "Get() of a missing dock", err, repo.ErrRecordNotFound)
+	})
+	t.Run("List", func(t *testing.T) {
+		r := factory(t)
+		docks, err := r.Docks.List()
+		if err != nil || docks == nil || len(docks) != 0 {
+			t.Errorf("List() of empty storage = %v, %v, want an empty list", docks, err)
+		}
+		for _, name := range []string{"dock b", "dock a"} {
+			r.Docks.Create(&repo.Dock{Name: name, Slots: 1})
+		}
+		docks, _ = r.Docks.List()
+		names := []string{}
+		for _, dock := range docks {
+			names = append(names, dock.Name)
+		}
+		if want := []string{"dock b", "dock a"}; !reflect.DeepEqual(names, want) {
+			t.Errorf("List() = %v, want %v in creation order", names, want)
+		}
+	})
+}
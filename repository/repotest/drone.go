@@ -0,0 +1,433 @@
+package repotest
+
+import (
+	repo "drone/v2/repository"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// missingID is an id no test creates a drone with.
+const missingID = 1000
+
+// RunDrones checks the contract of IDroneRepository.
+func RunDrones(t *testing.T, factory Factory) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, r Repositories)
+	}{
+		{name: "Create", run: testCreate},
+		{name: "Get", run: testGet},
+		{name: "List", run: testList},
+		{name: "Update", run: testUpdate},
+		{name: "Decommission", run: testDecommission},
+		{name: "AddMedication", run: testAddMedication},
+		{name: "RecalculatePayload", run: testRecalculatePayload},
+		{name: "RemoveMedication", run: testRemoveMedication},
+		{name: "RemoveItems", run: testRemoveItems},
+		{name: "Unload", run: testUnload},
+		{name: "CheckLoadingMedication", run: testCheckLoadingMedication},
+		{name: "AvailableDroneForLoading", run: testAvailableDroneForLoading},
+		{name: "CheckBatteryLevel", run: testCheckBatteryLevel},
+		{name: "UpdateBatteries", run: testUpdateBatteries},
+		{name: "ChangeDroneState", run: testChangeDroneState},
+		{name: "DockAndUndock", run: testDockAndUndock},
+		{name: "PositionAndDestination", run: testPositionAndDestination},
+		{name: "UpdateSnapshot", run: testUpdateSnapshot},
+		{name: "SetGrounded", run: testSetGrounded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(t, factory(t))
+		})
+	}
+}
+
+func testCreate(t *testing.T, r Repositories) {
+	id, err := r.Drones.Create(&repo.Drone{SerialNumber: "serial 1", Weight: 300, Model: "Lightweight"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	want := repo.Drone{
+		ID:              id,
+		SerialNumber:    "serial 1",
+		Weight:          300,
+		State:           "IDLE",
+		Model:           "Lightweight",
+		BatteryCapacity: 100,
+		Medications:     []repo.LoadItem{},
+		Version:         1,
+	}
+	if got := getDrone(t, r.Drones, id); !reflect.DeepEqual(got, want) {
+		t.Errorf("Create() stored %+v, want %+v", got, want)
+	}
+	if _, err := r.Drones.Create(&repo.Drone{SerialNumber: "serial 1", Weight: 100}); err == nil {
+		t.Errorf("Create() with a duplicate serial number did not fail")
+	}
+	long := "6qjUThKzS4mdhvCCXh9QEH2tdhYxwbu3rPJYD8tQMQwS456hn4KyzDBh24VHDiFgbZkkMna49agPiydhN5eXTkvieRd9CXv7QrDnF"
+	if _, err := r.Drones.Create(&repo.Drone{SerialNumber: long, Weight: 100}); err == nil {
+		t.Errorf("Create() with a serial number of %d characters did not fail", len(long))
+	}
+	other, err := r.Drones.Create(&repo.Drone{SerialNumber: "serial 2", State: "LOADED", BatteryCapacity: 50})
+	if err != nil || other == id {
+		t.Fatalf("Create() = %v, %v, want a new id", other, err)
+	}
+	if got := getDrone(t, r.Drones, other); got.State != "LOADED" || got.BatteryCapacity != 50 {
+		t.Errorf("Create() stored %+v, want the given state and battery", got)
+	}
+}
+
+func testGet(t *testing.T, r Repositories) {
+	ids := createDrones(t, r.Drones, repo.Drone{
+		SerialNumber: "serial 1",
+		Weight:       300,
+		Medications:  []repo.LoadItem{{MedicationCode: "code 1", Quantity: 2, Weight: 20}},
+	})
+	drone := getDrone(t, r.Drones, ids[0])
+	if len(drone.Medications) != 1 || drone.Medications[0].MedicationCode != "code 1" || drone.Medications[0].DroneID != ids[0] {
+		t.Errorf("Get() = %+v, want the drone with its medications", drone)
+	}
+	_, err := r.Drones.Get(missingID)
+	wantError(t, "Get()", err, repo.ErrRecordNotFound)
+}
+
+func testList(t *testing.T, r Repositories) {
+	createDrones(t, r.Drones,
+		repo.Drone{SerialNumber: "serial 1", State: "IDLE", Model: "Lightweight", BatteryCapacity: 100},
+		repo.Drone{SerialNumber: "serial 2", State: "LOADED", Model: "Heavyweight", BatteryCapacity: 40},
+		repo.Drone{SerialNumber: "serial 3", State: "IDLE", Model: "Lightweight", BatteryCapacity: 80},
+	)
+	tests := []struct {
+		name       string
+		filter     repo.DroneFilter
+		wantSerial []string
+		wantTotal  int64
+	}{
+		{name: "all", filter: repo.DroneFilter{}, wantSerial: []string{"serial 1", "serial 2", "serial 3"}, wantTotal: 3},
+		{name: "serial number", filter: repo.DroneFilter{SerialNumber: "serial 2"}, wantSerial: []string{"serial 2"}, wantTotal: 1},
+		{name: "state", filter: repo.DroneFilter{State: "IDLE"}, wantSerial: []string{"serial 1", "serial 3"}, wantTotal: 2},
+		{name: "model", filter: repo.DroneFilter{Model: "Heavyweight"}, wantSerial: []string{"serial 2"}, wantTotal: 1},
+		{name: "min battery", filter: repo.DroneFilter{MinBattery: 80}, wantSerial: []string{"serial 1", "serial 3"}, wantTotal: 2},
+		{name: "order", filter: repo.DroneFilter{Order: "battery_capacity desc"}, wantSerial: []string{"serial 1", "serial 3", "serial 2"}, wantTotal: 3},
+		{name: "page", filter: repo.DroneFilter{Order: "battery_capacity asc", Offset: 1, Limit: 1}, wantSerial: []string{"serial 3"}, wantTotal: 3},
+		{name: "page after the end", filter: repo.DroneFilter{Offset: 5, Limit: 1}, wantSerial: []string{}, wantTotal: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			drones, total, err := r.Drones.List(tt.filter)
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			serials := []string{}
+			for _, drone := range drones {
+				serials = append(serials, drone.SerialNumber)
+			}
+			if !reflect.DeepEqual(serials, tt.wantSerial) || total != tt.wantTotal {
+				t.Errorf("List() = %v total %v, want %v total %v", serials, total, tt.wantSerial, tt.wantTotal)
+			}
+		})
+	}
+}
+
+func testUpdate(t *testing.T, r Repositories) {
+	ids := createDrones(t, r.Drones, repo.Drone{SerialNumber: "serial 1", Model: "Lightweight", Weight: 100})
+	err := r.Drones.Update(&repo.Drone{ID: ids[0], Version: 1, Model: "Heavyweight", Weight: 400})
+	wantError(t, "Update()", err, nil)
+	err = r.Drones.Update(&repo.Drone{ID: ids[0], Version: 1, Model: "Lightweight", Weight: 300})
+	wantError(t, "Update() with a stale version", err, repo.ErrConcurrentUpdate)
+	err = r.Drones.Update(&repo.Drone{ID: missingID, Version: 1})
+	wantError(t, "Update() of a missing drone", err, repo.ErrRecordNotFound)
+	err = r.Drones.Update(&repo.Drone{ID: ids[0], Version: 2, Model: "Heavyweight", Weight: 400, HomeLatitude: 30.04, HomeLongitude: 31.23})
+	wantError(t, "Update() of the home base", err, nil)
+	if drone := getDrone(t, r.Drones, ids[0]); drone.Model != "Heavyweight" || drone.Weight != 400 || drone.HomeLatitude != 30.04 || drone.HomeLongitude != 31.23 || drone.Version != 3 {
+		t.Errorf("Update() stored %+v, want Heavyweight of weight 400 based at 30.04,31.23 at version 3", drone)
+	}
+}
+
+func testDecommission(t *testing.T, r Repositories) {
+	ids := createDrones(t, r.Drones, repo.Drone{SerialNumber: "serial 1"}, repo.Drone{SerialNumber: "serial 2"})
+	wantError(t, "Decommission() with a stale version", r.Drones.Decommission(ids[0], 2), repo.ErrConcurrentUpdate)
+	wantError(t, "Decommission()", r.Drones.Decommission(ids[0], 1), nil)
+	wantError(t, "Decommission() twice", r.Drones.Decommission(ids[0], 1), repo.ErrRecordNotFound)
+	_, err := r.Drones.Get(ids[0])
+	wantError(t, "Get() of a decommissioned drone", err, repo.ErrRecordNotFound)
+	if drones, total, _ := r.Drones.List(repo.DroneFilter{}); total != 1 || drones[0].ID != ids[1] {
+		t.Errorf("List() = %v, want only the active drone", drones)
+	}
+	if available := r.Drones.AvailableDroneForLoading(); len(available) != 1 {
+		t.Errorf("AvailableDroneForLoading() = %v, want only the active drone", available)
+	}
+	if _, err := r.Drones.Create(&repo.Drone{SerialNumber: "serial 1"}); err == nil {
+		t.Errorf("Create() reused the serial number of a decommissioned drone")
+	}
+	wantLogs(t, r, []string{"drone decommissioned"})
+}
+
+func testAddMedication(t *testing.T, r Repositories) {
+	ids := createDrones(t, r.Drones, repo.Drone{SerialNumber: "serial 1", Weight: 500, State: "LOADING"})
+	item := &repo.LoadItem{MedicationCode: "code 1", Quantity: 2, LotNumber: "lot 1", Weight: 20}
+	wantError(t, "AddMedication()", r.Drones.AddMedication(ids[0], 1, item), nil)
+	if item.ID == 0 || item.DroneID != ids[0] {
+		t.Errorf("AddMedication() item = %+v, want its id and drone id to be set", item)
+	}
+	err := r.Drones.AddMedication(ids[0], 1, &repo.LoadItem{MedicationCode: "code 2", Quantity: 1, Weight: 10})
+	wantError(t, "AddMedication() with a stale version", err, repo.ErrConcurrentUpdate)
+	err = r.Drones.AddMedication(missingID, 1, &repo.LoadItem{MedicationCode: "code 2", Quantity: 1, Weight: 10})
+	wantError(t, "AddMedication() to a missing drone", err, repo.ErrRecordNotFound)
+	drone := getDrone(t, r.Drones, ids[0])
+	want := []repo.LoadItem{*item}
+	if !reflect.DeepEqual(drone.Medications, want) || drone.CurrentPayload != 20 || drone.Version != 2 {
+		t.Errorf("AddMedication() stored %+v, want %+v with payload 20 at version 2", drone, want)
+	}
+}
+
+func testRecalculatePayload(t *testing.T, r Repositories) {
+	ids := createDrones(t, r.Drones, repo.Drone{
+		SerialNumber:   "serial 1",
+		CurrentPayload: 99,
+		Medications: []repo.LoadItem{
+			{MedicationCode: "code 1", Quantity: 1, Weight: 10},
+			{MedicationCode: "code 2", Quantity: 1, Weight: 15},
+		},
+	})
+	payload, err := r.Drones.RecalculatePayload(ids[0])
+	if err != nil || payload != 25 {
+		t.Errorf("RecalculatePayload() = %v, %v, want 25", payload, err)
+	}
+	if drone := getDrone(t, r.Drones, ids[0]); drone.CurrentPayload != 25 || drone.Version != 2 {
+		t.Errorf("RecalculatePayload() stored %+v, want payload 25 at version 2", drone)
+	}
+	_, err = r.Drones.RecalculatePayload(missingID)
+	wantError(t, "RecalculatePayload() of a missing drone", err, repo.ErrRecordNotFound)
+}
+
+func loadedDrone(t *testing.T, r Repositories) int {
+	ids := createDrones(t, r.Drones, repo.Drone{SerialNumber: "serial 1", Weight: 500, State: "LOADED"})
+	r.Drones.AddMedication(ids[0], 1, &repo.LoadItem{MedicationCode: "code 1", Quantity: 1, Weight: 10})
+	r.Drones.AddMedication(ids[0], 2, &repo.LoadItem{MedicationCode: "code 2", Quantity: 2, Weight: 20})
+	return ids[0]
+}
+
+func testRemoveMedication(t *testing.T, r Repositories) {
+	id := loadedDrone(t, r)
+	wantError(t, "RemoveMedication() with a stale version", r.Drones.RemoveMedication(id, 1, "code 1"), repo.ErrConcurrentUpdate)
+	wantError(t, "RemoveMedication() of a missing medication", r.Drones.RemoveMedication(id, 3, "code 3"), repo.ErrRecordNotFound)
+	wantError(t, "RemoveMedication() from a missing drone", r.Drones.RemoveMedication(missingID, 3, "code 1"), repo.ErrRecordNotFound)
+	wantError(t, "RemoveMedication()", r.Drones.RemoveMedication(id, 3, "code 1"), nil)
+	drone := getDrone(t, r.Drones, id)
+	if len(drone.Medications) != 1 || drone.Medications[0].MedicationCode != "code 2" || drone.CurrentPayload != 20 || drone.Version != 4 {
+		t.Errorf("RemoveMedication() stored %+v, want code 2 with payload 20 at version 4", drone)
+	}
+	wantLogs(t, r, []string{"medication code 1 unloaded"})
+}
+
+func testRemoveItems(t *testing.T, r Repositories) {
+	id := loadedDrone(t, r)
+	items := getDrone(t, r.Drones, id).Medications
+	wantError(t, "RemoveItems() with a stale version", r.Drones.RemoveItems(id, 1, []int{items[0].ID}), repo.ErrConcurrentUpdate)
+	wantError(t, "RemoveItems() of missing items", r.Drones.RemoveItems(id, 3, []int{missingID}), repo.ErrRecordNotFound)
+	wantError(t, "RemoveItems() from a missing drone", r.Drones.RemoveItems(missingID, 3, []int{items[0].ID}), repo.ErrRecordNotFound)
+	wantError(t, "RemoveItems()", r.Drones.RemoveItems(id, 3, []int{items[1].ID, missingID}), nil)
+	drone := getDrone(t, r.Drones, id)
+	if len(drone.Medications) != 1 || drone.Medications[0].MedicationCode != "code 1" || drone.CurrentPayload != 10 || drone.Version != 4 {
+		t.Errorf("RemoveItems() stored %+v, want code 1 with payload 10 at version 4", drone)
+	}
+	wantLogs(t, r, []string{"2 load items unloaded"})
+}
+
+func testUnload(t *testing.T, r Repositories) {
+	id := loadedDrone(t, r)
+	wantError(t, "Unload() with a stale version", r.Drones.Unload(id, 1), repo.ErrConcurrentUpdate)
+	wantError(t, "Unload()", r.Drones.Unload(id, 3), nil)
+	wantError(t, "Unload() of an empty drone", r.Drones.Unload(id, 4), repo.ErrRecordNotFound)
+	drone := getDrone(t, r.Drones, id)
+	if len(drone.Medications) != 0 || drone.CurrentPayload != 0 || drone.Version != 4 {
+		t.Errorf("Unload() stored %+v, want an empty drone at version 4", drone)
+	}
+	wantLogs(t, r, []string{"all medications unloaded"})
+}
+
+func testCheckLoadingMedication(t *testing.T, r Repositories) {
+	ids := createDrones(t, r.Drones, repo.Drone{SerialNumber: "serial 1", State: "LOADING"})
+	state, err := r.Drones.CheckLoadingMedication(ids[0])
+	if err != nil || state != "LOADING" {
+		t.Errorf("CheckLoadingMedication() = %v, %v, want LOADING", state, err)
+	}
+	_, err = r.Drones.CheckLoadingMedication(missingID)
+	wantError(t, "CheckLoadingMedication() of a missing drone", err, repo.ErrRecordNotFound)
+}
+
+func testAvailableDroneForLoading(t *testing.T, r Repositories) {
+	ids := createDrones(t, r.Drones,
+		repo.Drone{SerialNumber: "serial 1", State: "IDLE"},
+		repo.Drone{SerialNumber: "serial 2", State: "LOADING"},
+		repo.Drone{SerialNumber: "serial 3", State: "IDLE"},
+		repo.Drone{SerialNumber: "serial 4", State: "DELIVERING"},
+	)
+	got := []int{}
+	for _, drone := range r.Drones.AvailableDroneForLoading() {
+		got = append(got, drone.ID)
+	}
+	if want := []int{ids[0], ids[2]}; !reflect.DeepEqual(got, want) {
+		t.Errorf("AvailableDroneForLoading() = %v, want %v", got, want)
+	}
+}
+
+func testCheckBatteryLevel(t *testing.T, r Repositories) {
+	ids := createDrones(t, r.Drones, repo.Drone{SerialNumber: "serial 1", BatteryCapacity: 42})
+	level, err := r.Drones.CheckBatteryLevel(ids[0])
+	if err != nil || level != 42 {
+		t.Errorf("CheckBatteryLevel() = %v, %v, want 42", level, err)
+	}
+	_, err = r.Drones.CheckBatteryLevel(missingID)
+	wantError(t, "CheckBatteryLevel() of a missing drone", err, repo.ErrRecordNotFound)
+}
+
+func testUpdateBatteries(t *testing.T, r Repositories) {
+	ids := createDrones(t, r.Drones,
+		repo.Drone{SerialNumber: "serial 1", BatteryCapacity: 50},
+		repo.Drone{SerialNumber: "serial 2", BatteryCapacity: 1},
+		repo.Drone{SerialNumber: "serial 3", BatteryCapacity: 20},
+	)
+	err := r.Drones.UpdateBatteries(map[int]int{ids[2]: 35, ids[0]: 0, missingID: 10}, "charging")
+	wantError(t, "UpdateBatteries()", err, nil)
+	for i, want := range []int{0, 1, 35} {
+		drone := getDrone(t, r.Drones, ids[i])
+		if drone.BatteryCapacity != want || drone.Version != 1 {
+			t.Errorf("UpdateBatteries() battery of %s = %v at version %v, want %v at version 1", drone.SerialNumber, drone.BatteryCapacity, drone.Version, want)
+		}
+	}
+	logs := wantLogs(t, r, []string{"charging", "charging"})
+	if len(logs) == 2 && logs[0].DroneID != ids[0] || logs[0].BatteryCapacity != 0 || logs[1].DroneID != ids[2] || logs[1].BatteryCapacity != 35 {
+		t.Errorf("UpdateBatteries() logs = %+v, want the new levels of the updated drones", logs)
+	}
+}
+
+func testChangeDroneState(t *testing.T, r Repositories) {
+	ids := createDrones(t, r.Drones, repo.Drone{SerialNumber: "serial 1", State: "IDLE", BatteryCapacity: 80})
+	wantError(t, "ChangeDroneState()", r.Drones.ChangeDroneState(ids[0], "IDLE", "LOADING"), nil)
+	wantError(t, "ChangeDroneState() from a stale state", r.Drones.ChangeDroneState(ids[0], "IDLE", "LOADING"), repo.ErrConcurrentUpdate)
+	wantError(t, "ChangeDroneState() of a missing drone", r.Drones.ChangeDroneState(missingID, "IDLE", "LOADING"), repo.ErrRecordNotFound)
+	if drone := getDrone(t, r.Drones, ids[0]); drone.State != "LOADING" || drone.Version != 2 {
+		t.Errorf("ChangeDroneState() stored %+v, want LOADING at version 2", drone)
+	}
+	logs := wantLogs(t, r, []string{"state changed from IDLE to LOADING"})
+	if len(logs) == 1 && (logs[0].DroneState != "LOADING" || logs[0].BatteryCapacity != 80 || logs[0].DroneID != ids[0]) {
+		t.Errorf("ChangeDroneState() log = %+v, want the new state of the drone", logs[0])
+	}
+}
+
+func testDockAndUndock(t *testing.T, r Repositories) {
+	ids := createDrones(t, r.Drones,
+		repo.Drone{SerialNumber: "serial 1", BatteryCapacity: 20},
+		repo.Drone{SerialNumber: "serial 2", BatteryCapacity: 30},
+		repo.Drone{SerialNumber: "serial 3", BatteryCapacity: 40},
+	)
+	wantError(t, "Dock()", r.Drones.Dock(ids[0], 1, 2), nil)
+	wantError(t, "Dock() twice", r.Drones.Dock(ids[0], 2, 2), repo.ErrAlreadyDocked)
+	wantError(t, "Dock() in the last slot", r.Drones.Dock(ids[1], 1, 2), nil)
+	wantError(t, "Dock() in a full dock", r.Drones.Dock(ids[2], 1, 2), repo.ErrDockFull)
+	wantError(t, "Dock() of a missing drone", r.Drones.Dock(missingID, 2, 2), repo.ErrRecordNotFound)
+	if drone := getDrone(t, r.Drones, ids[0]); drone.DockID == nil || *drone.DockID != 1 || drone.Version != 1 {
+		t.Errorf("Dock() stored %+v, want dock 1 at version 1", drone)
+	}
+	drones, total, err := r.Drones.List(repo.DroneFilter{DockID: 1})
+	if err != nil || total != 2 || len(drones) != 2 {
+		t.Errorf("List() of dock 1 = %d drones, total %d, %v, want 2", len(drones), total, err)
+	}
+
+	wantError(t, "Undock()", r.Drones.Undock(ids[0]), nil)
+	wantError(t, "Undock() twice", r.Drones.Undock(ids[0]), repo.ErrNotDocked)
+	wantError(t, "Undock() of a missing drone", r.Drones.Undock(missingID), repo.ErrRecordNotFound)
+	if drone := getDrone(t, r.Drones, ids[0]); drone.DockID != nil {
+		t.Errorf("Undock() stored dock %d, want none", *drone.DockID)
+	}
+	wantError(t, "Dock() in the freed slot", r.Drones.Dock(ids[2], 1, 2), nil)
+	wantLogs(t, r, []string{"docked at dock 1", "docked at dock 1", "undocked from dock 1", "docked at dock 1"})
+}
+
+func testPositionAndDestination(t *testing.T, r Repositories) {
+	ids := createDrones(t, r.Drones, repo.Drone{SerialNumber: "serial 1", HomeLatitude: 30.04, HomeLongitude: 31.23})
+	wantError(t, "UpdatePosition()", r.Drones.UpdatePosition(ids[0], 30.05, -31.2), nil)
+	wantError(t, "UpdatePosition() of a missing drone", r.Drones.UpdatePosition(missingID, 1, 1), repo.ErrRecordNotFound)
+	drone := getDrone(t, r.Drones, ids[0])
+	if drone.Latitude != 30.05 || drone.Longitude != -31.2 || drone.HomeLatitude != 30.04 || drone.Version != 1 {
+		t.Errorf("UpdatePosition() stored %+v, want position 30.05,-31.2 at version 1", drone)
+	}
+
+	latitude, longitude := 30.1, 31.3
+	wantError(t, "SetDestination()", r.Drones.SetDestination(ids[0], &latitude, &longitude), nil)
+	wantError(t, "SetDestination() of a missing drone", r.Drones.SetDestination(missingID, nil, nil), repo.ErrRecordNotFound)
+	drone = getDrone(t, r.Drones, ids[0])
+	if drone.DestinationLatitude == nil || *drone.DestinationLatitude != 30.1 || *drone.DestinationLongitude != 31.3 {
+		t.Errorf("SetDestination() stored %+v, want destination 30.1,31.3", drone)
+	}
+	wantError(t, "SetDestination() to clear it", r.Drones.SetDestination(ids[0], nil, nil), nil)
+	if drone := getDrone(t, r.Drones, ids[0]); drone.DestinationLatitude != nil || drone.DestinationLongitude != nil {
+		t.Errorf("SetDestination() stored %+v, want no destination", drone)
+	}
+	wantLogs(t, r, []string{"destination set to 30.1,31.3", "destination cleared"})
+}
+
+func testSetGrounded(t *testing.T, r Repositories) {
+	ids := createDrones(t, r.Drones, repo.Drone{SerialNumber: "serial 1", State: "IDLE"}, repo.Drone{SerialNumber: "serial 2", State: "IDLE"})
+	wantError(t, "SetGrounded()", r.Drones.SetGrounded(ids[0], true), nil)
+	wantError(t, "SetGrounded() again", r.Drones.SetGrounded(ids[0], true), nil)
+	wantError(t, "SetGrounded() of a missing drone", r.Drones.SetGrounded(missingID, true), repo.ErrRecordNotFound)
+	if drone := getDrone(t, r.Drones, ids[0]); !drone.Grounded || drone.Version != 2 {
+		t.Errorf("SetGrounded() stored %+v, want it grounded at version 2", drone)
+	}
+	if available := r.Drones.AvailableDroneForLoading(); len(available) != 1 || available[0].ID != ids[1] {
+		t.Errorf("AvailableDroneForLoading() = %+v, want only the drone that is not grounded", available)
+	}
+	wantError(t, "SetGrounded() to release it", r.Drones.SetGrounded(ids[0], false), nil)
+	if drone := getDrone(t, r.Drones, ids[0]); drone.Grounded || drone.Version != 3 {
+		t.Errorf("SetGrounded() stored %+v, want it released at version 3", drone)
+	}
+	wantLogs(t, r, []string{"drone grounded", "drone released from the ground"})
+}
+
+func testUpdateSnapshot(t *testing.T, r Repositories) {
+	ids := createDrones(t, r.Drones, repo.Drone{SerialNumber: "serial 1", State: "IDLE", BatteryCapacity: 80})
+	recordedAt := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	reading := repo.Telemetry{
+		RecordedAt: recordedAt, BatteryLevel: floatPointer(79.6), Latitude: floatPointer(30.05), Longitude: floatPointer(31.2),
+		Altitude: floatPointer(120), Speed: floatPointer(40),
+	}
+	wantError(t, "UpdateSnapshot()", r.Drones.UpdateSnapshot(ids[0], reading), nil)
+	wantError(t, "UpdateSnapshot() of a missing drone", r.Drones.UpdateSnapshot(missingID, reading), repo.ErrRecordNotFound)
+	drone := getDrone(t, r.Drones, ids[0])
+	if drone.BatteryCapacity != 80 || drone.Latitude != 30.05 || drone.Longitude != 31.2 || drone.Altitude != 120 || drone.Speed != 40 ||
+		drone.State != "IDLE" || drone.Version != 1 || drone.TelemetryAt == nil || !drone.TelemetryAt.Equal(recordedAt) {
+		t.Errorf("UpdateSnapshot() stored %+v, want the reading at version 1", drone)
+	}
+
+	// the values that are not reported and the state are left as they are
+	reading = repo.Telemetry{RecordedAt: recordedAt.Add(time.Minute), BatteryLevel: floatPointer(70), State: "DELIVERING"}
+	wantError(t, "UpdateSnapshot() of a part of the values", r.Drones.UpdateSnapshot(ids[0], reading), nil)
+	older := repo.Telemetry{RecordedAt: recordedAt.Add(30 * time.Second), BatteryLevel: floatPointer(75)}
+	wantError(t, "UpdateSnapshot() of an older reading", r.Drones.UpdateSnapshot(ids[0], older), nil)
+	drone = getDrone(t, r.Drones, ids[0])
+	if drone.BatteryCapacity != 70 || drone.Latitude != 30.05 || drone.Longitude != 31.2 || drone.Altitude != 120 || drone.Speed != 40 ||
+		drone.State != "IDLE" || drone.Version != 1 || !drone.TelemetryAt.Equal(recordedAt.Add(time.Minute)) {
+		t.Errorf("UpdateSnapshot() stored %+v, want the battery of the newest reading", drone)
+	}
+	wantLogs(t, r, []string{"telemetry reported at 2026-01-01T12:01:00Z"})
+}
+
+// wantLogs checks the descriptions of all the recorded logs.
+func wantLogs(t *testing.T, r Repositories, want []string) []repo.Log {
+	t.Helper()
+	logs, err := r.Logs.List()
+	if err != nil {
+		t.Fatalf("List() logs error = %v", err)
+	}
+	got := []string{}
+	for _, log := range logs {
+		got = append(got, log.Description)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("logs = %v, want %v", got, want)
+	}
+	return logs
+}
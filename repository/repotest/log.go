@@ -0,0 +1,59 @@
+package repotest
+
+import (
+	repo "drone/v2/repository"
+	"testing"
+	"time"
+)
+
+// RunLogs checks the contract of ILogRepository.
+func RunLogs(t *testing.T, factory Factory) {
+	t.Run("CreateAndList", func(t *testing.T) {
+		r := factory(t)
+		logs, err := r.Logs.List()
+		if err != nil || logs == nil || len(logs) != 0 {
+			t.Errorf("List() of empty storage = %v, %v, want an empty list", logs, err)
+		}
+		fixtures := []repo.Log{
+			{DroneID: 1, BatteryCapacity: 50, DroneState: "LOADING"},
+			{DroneID: 2, BatteryCapacity: 100, DroneState: "IDLE", Description: "state changed from RETURNING to IDLE"},
+			{DroneID: 3, BatteryCapacity: 90, DroneState: "LOADED"},
+		}
+		for _, log := range fixtures {
+			wantError(t, "Create()", r.Logs.Create(log), nil)
+		}
+		logs, err = r.Logs.List()
+		if err != nil || len(logs) != len(fixtures) {
+			t.Fatalf("List() = %v, %v, want %d logs", logs, err, len(fixtures))
+		}
+		for i, log := range logs {
+			want := fixtures[i]
+			if log.DroneID != want.DroneID || log.BatteryCapacity != want.BatteryCapacity ||
+				log.DroneState != want.DroneState || log.Description != want.Description {
+				t.Errorf("List()[%d] = %+v, want %+v", i, log, want)
+			}
+			if log.ID == 0 || log.CreatedAt.IsZero() {
+				t.Errorf("List()[%d] = %+v, want its id and creation date to be set", i, log)
+			}
+			if i > 0 && log.ID <= logs[i-1].ID {
+				t.Errorf("List() ids = %v then %v, want logs in creation order", logs[i-1].ID, log.ID)
+			}
+		}
+	})
+	t.Run("CreatedAtFromClock", func(t *testing.T) {
+		r := factory(t)
+		first := r.Clock.Now()
+		wantError(t, "Create()", r.Logs.Create(repo.Log{DroneID: 1, BatteryCapacity: 50, DroneState: "IDLE"}), nil)
+		r.Clock.Advance(time.Hour)
+		wantError(t, "Create()", r.Logs.Create(repo.Log{DroneID: 1, BatteryCapacity: 40, DroneState: "IDLE"}), nil)
+		logs, err := r.Logs.List()
+		if err != nil || len(logs) != 2 {
+			t.Fatalf("List() = %v, %v, want 2 logs", logs, err)
+		}
+		for i, want := range []time.Time{first, first.Add(time.Hour)} {
+			if !logs[i].CreatedAt.Equal(want) {
+				t.Errorf("List()[%d].CreatedAt = %v, want %v", i, logs[i].CreatedAt, want)
+			}
+		}
+	})
+}
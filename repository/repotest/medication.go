@@ -0,0 +1,62 @@
+package repotest
+
+import (
+	repo "drone/v2/repository"
+	"reflect"
+	"testing"
+)
+
+// RunMedications checks the contract of IMedicationRepository.
+func RunMedications(t *testing.T, factory Factory) {
+	t.Run("CreateAndGet", func(t *testing.T) {
+		r := factory(t)
+		medication := repo.Medication{Name: "medication 1", Code: "code 1", Weight: 12.5, Image: "http://image"}
+		code, err := r.Medications.Create(&medication)
+		if err != nil || code != "code 1" {
+			t.Fatalf("Create() = %v, %v, want code 1", code, err)
+		}
+		if got, err := r.Medications.Get("code 1"); err != nil || !reflect.DeepEqual(got, medication) {
+			t.Errorf("Get() = %+v, %v, want %+v", got, err, medication)
+		}
+		if _, err := r.Medications.Create(&repo.Medication{Name: "medication 2", Code: "code 1"}); err == nil {
+			t.Errorf("Create() with a duplicate code did not fail")
+		}
+		if _, err := r.Medications.Create(&repo.Medication{Name: "medication 1", Code: "code 2"}); err == nil {
+			t.Errorf("Create() with a duplicate name did not fail")
+		}
+		_, err = r.Medications.Get("missing")
+		wantError(t, "Get() of a missing medication", err, repo.ErrRecordNotFound)
+	})
+	t.Run("List", func(t *testing.T) {
+		r := factory(t)
+		medications, err := r.Medications.List()
+		if err != nil || medications == nil || len(medications) != 0 {
+			t.Errorf("List() of empty storage = %v, %v, want an empty list", medications, err)
+		}
+		for _, code := range []string{"code 2", "code 1", "code 3"} {
+			r.Medications.Create(&repo.Medication{Name: "name " + code, Code: code, Weight: 1})
+		}
+		medications, _ = r.Medications.List()
+		codes := []string{}
+		for _, medication := range medications {
+			codes = append(codes, medication.Code)
+		}
+		if want := []string{"code 1", "code 2", "code 3"}; !reflect.DeepEqual(codes, want) {
+			t.Errorf("List() = %v, want %v", codes, want)
+		}
+	})
+	t.Run("UpdateAndDelete", func(t *testing.T) {
+		r := factory(t)
+		r.Medications.Create(&repo.Medication{Name: "medication 1", Code: "code 1", Weight: 1})
+		updated := repo.Medication{Name: "medication 2", Code: "code 1", Weight: 2, Image: "http://image"}
+		wantError(t, "Update()", r.Medications.Update(&updated), nil)
+		if got, _ := r.Medications.Get("code 1"); !reflect.DeepEqual(got, updated) {
+			t.Errorf("Update() stored %+v, want %+v", got, updated)
+		}
+		wantError(t, "Update() of a missing medication", r.Medications.Update(&repo.Medication{Name: "x", Code: "missing"}), repo.ErrRecordNotFound)
+		wantError(t, "Delete()", r.Medications.Delete("code 1"), nil)
+		wantError(t, "Delete() twice", r.Medications.Delete("code 1"), repo.ErrRecordNotFound)
+		_, err := r.Medications.Get("code 1")
+		wantError(t, "Get() of a deleted medication", err, repo.ErrRecordNotFound)
+	})
+}
@@ -0,0 +1,94 @@
+package repotest
+
+import (
+	repo "drone/v2/repository"
+	"reflect"
+	"testing"
+)
+
+// RunOrders checks the contract of IOrderRepository.
+func RunOrders(t *testing.T, factory Factory) {
+	t.Run("CreateAndGet", func(t *testing.T) {
+		r := factory(t)
+		latitude, longitude := 30.1, 31.3
+		order := repo.Order{
+			Destination:          "hospital",
+			Priority:             2,
+			DestinationLatitude:  &latitude,
+			DestinationLongitude: &longitude,
+			Items: []repo.OrderItem{
+				{MedicationCode: "code 1", Quantity: 2},
+				{MedicationCode: "code 2"},
+			},
+		}
+		id, err := r.Orders.Create(&order)
+		if err != nil || id == 0 || id != order.ID {
+			t.Fatalf("Create() = %v, %v, want the id of the order", id, err)
+		}
+		got, err := r.Orders.Get(id)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if got.Destination != "hospital" || got.Priority != 2 || got.Status != "PENDING" || got.DroneID != nil || got.CreatedAt.IsZero() {
+			t.Errorf("Get() = %+v, want a PENDING order to hospital", got)
+		}
+		if got.DestinationLatitude == nil || *got.DestinationLatitude != 30.1 || got.DestinationLongitude == nil || *got.DestinationLongitude != 31.3 {
+			t.Errorf("Get() = %+v, want the destination at 30.1,31.3", got)
+		}
+		wantItems := []repo.OrderItem{
+			{ID: order.Items[0].ID, OrderID: id, MedicationCode: "code 1", Quantity: 2},
+			{ID: order.Items[1].ID, OrderID: id, MedicationCode: "code 2", Quantity: 1},
+		}
+		if !reflect.DeepEqual(got.Items, wantItems) {
+			t.Errorf("Get() items = %+v, want %+v", got.Items, wantItems)
+		}
+		_, err = r.Orders.Get(missingID)
+		wantError(t, "Get() of a missing order", err, repo.ErrRecordNotFound)
+	})
+	t.Run("List", func(t *testing.T) {
+		r := factory(t)
+		orders, err := r.Orders.List("PENDING")
+		if err != nil || orders == nil || len(orders) != 0 {
+			t.Errorf("List() of empty storage = %v, %v, want an empty list", orders, err)
+		}
+		for _, order := range []repo.Order{
+			{Destination: "low", Priority: 0, Items: []repo.OrderItem{{MedicationCode: "code 1"}}},
+			{Destination: "high", Priority: 5, Items: []repo.OrderItem{{MedicationCode: "code 1"}, {MedicationCode: "code 2"}}},
+			{Destination: "assigned", Priority: 9, Status: "ASSIGNED"},
+			{Destination: "low later", Priority: 0},
+		} {
+			order := order
+			r.Orders.Create(&order)
+		}
+		orders, _ = r.Orders.List("PENDING")
+		destinations := []string{}
+		for _, order := range orders {
+			destinations = append(destinations, order.Destination)
+		}
+		if want := []string{"high", "low", "low later"}; !reflect.DeepEqual(destinations, want) {
+			t.Errorf("List() = %v, want %v", destinations, want)
+		}
+		if len(orders) == 3 && len(orders[0].Items) != 2 {
+			t.Errorf("List() items = %+v, want the items of the order", orders[0].Items)
+		}
+	})
+	t.Run("AssignAndUpdateStatus", func(t *testing.T) {
+		r := factory(t)
+		order := repo.Order{Destination: "hospital", Items: []repo.OrderItem{{MedicationCode: "code 1"}, {MedicationCode: "code 2"}}}
+		id, _ := r.Orders.Create(&order)
+		jobs := map[int]int{order.Items[0].ID: 7, order.Items[1].ID: 8}
+		wantError(t, "Assign()", r.Orders.Assign(id, "ASSIGNED", 3, jobs), nil)
+		wantError(t, "Assign() of a missing order", r.Orders.Assign(missingID, "ASSIGNED", 3, jobs), repo.ErrRecordNotFound)
+		got, _ := r.Orders.Get(id)
+		if got.Status != "ASSIGNED" || got.DroneID == nil || *got.DroneID != 3 || got.Items[0].JobID != 7 || got.Items[1].JobID != 8 {
+			t.Errorf("Assign() stored %+v, want ASSIGNED to drone 3 with the item jobs", got)
+		}
+
+		wantError(t, "UpdateStatus()", r.Orders.UpdateStatus(id, "ASSIGNED", "LOADED"), nil)
+		wantError(t, "UpdateStatus() from a stale status", r.Orders.UpdateStatus(id, "ASSIGNED", "FAILED"), repo.ErrOrderChanged)
+		wantError(t, "UpdateStatus() of a missing order", r.Orders.UpdateStatus(missingID, "ASSIGNED", "LOADED"), repo.ErrRecordNotFound)
+		if got, _ := r.Orders.Get(id); got.Status != "LOADED" {
+			t.Errorf("UpdateStatus() stored %s, want LOADED", got.Status)
+		}
+	})
+}
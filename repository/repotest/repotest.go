@@ -0,0 +1,103 @@
+// Package repotest checks that a storage backend follows the contract of the repository
+// interfaces, every backend runs the same suite from its own tests:
+//
+//	func TestConformance(t *testing.T) {
+//		repotest.Run(t, func(t *testing.T) repotest.Repositories {
+//			clock := clock.NewFake(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+//			logRepo := repository.NewMemoryLogRepository(clock)
+//			return repotest.Repositories{
+//				Drones:      repository.NewMemoryDroneRepo(logRepo),
+//				Logs:        logRepo,
+//				Medications: repository.NewMemoryMedicationRepo(),
+//				Docks:       repository.NewMemoryDockRepo(),
+//				Orders:      repository.NewMemoryOrderRepo(clock),
+//				Telemetry:   repository.NewMemoryTelemetryRepo(),
+//				Webhooks:    repository.NewMemoryWebhookRepo(clock),
+//				Alerts:      repository.NewMemoryAlertRepo(clock),
+//				Clock:       clock,
+//			}
+//		})
+//	}
+package repotest
+
+import (
+	"drone/v2/clock"
+	repo "drone/v2/repository"
+	"testing"
+)
+
+// Repositories are the repositories of one backend sharing the same empty storage,
+// the drone repository must record its logs in Logs and the repositories must take the time from Clock.
+type Repositories struct {
+	Drones      repo.IDroneRepository
+	Logs        repo.ILogRepository
+	Medications repo.IMedicationRepository
+	Docks       repo.IDockRepository
+	Orders      repo.IOrderRepository
+	Telemetry   repo.ITelemetryRepository
+	Webhooks    repo.IWebhookRepository
+	Alerts      repo.IAlertRepository
+	Clock       *clock.Fake
+}
+
+// Factory returns repositories on a new empty storage for every test,
+// cleanups of the storage should be registered on t.
+type Factory func(t *testing.T) Repositories
+
+// Run runs the whole contract against the backend created by factory.
+func Run(t *testing.T, factory Factory) {
+	t.Run("Drones", func(t *testing.T) {
+		RunDrones(t, factory)
+	})
+	t.Run("Logs", func(t *testing.T) {
+		RunLogs(t, factory)
+	})
+	t.Run("Medications", func(t *testing.T) {
+		RunMedications(t, factory)
+	})
+	t.Run("Docks", func(t *testing.T) {
+		RunDocks(t, factory)
+	})
+	t.Run("Orders", func(t *testing.T) {
+		RunOrders(t, factory)
+	})
+	t.Run("Telemetry", func(t *testing.T) {
+		RunTelemetry(t, factory)
+	})
+	t.Run("Webhooks", func(t *testing.T) {
+		RunWebhooks(t, factory)
+	})
+	t.Run("Alerts", func(t *testing.T) {
+		RunAlerts(t, factory)
+	})
+}
+
+func createDrones(t *testing.T, drones repo.IDroneRepository, fixtures ...repo.Drone) []int {
+	t.Helper()
+	ids := make([]int, 0, len(fixtures))
+	for _, drone := range fixtures {
+		drone := drone
+		id, err := drones.Create(&drone)
+		if err != nil {
+			t.Fatalf("Can't create drone %s: %v", drone.SerialNumber, err)
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func getDrone(t *testing.T, drones repo.IDroneRepository, id int) repo.Drone {
+	t.Helper()
+	drone, err := drones.Get(id)
+	if err != nil {
+		t.Fatalf("Get(%d) error = %v", id, err)
+	}
+	return drone
+}
+
+func wantError(t *testing.T, method string, err error, want error) {
+	t.Helper()
+	if err != want {
+		t.Errorf("%s error = %v, wantErr %v", method, err, want)
+	}
+}
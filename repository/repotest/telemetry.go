@@ -0,0 +1,181 @@
+package repotest
+
+import (
+	repo "drone/v2/repository"
+	"math"
+	"testing"
+	"time"
+)
+
+var telemetryStart = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// RunTelemetry checks the contract of ITelemetryRepository.
+func RunTelemetry(t *testing.T, factory Factory) {
+	t.Run("CreateAndList", func(t *testing.T) {
+		r := factory(t)
+		readings := []repo.Telemetry{
+			{DroneID: 1, RecordedAt: telemetryStart.Add(2 * time.Minute), BatteryLevel: floatPointer(90), State: "DELIVERING"},
+			{DroneID: 1, RecordedAt: telemetryStart, BatteryLevel: floatPointer(92)},
+			{DroneID: 2, RecordedAt: telemetryStart.Add(time.Minute), BatteryLevel: floatPointer(50)},
+			{
+				DroneID: 1, RecordedAt: telemetryStart.Add(time.Minute), BatteryLevel: floatPointer(91),
+				Latitude: floatPointer(30.05), Longitude: floatPointer(31.2), Altitude: floatPointer(100), Speed: floatPointer(40),
+			},
+		}
+		wantError(t, "Create()", r.Telemetry.Create(readings), nil)
+		wantError(t, "Create() of no readings", r.Telemetry.Create(nil), nil)
+		for _, reading := range readings {
+			if reading.ID == 0 {
+				t.Errorf("Create() left reading %+v without an id", reading)
+			}
+		}
+
+		got, err := r.Telemetry.List(repo.TelemetryFilter{DroneID: 1})
+		if err != nil || len(got) != 3 {
+			t.Fatalf("List() = %v, %v, want the 3 readings of drone 1", got, err)
+		}
+		for i, want := range []float64{92, 91, 90} {
+			if !sameValue(got[i].BatteryLevel, want) || got[i].Samples != 1 {
+				t.Errorf("List()[%d] = %+v, want battery %v of one sample", i, got[i], want)
+			}
+		}
+		if !sameValue(got[1].Latitude, 30.05) || !sameValue(got[1].Altitude, 100) || !sameValue(got[1].Speed, 40) ||
+			got[0].Latitude != nil || got[2].State != "DELIVERING" || !got[2].RecordedAt.Equal(telemetryStart.Add(2*time.Minute)) {
+			t.Errorf("List() = %+v, want the stored values of the readings", got)
+		}
+
+		filters := []struct {
+			name   string
+			filter repo.TelemetryFilter
+			want   []float64
+		}{
+			{name: "from", filter: repo.TelemetryFilter{DroneID: 1, From: telemetryStart.Add(time.Minute)}, want: []float64{91, 90}},
+			{name: "to", filter: repo.TelemetryFilter{DroneID: 1, To: telemetryStart.Add(2 * time.Minute)}, want: []float64{92, 91}},
+			{name: "limit", filter: repo.TelemetryFilter{DroneID: 1, Limit: 1}, want: []float64{92}},
+			{name: "other drone", filter: repo.TelemetryFilter{DroneID: 3}, want: []float64{}},
+		}
+		for _, tt := range filters {
+			got, err := r.Telemetry.List(tt.filter)
+			if err != nil || got == nil || !sameBatteries(got, tt.want) {
+				t.Errorf("List() by %s = %v, %v, want batteries %v", tt.name, got, err, tt.want)
+			}
+		}
+	})
+	t.Run("DeleteBefore", func(t *testing.T) {
+		r := factory(t)
+		wantError(t, "Create()", r.Telemetry.Create([]repo.Telemetry{
+			{DroneID: 1, RecordedAt: telemetryStart, BatteryLevel: floatPointer(92)},
+			{DroneID: 2, RecordedAt: telemetryStart.Add(time.Minute), BatteryLevel: floatPointer(91)},
+			{DroneID: 1, RecordedAt: telemetryStart.Add(2 * time.Minute), BatteryLevel: floatPointer(90)},
+		}), nil)
+		deleted, err := r.Telemetry.DeleteBefore(telemetryStart.Add(2 * time.Minute))
+		if err != nil || deleted != 2 {
+			t.Errorf("DeleteBefore() = %v, %v, want 2 deleted", deleted, err)
+		}
+		if got, _ := r.Telemetry.List(repo.TelemetryFilter{DroneID: 1}); !sameBatteries(got, []float64{90}) {
+			t.Errorf("List() after DeleteBefore() = %v, want only the newest reading", got)
+		}
+	})
+	t.Run("Downsample", func(t *testing.T) {
+		r := factory(t)
+		wantError(t, "Create()", r.Telemetry.Create([]repo.Telemetry{
+			{
+				DroneID: 1, RecordedAt: telemetryStart.Add(time.Minute), BatteryLevel: floatPointer(90),
+				Latitude: floatPointer(30), Longitude: floatPointer(31), Altitude: floatPointer(100), State: "DELIVERING",
+			},
+			// the altitude is not reported so it is left out of the average
+			{DroneID: 1, RecordedAt: telemetryStart.Add(2 * time.Minute), BatteryLevel: floatPointer(88), Latitude: floatPointer(31), Longitude: floatPointer(31)},
+			{
+				DroneID: 1, RecordedAt: telemetryStart.Add(4 * time.Minute), BatteryLevel: floatPointer(86),
+				Latitude: floatPointer(32), Longitude: floatPointer(31), Altitude: floatPointer(110), State: "DELIVERED",
+			},
+			// alone in its bucket
+			{DroneID: 1, RecordedAt: telemetryStart.Add(6 * time.Minute), BatteryLevel: floatPointer(80)},
+			{DroneID: 2, RecordedAt: telemetryStart.Add(3 * time.Minute), BatteryLevel: floatPointer(50)},
+			// not old enough
+			{DroneID: 1, RecordedAt: telemetryStart.Add(11 * time.Minute), BatteryLevel: floatPointer(70)},
+			{DroneID: 1, RecordedAt: telemetryStart.Add(12 * time.Minute), BatteryLevel: floatPointer(69)},
+		}), nil)
+		removed, err := r.Telemetry.Downsample(time.Time{}, telemetryStart.Add(10*time.Minute), 5*time.Minute)
+		if err != nil || removed != 2 {
+			t.Fatalf("Downsample() = %v, %v, want 2 readings less", removed, err)
+		}
+		got, _ := r.Telemetry.List(repo.TelemetryFilter{DroneID: 1})
+		if len(got) != 4 || !sameBatteries(got[1:], []float64{80, 70, 69}) {
+			t.Fatalf("List() after Downsample() = %+v, want the merged reading then 80, 70 and 69", got)
+		}
+		merged := got[0]
+		if !merged.RecordedAt.Equal(telemetryStart) || merged.Samples != 3 || !sameValue(merged.BatteryLevel, 88) ||
+			!sameValue(merged.Latitude, 31) || merged.Altitude == nil || math.Abs(*merged.Altitude-105) > 1e-9 ||
+			merged.Speed != nil || merged.State != "DELIVERED" {
+			t.Errorf("Downsample() merged %+v, want the average of 3 samples at %v in DELIVERED", merged, telemetryStart)
+		}
+		if got, _ := r.Telemetry.List(repo.TelemetryFilter{DroneID: 2}); len(got) != 1 || got[0].Samples != 1 {
+			t.Errorf("List() of drone 2 after Downsample() = %+v, want its reading kept", got)
+		}
+
+		// merged readings are weighted by their samples when they are downsampled again
+		removed, err = r.Telemetry.Downsample(time.Time{}, telemetryStart.Add(10*time.Minute), 10*time.Minute)
+		if err != nil || removed != 1 {
+			t.Fatalf("Downsample() again = %v, %v, want 1 reading less", removed, err)
+		}
+		got, _ = r.Telemetry.List(repo.TelemetryFilter{DroneID: 1})
+		if len(got) != 3 || got[0].Samples != 4 || !sameValue(got[0].BatteryLevel, 86) {
+			t.Errorf("List() after downsampling again = %+v, want 4 samples with battery 86", got)
+		}
+
+		// only the window is downsampled
+		removed, err = r.Telemetry.Downsample(telemetryStart.Add(10*time.Minute), telemetryStart.Add(11*time.Minute), 5*time.Minute)
+		if err != nil || removed != 0 {
+			t.Errorf("Downsample() of a window without readings = %v, %v, want no reading less", removed, err)
+		}
+	})
+	t.Run("DownsampleInPages", func(t *testing.T) {
+		r := factory(t)
+		// more readings in one bucket than downsampled at once, then buckets that are split across pages
+		readings := []repo.Telemetry{}
+		for i := 0; i < 1200; i++ {
+			readings = append(readings, repo.Telemetry{DroneID: 1, RecordedAt: telemetryStart.Add(time.Duration(i) * 100 * time.Millisecond), BatteryLevel: floatPointer(float64(i % 2))})
+		}
+		for i := 0; i < 1200; i++ {
+			readings = append(readings, repo.Telemetry{DroneID: 2, RecordedAt: telemetryStart.Add(time.Duration(i) * time.Second), BatteryLevel: floatPointer(50)})
+		}
+		wantError(t, "Create()", r.Telemetry.Create(readings), nil)
+		removed, err := r.Telemetry.Downsample(time.Time{}, telemetryStart.Add(time.Hour), 5*time.Minute)
+		if err != nil || removed != 1199+1196 {
+			t.Fatalf("Downsample() = %v, %v, want %v readings less", removed, err, 1199+1196)
+		}
+		if got, _ := r.Telemetry.List(repo.TelemetryFilter{DroneID: 1}); len(got) != 1 || got[0].Samples != 1200 || !sameValue(got[0].BatteryLevel, 0.5) {
+			t.Errorf("List() of drone 1 after Downsample() = %+v, want 1200 samples with battery 0.5", got)
+		}
+		got, _ := r.Telemetry.List(repo.TelemetryFilter{DroneID: 2})
+		if len(got) != 4 {
+			t.Fatalf("List() of drone 2 after Downsample() = %+v, want a reading per bucket", got)
+		}
+		for _, reading := range got {
+			if reading.Samples != 300 {
+				t.Errorf("List() of drone 2 after Downsample() = %+v, want 300 samples in each bucket", reading)
+			}
+		}
+	})
+}
+
+func sameBatteries(readings []repo.Telemetry, want []float64) bool {
+	if len(readings) != len(want) {
+		return false
+	}
+	for i, reading := range readings {
+		if !sameValue(reading.BatteryLevel, want[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func sameValue(value *float64, want float64) bool {
+	return value != nil && *value == want
+}
+
+func floatPointer(value float64) *float64 {
+	return &value
+}
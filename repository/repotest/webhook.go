@@ -0,0 +1,102 @@
+package repotest
+
+import (
+	repo "drone/v2/repository"
+	"testing"
+	"time"
+)
+
+// RunWebhooks checks the contract of IWebhookRepository.
+func RunWebhooks(t *testing.T, factory Factory) {
+	t.Run("CreateGetListDelete", func(t *testing.T) {
+		r := factory(t)
+		webhooks, err := r.Webhooks.List()
+		if err != nil || webhooks == nil || len(webhooks) != 0 {
+			t.Errorf("List() of empty storage = %v, %v, want an empty list", webhooks, err)
+		}
+		webhook := repo.Webhook{URL: "https://erp.example.com/hooks", Secret: "secret", Events: "drone-loaded,battery-low"}
+		id, err := r.Webhooks.Create(&webhook)
+		if err != nil || id == 0 || id != webhook.ID {
+			t.Fatalf("Create() = %v, %v, want the id of the webhook", id, err)
+		}
+		if _, err := r.Webhooks.Create(&repo.Webhook{URL: "https://ward.example.com/hooks", Secret: "other"}); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		got, err := r.Webhooks.Get(id)
+		if err != nil || got.URL != webhook.URL || got.Secret != "secret" || got.Events != webhook.Events || !got.CreatedAt.Equal(r.Clock.Now()) {
+			t.Errorf("Get() = %+v, %v, want %+v created now", got, err, webhook)
+		}
+		_, err = r.Webhooks.Get(missingID)
+		wantError(t, "Get() of a missing webhook", err, repo.ErrRecordNotFound)
+
+		wantError(t, "Delete()", r.Webhooks.Delete(id), nil)
+		wantError(t, "Delete() again", r.Webhooks.Delete(id), repo.ErrRecordNotFound)
+		_, err = r.Webhooks.Get(id)
+		wantError(t, "Get() of a deleted webhook", err, repo.ErrRecordNotFound)
+		if webhooks, _ := r.Webhooks.List(); len(webhooks) != 1 || webhooks[0].Secret != "other" {
+			t.Errorf("List() after Delete() = %+v, want the other webhook", webhooks)
+		}
+	})
+	t.Run("Deliveries", func(t *testing.T) {
+		r := factory(t)
+		now := r.Clock.Now()
+		later := now.Add(time.Minute)
+		id, _ := r.Webhooks.Create(&repo.Webhook{URL: "https://erp.example.com/hooks", Secret: "secret"})
+		otherID, _ := r.Webhooks.Create(&repo.Webhook{URL: "https://ward.example.com/hooks", Secret: "other"})
+		deliveries := []repo.WebhookDelivery{
+			{WebhookID: id, EventID: 1, EventType: "drone-loaded", Payload: `{"id":1}`, NextAttemptAt: &now},
+			{WebhookID: id, EventID: 2, EventType: "battery-low", Payload: `{"id":2}`, NextAttemptAt: &later},
+			{WebhookID: otherID, EventID: 1, EventType: "drone-loaded", Payload: `{"id":1}`, NextAttemptAt: &now},
+		}
+		wantError(t, "CreateDeliveries()", r.Webhooks.CreateDeliveries(deliveries), nil)
+		wantError(t, "CreateDeliveries() of no deliveries", r.Webhooks.CreateDeliveries(nil), nil)
+		for _, delivery := range deliveries {
+			if delivery.ID == 0 {
+				t.Errorf("CreateDeliveries() left delivery %+v without an id", delivery)
+			}
+		}
+
+		due, err := r.Webhooks.DueDeliveries(now, 0)
+		if err != nil || len(due) != 2 || due[0].ID != deliveries[0].ID || due[1].ID != deliveries[2].ID {
+			t.Fatalf("DueDeliveries() = %+v, %v, want the deliveries due now", due, err)
+		}
+		if due[0].Status != "PENDING" || due[0].Payload != `{"id":1}` || due[0].EventType != "drone-loaded" {
+			t.Errorf("DueDeliveries()[0] = %+v, want the stored PENDING delivery", due[0])
+		}
+		if due, _ := r.Webhooks.DueDeliveries(later, 1); len(due) != 1 || due[0].ID != deliveries[0].ID {
+			t.Errorf("DueDeliveries() with limit = %+v, want the first due delivery", due)
+		}
+
+		failed := due[0]
+		failed.AttemptCount, failed.NextAttemptAt = 1, &later
+		wantError(t, "RecordAttempt() of a failure", r.Webhooks.RecordAttempt(failed, repo.WebhookAttempt{AttemptedAt: now, StatusCode: 500, Error: "status 500"}), nil)
+		succeeded := failed
+		succeeded.Status, succeeded.AttemptCount, succeeded.NextAttemptAt = "SUCCEEDED", 2, nil
+		wantError(t, "RecordAttempt() of a success", r.Webhooks.RecordAttempt(succeeded, repo.WebhookAttempt{AttemptedAt: later, StatusCode: 204}), nil)
+		missing := succeeded
+		missing.ID = missingID
+		wantError(t, "RecordAttempt() of a missing delivery", r.Webhooks.RecordAttempt(missing, repo.WebhookAttempt{AttemptedAt: later}), repo.ErrRecordNotFound)
+
+		listed, err := r.Webhooks.ListDeliveries(id)
+		if err != nil || len(listed) != 2 || listed[0].ID != deliveries[1].ID {
+			t.Fatalf("ListDeliveries() = %+v, %v, want the 2 deliveries of the webhook, the newest first", listed, err)
+		}
+		got := listed[1]
+		if got.Status != "SUCCEEDED" || got.AttemptCount != 2 || got.NextAttemptAt != nil || len(got.Attempts) != 2 {
+			t.Fatalf("ListDeliveries()[1] = %+v, want the SUCCEEDED delivery with 2 attempts", got)
+		}
+		if got.Attempts[0].StatusCode != 500 || got.Attempts[0].Error != "status 500" || !got.Attempts[0].AttemptedAt.Equal(now) ||
+			got.Attempts[1].StatusCode != 204 || got.Attempts[1].ID == 0 {
+			t.Errorf("ListDeliveries() attempts = %+v, want the failure then the success", got.Attempts)
+		}
+
+		// the deliveries of a deleted webhook are not attempted anymore
+		wantError(t, "Delete()", r.Webhooks.Delete(otherID), nil)
+		if due, _ := r.Webhooks.DueDeliveries(later, 0); len(due) != 1 || due[0].ID != deliveries[1].ID {
+			t.Errorf("DueDeliveries() after Delete() = %+v, want only the delivery of the existing webhook", due)
+		}
+		if listed, _ := r.Webhooks.ListDeliveries(otherID); len(listed) != 1 {
+			t.Errorf("ListDeliveries() of a deleted webhook = %+v, want its delivery kept", listed)
+		}
+	})
+}
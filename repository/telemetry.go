@@ -0,0 +1,211 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// telemetryBatchSize is the number of readings written or deleted by one statement.
+const telemetryBatchSize = 500
+
+// TelemetryFilter narrows the readings returned by List, zero times are not filtered on.
+type TelemetryFilter struct {
+	DroneID int
+	From    time.Time
+	To      time.Time
+	Limit   int
+}
+
+type ITelemetryRepository interface {
+	Create(readings []Telemetry) error
+	List(filter TelemetryFilter) ([]Telemetry, error)
+	DeleteBefore(before time.Time) (int, error)
+	Downsample(from, before time.Time, bucket time.Duration) (int, error)
+}
+
+type telemetryRepo struct {
+	client *gorm.DB
+}
+
+func NewTelemetryRepo(client *gorm.DB) ITelemetryRepository {
+	return &telemetryRepo{
+		client: client,
+	}
+}
+
+func (t *telemetryRepo) Create(readings []Telemetry) error {
+	if len(readings) == 0 {
+		return nil
+	}
+	return t.client.CreateInBatches(readings, telemetryBatchSize).Error
+}
+
+// List returns the readings of the drone recorded from From until before To, the oldest first.
+func (t *telemetryRepo) List(filter TelemetryFilter) ([]Telemetry, error) {
+	query := t.client.Where("drone_id = ?", filter.DroneID)
+	if !filter.From.IsZero() {
+		query = query.Where("recorded_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("recorded_at < ?", filter.To)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	readings := []Telemetry{}
+	if result := query.Order("recorded_at, id").Find(&readings); result.Error != nil {
+		return nil, result.Error
+	}
+	return readings, nil
+}
+
+// DeleteBefore deletes the readings recorded before the time and returns how many were deleted.
+func (t *telemetryRepo) DeleteBefore(before time.Time) (int, error) {
+	result := t.client.Where("recorded_at < ?", before).Delete(&Telemetry{})
+	return int(result.RowsAffected), result.Error
+}
+
+// Downsample merges the readings recorded from the time until before the other into one reading per drone and bucket,
+// it returns how many readings less are stored. A zero from is not filtered on.
+func (t *telemetryRepo) Downsample(from, before time.Time, bucket time.Duration) (int, error) {
+	page := func(cursor telemetryCursor) ([]Telemetry, error) {
+		query := t.client.Where("recorded_at < ?", before)
+		if !from.IsZero() {
+			query = query.Where("recorded_at >= ?", from)
+		}
+		if cursor.droneID > 0 {
+			query = query.Where("(drone_id > ? OR (drone_id = ? AND recorded_at >= ?))", cursor.droneID, cursor.droneID, cursor.recordedAt)
+		}
+		var readings []Telemetry
+		if result := query.Order("drone_id, recorded_at, id").Limit(telemetryBatchSize).Find(&readings); result.Error != nil {
+			return nil, result.Error
+		}
+		return readings, nil
+	}
+	merge := func(merged []Telemetry, replaced []int) error {
+		return t.client.Transaction(func(tx *gorm.DB) error {
+			if result := tx.Delete(&Telemetry{}, replaced); result.Error != nil {
+				return result.Error
+			}
+			return tx.Create(merged).Error
+		})
+	}
+	return downsample(bucket, page, merge)
+}
+
+// telemetryCursor is where the next page of readings to downsample starts, the readings of a later drone
+// or of the same drone recorded from the time.
+type telemetryCursor struct {
+	droneID    int
+	recordedAt time.Time
+}
+
+// downsample merges the readings one page at a time so a long window is never held in memory or in one transaction.
+// page returns up to telemetryBatchSize readings from the cursor ordered by drone and time, merge replaces readings
+// by the merged ones. The last bucket of a full page may go on in the next page so it is left to the next page,
+// unless it fills the page: it is merged then and its merged reading is merged again with the rest of the bucket.
+func downsample(bucket time.Duration, page func(cursor telemetryCursor) ([]Telemetry, error), merge func(merged []Telemetry, replaced []int) error) (int, error) {
+	removed := 0
+	cursor := telemetryCursor{}
+	for {
+		readings, err := page(cursor)
+		if err != nil {
+			return removed, err
+		}
+		full := len(readings) == telemetryBatchSize
+		batch := readings
+		if full {
+			last := readings[len(readings)-1]
+			cursor = telemetryCursor{droneID: last.DroneID, recordedAt: last.RecordedAt.Truncate(bucket)}
+			start := len(readings)
+			for start > 0 && readings[start-1].DroneID == cursor.droneID && !readings[start-1].RecordedAt.Before(cursor.recordedAt) {
+				start--
+			}
+			if start > 0 {
+				batch = readings[:start]
+			}
+		}
+		if merged, replaced := mergeReadings(batch, bucket); len(merged) > 0 {
+			if err := merge(merged, replaced); err != nil {
+				return removed, err
+			}
+			removed += len(replaced) - len(merged)
+		}
+		if !full {
+			return removed, nil
+		}
+	}
+}
+
+// mergeReadings merges the readings of a drone in the same bucket into one reading at the start of the bucket,
+// the values are averaged by the samples of the readings and the state is the last one reported. The readings
+// must be ordered by drone and time, it returns the merged readings and the ids of the readings they replace.
+// Buckets of one reading are kept as they are so downsampling again changes nothing.
+func mergeReadings(readings []Telemetry, bucket time.Duration) ([]Telemetry, []int) {
+	merged := []Telemetry{}
+	replaced := []int{}
+	for start := 0; start < len(readings); {
+		first := readings[start]
+		bucketStart := first.RecordedAt.Truncate(bucket)
+		end := start + 1
+		for end < len(readings) && readings[end].DroneID == first.DroneID && readings[end].RecordedAt.Truncate(bucket).Equal(bucketStart) {
+			end++
+		}
+		if end-start > 1 {
+			merged = append(merged, mergeBucket(readings[start:end], bucketStart))
+			for _, reading := range readings[start:end] {
+				replaced = append(replaced, reading.ID)
+			}
+		}
+		start = end
+	}
+	return merged, replaced
+}
+
+func mergeBucket(readings []Telemetry, recordedAt time.Time) Telemetry {
+	result := Telemetry{DroneID: readings[0].DroneID, RecordedAt: recordedAt}
+	var battery, latitude, longitude, altitude, speed average
+	for _, reading := range readings {
+		samples := reading.Samples
+		if samples < 1 {
+			samples = 1
+		}
+		battery.add(reading.BatteryLevel, samples)
+		if reading.Latitude != nil && reading.Longitude != nil {
+			latitude.add(reading.Latitude, samples)
+			longitude.add(reading.Longitude, samples)
+		}
+		altitude.add(reading.Altitude, samples)
+		speed.add(reading.Speed, samples)
+		result.Samples += samples
+		if reading.State != "" {
+			result.State = reading.State
+		}
+	}
+	result.BatteryLevel, result.Latitude, result.Longitude = battery.value(), latitude.value(), longitude.value()
+	result.Altitude, result.Speed = altitude.value(), speed.value()
+	return result
+}
+
+// average is the mean of the reported values weighted by their samples, values that were not reported are left out.
+type average struct {
+	sum     float64
+	samples int
+}
+
+func (a *average) add(value *float64, samples int) {
+	if value == nil {
+		return
+	}
+	a.sum += *value * float64(samples)
+	a.samples += samples
+}
+
+func (a average) value() *float64 {
+	if a.samples == 0 {
+		return nil
+	}
+	value := a.sum / float64(a.samples)
+	return &value
+}
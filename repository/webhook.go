@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type IWebhookRepository interface {
+	Create(webhook *Webhook) (int, error)
+	Get(id int) (Webhook, error)
+	List() ([]Webhook, error)
+	Delete(id int) error
+	CreateDeliveries(deliveries []WebhookDelivery) error
+	ListDeliveries(webhookID int) ([]WebhookDelivery, error)
+	DueDeliveries(now time.Time, limit int) ([]WebhookDelivery, error)
+	RecordAttempt(delivery WebhookDelivery, attempt WebhookAttempt) error
+}
+
+type webhookRepo struct {
+	client *gorm.DB
+}
+
+func NewWebhookRepo(client *gorm.DB) IWebhookRepository {
+	return &webhookRepo{
+		client: client,
+	}
+}
+
+func (w *webhookRepo) Create(webhook *Webhook) (int, error) {
+	if result := w.client.Create(webhook); result.Error != nil {
+		return 0, result.Error
+	}
+	return webhook.ID, nil
+}
+
+func (w *webhookRepo) Get(id int) (Webhook, error) {
+	var webhook Webhook
+	if result := w.client.First(&webhook, id); result.Error != nil {
+		return Webhook{}, result.Error
+	}
+	return webhook, nil
+}
+
+func (w *webhookRepo) List() ([]Webhook, error) {
+	webhooks := []Webhook{}
+	if result := w.client.Order("id").Find(&webhooks); result.Error != nil {
+		return nil, result.Error
+	}
+	return webhooks, nil
+}
+
+// Delete removes the webhook, its deliveries are kept but not attempted anymore.
+func (w *webhookRepo) Delete(id int) error {
+	result := w.client.Delete(&Webhook{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+func (w *webhookRepo) CreateDeliveries(deliveries []WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return w.client.Create(&deliveries).Error
+}
+
+// ListDeliveries lists the deliveries of the webhook with their attempts, the newest first.
+func (w *webhookRepo) ListDeliveries(webhookID int) ([]WebhookDelivery, error) {
+	deliveries := []WebhookDelivery{}
+	result := w.client.Preload("Attempts", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Where("webhook_id = ?", webhookID).Order("id desc").Find(&deliveries)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return deliveries, nil
+}
+
+// DueDeliveries returns the deliveries of the existing webhooks due for an attempt at now, the longest due first.
+func (w *webhookRepo) DueDeliveries(now time.Time, limit int) ([]WebhookDelivery, error) {
+	deliveries := []WebhookDelivery{}
+	query := w.client.
+		Where("next_attempt_at <= ?", now).
+		Where("webhook_id IN (?)", w.client.Model(&Webhook{}).Select("id")).
+		Order("next_attempt_at, id")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if result := query.Find(&deliveries); result.Error != nil {
+		return nil, result.Error
+	}
+	return deliveries, nil
+}
+
+// RecordAttempt saves the attempt and the status, attempt count and next attempt of the delivery.
+func (w *webhookRepo) RecordAttempt(delivery WebhookDelivery, attempt WebhookAttempt) error {
+	return w.client.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&WebhookDelivery{}).Where("id = ?", delivery.ID).Updates(map[string]interface{}{
+			"status":          delivery.Status,
+			"attempt_count":   delivery.AttemptCount,
+			"next_attempt_at": delivery.NextAttemptAt,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrRecordNotFound
+		}
+		attempt.DeliveryID = delivery.ID
+		return tx.Create(&attempt).Error
+	})
+}
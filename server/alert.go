@@ -0,0 +1,108 @@
+package server
+
+import (
+	"drone/v2/usecase"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type IAlertAPI interface {
+	ListAlerts(w http.ResponseWriter, r *http.Request)
+	GetAlert(w http.ResponseWriter, r *http.Request)
+	AcknowledgeAlert(w http.ResponseWriter, r *http.Request)
+	ResolveAlert(w http.ResponseWriter, r *http.Request)
+}
+
+type alertAPI struct {
+	alertUsecase usecase.IAlertUsecase
+}
+
+func NewAlertAPI(alertUsecase usecase.IAlertUsecase) IAlertAPI {
+	return &alertAPI{
+		alertUsecase: alertUsecase,
+	}
+}
+
+// ListAlerts lists the alerts filtered by the drone_id and status parameters, the newest first.
+func (api *alertAPI) ListAlerts(w http.ResponseWriter, r *http.Request) {
+	values := r.URL.Query()
+	var droneID int
+	if value := values.Get("drone_id"); value != "" {
+		var err error
+		if droneID, err = strconv.Atoi(value); err != nil {
+			http.Error(w, "Invaild drone id", http.StatusBadRequest)
+			return
+		}
+	}
+	alerts, err := api.alertUsecase.ListAlerts(droneID, values.Get("status"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, http.StatusOK, alerts)
+}
+
+func (api *alertAPI) GetAlert(w http.ResponseWriter, r *http.Request) {
+	id, err := alertIDFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	alert, err := api.alertUsecase.GetAlert(id)
+	if err != nil {
+		http.Error(w, err.Error(), alertErrorStatus(err))
+		return
+	}
+	writeJSON(w, http.StatusOK, alert)
+}
+
+func (api *alertAPI) AcknowledgeAlert(w http.ResponseWriter, r *http.Request) {
+	id, err := alertIDFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	alert, err := api.alertUsecase.AcknowledgeAlert(id)
+	if err != nil {
+		http.Error(w, err.Error(), alertErrorStatus(err))
+		return
+	}
+	writeJSON(w, http.StatusOK, alert)
+}
+
+// ResolveAlert closes the alert, resolving a critical battery alert releases the grounded drone.
+func (api *alertAPI) ResolveAlert(w http.ResponseWriter, r *http.Request) {
+	id, err := alertIDFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	alert, err := api.alertUsecase.ResolveAlert(id)
+	if err != nil {
+		http.Error(w, err.Error(), alertErrorStatus(err))
+		return
+	}
+	writeJSON(w, http.StatusOK, alert)
+}
+
+func alertIDFromRequest(r *http.Request) (int, error) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		return 0, errors.New("Invaild alert id")
+	}
+	return id, nil
+}
+
+func alertErrorStatus(err error) int {
+	var status *usecase.AlertStatusError
+	if errors.Is(err, usecase.ErrAlertNotFound) {
+		return http.StatusNotFound
+	}
+	if errors.As(err, &status) || errors.Is(err, usecase.ErrAlertChanged) {
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
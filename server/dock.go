@@ -0,0 +1,115 @@
+package server
+
+import (
+	"drone/v2/usecase"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type IDockAPI interface {
+	RegisterDock(w http.ResponseWriter, r *http.Request)
+	GetDock(w http.ResponseWriter, r *http.Request)
+	ListDocks(w http.ResponseWriter, r *http.Request)
+	DockDrone(w http.ResponseWriter, r *http.Request)
+	UndockDrone(w http.ResponseWriter, r *http.Request)
+}
+
+type dockAPI struct {
+	dockUsecase usecase.IDockUsecase
+}
+
+func NewDockAPI(dockUsecase usecase.IDockUsecase) IDockAPI {
+	return &dockAPI{
+		dockUsecase: dockUsecase,
+	}
+}
+
+func (api *dockAPI) RegisterDock(w http.ResponseWriter, r *http.Request) {
+	var dock DockPayload
+	if r.Body == nil {
+		http.Error(w, "register dock must have json payload", http.StatusBadRequest)
+		return
+	}
+	if err := json.NewDecoder(r.Body).Decode(&dock); err != nil {
+		http.Error(w, "Invaild json payload", http.StatusBadRequest)
+		return
+	}
+	id, err := api.dockUsecase.RegisterDock(usecase.DockObject(dock))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, http.StatusCreated, RegisterDockPayload{DockId: id})
+}
+
+func (api *dockAPI) GetDock(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invaild dock id", http.StatusBadRequest)
+		return
+	}
+	dock, err := api.dockUsecase.GetDock(id)
+	if err != nil {
+		http.Error(w, err.Error(), dockErrorStatus(err))
+		return
+	}
+	writeJSON(w, http.StatusOK, dock)
+}
+
+func (api *dockAPI) ListDocks(w http.ResponseWriter, r *http.Request) {
+	docks, err := api.dockUsecase.ListDocks()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, docks)
+}
+
+func (api *dockAPI) DockDrone(w http.ResponseWriter, r *http.Request) {
+	id, err := droneIDFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var payload DockDronePayload
+	if r.Body == nil || json.NewDecoder(r.Body).Decode(&payload) != nil {
+		http.Error(w, "Invaild json payload", http.StatusBadRequest)
+		return
+	}
+	drone, err := api.dockUsecase.DockDrone(id, payload.DockId)
+	if err != nil {
+		http.Error(w, err.Error(), dockErrorStatus(err))
+		return
+	}
+	writeJSON(w, http.StatusOK, drone)
+}
+
+func (api *dockAPI) UndockDrone(w http.ResponseWriter, r *http.Request) {
+	id, err := droneIDFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	drone, err := api.dockUsecase.UndockDrone(id)
+	if err != nil {
+		http.Error(w, err.Error(), dockErrorStatus(err))
+		return
+	}
+	writeJSON(w, http.StatusOK, drone)
+}
+
+func dockErrorStatus(err error) int {
+	var state *usecase.DockStateError
+	if errors.Is(err, usecase.ErrDockNotFound) || errors.Is(err, usecase.ErrDroneNotFound) {
+		return http.StatusNotFound
+	}
+	if errors.As(err, &state) || errors.Is(err, usecase.ErrDockFull) ||
+		errors.Is(err, usecase.ErrDroneDocked) || errors.Is(err, usecase.ErrDroneNotDocked) {
+		return http.StatusConflict
+	}
+	return transitionErrorStatus(err)
+}
@@ -3,6 +3,8 @@ package server
 import (
 	"drone/v2/usecase"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 
@@ -13,11 +15,31 @@ import (
 
 type IDroneAPI interface {
 	RegisterDrone(w http.ResponseWriter, r *http.Request)
+	GetDrone(w http.ResponseWriter, r *http.Request)
+	ListDrones(w http.ResponseWriter, r *http.Request)
+	UpdateDrone(w http.ResponseWriter, r *http.Request)
+	DecommissionDrone(w http.ResponseWriter, r *http.Request)
 	RegisterMedication(w http.ResponseWriter, r *http.Request)
+	GetMedication(w http.ResponseWriter, r *http.Request)
+	ListMedications(w http.ResponseWriter, r *http.Request)
+	UpdateMedication(w http.ResponseWriter, r *http.Request)
+	DeleteMedication(w http.ResponseWriter, r *http.Request)
 	LoadingMedication(w http.ResponseWriter, r *http.Request)
+	GetLoadingJob(w http.ResponseWriter, r *http.Request)
 	CheckLoadingMedication(w http.ResponseWriter, r *http.Request)
 	CheckAvailableDrones(w http.ResponseWriter, r *http.Request)
 	CheckDroneBattery(w http.ResponseWriter, r *http.Request)
+	RecalculatePayload(w http.ResponseWriter, r *http.Request)
+	UnloadMedication(w http.ResponseWriter, r *http.Request)
+	UnloadDrone(w http.ResponseWriter, r *http.Request)
+	TransitionDrone(w http.ResponseWriter, r *http.Request)
+	StartDelivery(w http.ResponseWriter, r *http.Request)
+	MarkDelivered(w http.ResponseWriter, r *http.Request)
+	ReturnDrone(w http.ResponseWriter, r *http.Request)
+	UpdatePosition(w http.ResponseWriter, r *http.Request)
+	SetDestination(w http.ResponseWriter, r *http.Request)
+	ListDronesNearby(w http.ResponseWriter, r *http.Request)
+	CanReach(w http.ResponseWriter, r *http.Request)
 }
 
 type droneAPI struct {
@@ -25,9 +47,10 @@ type droneAPI struct {
 	medicationUsecase usecase.IMedicationUsecase
 }
 
-func NewDroneAPI(droneUsecase usecase.IDroneUsecase) IDroneAPI {
+func NewDroneAPI(droneUsecase usecase.IDroneUsecase, medicationUsecase usecase.IMedicationUsecase) IDroneAPI {
 	return &droneAPI{
-		droneUsecase: droneUsecase,
+		droneUsecase:      droneUsecase,
+		medicationUsecase: medicationUsecase,
 	}
 }
 
@@ -60,35 +83,6 @@ func (api *droneAPI) RegisterDrone(w http.ResponseWriter, r *http.Request) {
 	w.Write(payload)
 }
 
-func (api *droneAPI) RegisterMedication(w http.ResponseWriter, r *http.Request) {
-	var medication MedicationPayload
-	if r.Body == nil {
-		http.Error(w, "register medication must have json payload", http.StatusBadRequest)
-		return
-	}
-	err := json.NewDecoder(r.Body).Decode(&medication)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		return
-	}
-	id, err := api.medicationUsecase.RegisterMedication(usecaseEntity.MedicationObject(medication))
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		return
-	}
-	payload := RegisterMediactionPayload{
-		MedicationId: id,
-	}
-	data, err := json.Marshal(payload)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-	w.WriteHeader(http.StatusCreated)
-	w.Header().Set("Content-Type", "application/json")
-	w.Write(data)
-}
-
 func (api *droneAPI) LoadingMedication(w http.ResponseWriter, r *http.Request) {
 	args, ok := mux.Vars(r)["id"]
 	if !ok {
@@ -102,24 +96,42 @@ func (api *droneAPI) LoadingMedication(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invaild drone id", http.StatusBadRequest)
 		return
 	}
-	var medication MedicationPayload
+	var load LoadPayload
 	if r.Body == nil {
 		http.Error(w, "load medication end point must have json payload", http.StatusBadRequest)
 		return
 	}
 
-	err = json.NewDecoder(r.Body).Decode(&medication)
+	err = json.NewDecoder(r.Body).Decode(&load)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	err = api.droneUsecase.LoadingMedication(id, usecaseEntity.MedicationObject(medication))
+	job, err := api.droneUsecase.LoadingMedication(id, usecaseEntity.LoadObject(load))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		status := transitionErrorStatus(err)
+		if errors.Is(err, usecase.ErrLoadingQueueFull) {
+			status = http.StatusServiceUnavailable
+		}
+		http.Error(w, err.Error(), status)
 		return
 	}
-	w.WriteHeader(http.StatusCreated)
-	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", fmt.Sprintf("/api/jobs/%d", job.ID))
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+func (api *droneAPI) GetLoadingJob(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invaild job id", http.StatusBadRequest)
+		return
+	}
+	job, err := api.droneUsecase.GetLoadingJob(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
 }
 
 func (api *droneAPI) CheckLoadingMedication(w http.ResponseWriter, r *http.Request) {
@@ -199,3 +211,143 @@ func (api *droneAPI) CheckDroneBattery(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.Write(data)
 }
+
+func (api *droneAPI) RecalculatePayload(w http.ResponseWriter, r *http.Request) {
+	id, err := droneIDFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	drone, err := api.droneUsecase.RecalculatePayload(id)
+	if err != nil {
+		http.Error(w, err.Error(), transitionErrorStatus(err))
+		return
+	}
+	writeJSON(w, http.StatusOK, drone)
+}
+
+func (api *droneAPI) UnloadMedication(w http.ResponseWriter, r *http.Request) {
+	id, err := droneIDFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	drone, err := api.droneUsecase.UnloadMedication(id, mux.Vars(r)["code"])
+	if err != nil {
+		http.Error(w, err.Error(), unloadErrorStatus(err))
+		return
+	}
+	writeJSON(w, http.StatusOK, drone)
+}
+
+func (api *droneAPI) UnloadDrone(w http.ResponseWriter, r *http.Request) {
+	id, err := droneIDFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	drone, err := api.droneUsecase.UnloadDrone(id)
+	if err != nil {
+		http.Error(w, err.Error(), unloadErrorStatus(err))
+		return
+	}
+	writeJSON(w, http.StatusOK, drone)
+}
+
+func (api *droneAPI) TransitionDrone(w http.ResponseWriter, r *http.Request) {
+	id, err := droneIDFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var transition TransitionPayload
+	if r.Body == nil {
+		http.Error(w, "transition end point must have json payload", http.StatusBadRequest)
+		return
+	}
+	err = json.NewDecoder(r.Body).Decode(&transition)
+	if err != nil {
+		http.Error(w, "Invaild json payload", http.StatusBadRequest)
+		return
+	}
+	err = api.droneUsecase.ChangeDroneState(id, transition.State)
+	writeTransitionResponse(w, id, transition.State, err)
+}
+
+func (api *droneAPI) StartDelivery(w http.ResponseWriter, r *http.Request) {
+	id, err := droneIDFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	err = api.droneUsecase.StartDelivery(id)
+	writeTransitionResponse(w, id, usecase.StateDelivering, err)
+}
+
+func (api *droneAPI) MarkDelivered(w http.ResponseWriter, r *http.Request) {
+	id, err := droneIDFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	err = api.droneUsecase.MarkDelivered(id)
+	writeTransitionResponse(w, id, usecase.StateDelivered, err)
+}
+
+func (api *droneAPI) ReturnDrone(w http.ResponseWriter, r *http.Request) {
+	id, err := droneIDFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	err = api.droneUsecase.ReturnToBase(id)
+	writeTransitionResponse(w, id, usecase.StateReturning, err)
+}
+
+func droneIDFromRequest(r *http.Request) (int, error) {
+	args, ok := mux.Vars(r)["id"]
+	if !ok {
+		return 0, errors.New("Couldn't find id in request URL")
+	}
+	id, err := strconv.Atoi(args)
+	if err != nil {
+		return 0, errors.New("Invaild drone id")
+	}
+	return id, nil
+}
+
+func writeTransitionResponse(w http.ResponseWriter, id int, state string, err error) {
+	if err != nil {
+		http.Error(w, err.Error(), transitionErrorStatus(err))
+		return
+	}
+	writeJSON(w, http.StatusOK, DroneStatePayload{
+		DroneId: id,
+		State:   state,
+	})
+}
+
+func transitionErrorStatus(err error) int {
+	var illegal *usecase.IllegalTransitionError
+	var outOfRange *usecase.RangeError
+	if errors.Is(err, usecase.ErrDroneNotFound) {
+		return http.StatusNotFound
+	}
+	if errors.As(err, &illegal) || errors.As(err, &outOfRange) || errors.Is(err, usecase.ErrConcurrentUpdate) ||
+		errors.Is(err, usecase.ErrDroneGrounded) || errors.Is(err, usecase.ErrLoadingInProgress) ||
+		errors.Is(err, usecase.ErrDroneNotEmpty) {
+		return http.StatusConflict
+	}
+	return http.StatusBadRequest
+}
+
+func unloadErrorStatus(err error) int {
+	var state *usecase.UnloadStateError
+	if errors.As(err, &state) || errors.Is(err, usecase.ErrLoadingInProgress) {
+		return http.StatusConflict
+	}
+	if errors.Is(err, usecase.ErrMedicationNotFound) {
+		return http.StatusNotFound
+	}
+	return transitionErrorStatus(err)
+}
@@ -1,11 +1,27 @@
 package server
 
+import "time"
+
 type DornePayload struct {
-	SerialNumber string  `json:"serial_number" valid:"required~Serial Number is not provided,stringlength(10|100)"`
-	Model        string  `json:"model" valid:"required~Model is not provided,matches(Lightweight|Middleweight|Cruiserweight|Heavyweight)"`
-	Weight       float32 `json:"weight" valid:"required~Weight is not provided,range(10|500)"`
-	Battery      int     `json:"battery" valid:"optional, range(10|100)"`
-	State        string  `json:"state" valid:"optional,matches(IDLE|LOADING|LOADED|DELIVERING|DELIVERED|RETURNING)"`
+	SerialNumber  string  `json:"serial_number" valid:"required~Serial Number is not provided,stringlength(10|100)"`
+	Model         string  `json:"model" valid:"required~Model is not provided,matches(Lightweight|Middleweight|Cruiserweight|Heavyweight)"`
+	Weight        float32 `json:"weight" valid:"required~Weight is not provided,range(10|500)"`
+	Battery       int     `json:"battery" valid:"optional, range(10|100)"`
+	State         string  `json:"state" valid:"optional,matches(IDLE|LOADING|LOADED|DELIVERING|DELIVERED|RETURNING)"`
+	HomeLatitude  float64 `json:"home_latitude"`
+	HomeLongitude float64 `json:"home_longitude"`
+}
+
+type UpdateDronePayload struct {
+	Model         string   `json:"model"`
+	Weight        float32  `json:"weight"`
+	HomeLatitude  *float64 `json:"home_latitude"`
+	HomeLongitude *float64 `json:"home_longitude"`
+}
+
+type LocationPayload struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
 }
 
 type MedicationPayload struct {
@@ -15,12 +31,18 @@ type MedicationPayload struct {
 	Image  string  `json:"image" valid:"optional,url"`
 }
 
+type LoadPayload struct {
+	Code      string `json:"code"`
+	Quantity  int    `json:"quantity"`
+	LotNumber string `json:"lot_number"`
+}
+
 type RegisterDronePayload struct {
 	DroneId int `json:"drone_id"`
 }
 
 type RegisterMediactionPayload struct {
-	MedicationId int `json:"medication_id"`
+	Code string `json:"code"`
 }
 
 type CheckLoadingMedicationPayload struct {
@@ -32,3 +54,57 @@ type BatteryLevelPayload struct {
 	DroneId      int    `json:"drone_id"`
 	BatteryLevel string `json:"battery_level"`
 }
+
+type TransitionPayload struct {
+	State string `json:"state"`
+}
+
+type DroneStatePayload struct {
+	DroneId int    `json:"drone_id"`
+	State   string `json:"state"`
+}
+
+type DockPayload struct {
+	Name       string  `json:"name"`
+	Latitude   float64 `json:"latitude"`
+	Longitude  float64 `json:"longitude"`
+	Slots      int     `json:"slots"`
+	ChargeRate float64 `json:"charge_rate"`
+}
+
+type RegisterDockPayload struct {
+	DockId int `json:"dock_id"`
+}
+
+type DockDronePayload struct {
+	DockId int `json:"dock_id"`
+}
+
+type OrderItemPayload struct {
+	Code     string `json:"code"`
+	Quantity int    `json:"quantity"`
+}
+
+type OrderPayload struct {
+	Destination          string             `json:"destination"`
+	Priority             int                `json:"priority"`
+	DestinationLatitude  *float64           `json:"destination_latitude"`
+	DestinationLongitude *float64           `json:"destination_longitude"`
+	Items                []OrderItemPayload `json:"items"`
+}
+
+type TelemetryPayload struct {
+	RecordedAt   time.Time `json:"recorded_at"`
+	BatteryLevel *float64  `json:"battery_level"`
+	Latitude     *float64  `json:"latitude"`
+	Longitude    *float64  `json:"longitude"`
+	Altitude     *float64  `json:"altitude"`
+	Speed        *float64  `json:"speed"`
+	State        string    `json:"state"`
+}
+
+type WebhookSubscriptionPayload struct {
+	URL    string   `json:"url"`
+	Secret string   `json:"secret"`
+	Events []string `json:"events"`
+}
@@ -0,0 +1,155 @@
+package server
+
+import (
+	"drone/v2/usecase"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// heartbeatInterval keeps idle streams open through proxies that close silent connections
+const heartbeatInterval = 15 * time.Second
+
+type IEventAPI interface {
+	StreamEvents(w http.ResponseWriter, r *http.Request)
+	WebSocketEvents(w http.ResponseWriter, r *http.Request)
+}
+
+type eventAPI struct {
+	events   usecase.IEventBus
+	upgrader websocket.Upgrader
+}
+
+func NewEventAPI(events usecase.IEventBus) IEventAPI {
+	return &eventAPI{
+		events: events,
+	}
+}
+
+// subscribe subscribes to the events picked by the drone_id and type query parameters, the type can
+// be repeated or comma separated. It resumes after the Last-Event-ID header or the last_event_id parameter.
+func (api *eventAPI) subscribe(r *http.Request) (*usecase.Subscription, error) {
+	var filter usecase.EventFilter
+	var lastEventID int64
+	var err error
+	values := r.URL.Query()
+	if value := values.Get("drone_id"); value != "" {
+		if filter.DroneID, err = strconv.Atoi(value); err != nil {
+			return nil, errors.New("Invaild drone id")
+		}
+	}
+	for _, value := range values["type"] {
+		for _, eventType := range strings.Split(value, ",") {
+			if eventType = strings.TrimSpace(eventType); eventType != "" {
+				filter.Types = append(filter.Types, eventType)
+			}
+		}
+	}
+	value := r.Header.Get("Last-Event-ID")
+	if value == "" {
+		value = values.Get("last_event_id")
+	}
+	if value != "" {
+		if lastEventID, err = strconv.ParseInt(value, 10, 64); err != nil {
+			return nil, errors.New("Invaild last event id")
+		}
+	}
+	return api.events.Subscribe(filter, lastEventID)
+}
+
+// StreamEvents streams the events as server-sent events until the client goes away.
+func (api *eventAPI) StreamEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+	subscription, err := api.subscribe(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer api.events.Unsubscribe(subscription)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+		case event, open := <-subscription.Events:
+			if !open {
+				// the client fell behind, it reconnects with the last event id
+				return
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				log.Println(err.Error())
+				continue
+			}
+			fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+		}
+		flusher.Flush()
+	}
+}
+
+// WebSocketEvents sends the events as json messages over a websocket until either side closes it.
+func (api *eventAPI) WebSocketEvents(w http.ResponseWriter, r *http.Request) {
+	subscription, err := api.subscribe(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer api.events.Unsubscribe(subscription)
+	conn, err := api.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// the upgrader already answered the request
+		return
+	}
+	defer conn.Close()
+
+	// the client does not send anything, reading only notices when it closes the connection
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-closed:
+			return
+		case <-heartbeat.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(heartbeatInterval)); err != nil {
+				return
+			}
+		case event, open := <-subscription.Events:
+			if !open {
+				conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "too far behind"))
+				return
+			}
+			if err := conn.WriteJSON(event); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"drone/v2/usecase"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+func (api *droneAPI) GetDrone(w http.ResponseWriter, r *http.Request) {
+	id, err := droneIDFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	drone, err := api.droneUsecase.GetDrone(id)
+	if err != nil {
+		http.Error(w, err.Error(), droneErrorStatus(err))
+		return
+	}
+	writeJSON(w, http.StatusOK, drone)
+}
+
+func (api *droneAPI) ListDrones(w http.ResponseWriter, r *http.Request) {
+	values := r.URL.Query()
+	query := usecase.DroneQuery{
+		State: values.Get("state"),
+		Model: values.Get("model"),
+		Sort:  values.Get("sort"),
+	}
+	var err error
+	for key, target := range map[string]*int{
+		"min_battery": &query.MinBattery,
+		"page":        &query.Page,
+		"page_size":   &query.PageSize,
+	} {
+		if *target, err = queryInt(values, key); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	page, err := api.droneUsecase.ListDrones(query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+func (api *droneAPI) UpdateDrone(w http.ResponseWriter, r *http.Request) {
+	id, err := droneIDFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var update UpdateDronePayload
+	if r.Body == nil {
+		http.Error(w, "update drone must have json payload", http.StatusBadRequest)
+		return
+	}
+	err = json.NewDecoder(r.Body).Decode(&update)
+	if err != nil {
+		http.Error(w, "Invaild json payload", http.StatusBadRequest)
+		return
+	}
+	drone, err := api.droneUsecase.UpdateDrone(id, usecase.DroneUpdateObject(update))
+	if err != nil {
+		http.Error(w, err.Error(), droneErrorStatus(err))
+		return
+	}
+	writeJSON(w, http.StatusOK, drone)
+}
+
+func (api *droneAPI) DecommissionDrone(w http.ResponseWriter, r *http.Request) {
+	id, err := droneIDFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := api.droneUsecase.DecommissionDrone(id); err != nil {
+		http.Error(w, err.Error(), droneErrorStatus(err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func queryInt(values url.Values, key string) (int, error) {
+	value := values.Get(key)
+	if value == "" {
+		return 0, nil
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, errors.New(fmt.Sprintf("Invaild %s", key))
+	}
+	return number, nil
+}
+
+func droneErrorStatus(err error) int {
+	if errors.Is(err, usecase.ErrDroneNotFound) {
+		return http.StatusNotFound
+	}
+	if errors.Is(err, usecase.ErrDroneNotIdle) {
+		return http.StatusConflict
+	}
+	return transitionErrorStatus(err)
+}
@@ -0,0 +1,127 @@
+package server
+
+import (
+	"drone/v2/usecase"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+func (api *droneAPI) UpdatePosition(w http.ResponseWriter, r *http.Request) {
+	id, location, err := locationFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	drone, err := api.droneUsecase.UpdatePosition(id, location)
+	if err != nil {
+		http.Error(w, err.Error(), droneErrorStatus(err))
+		return
+	}
+	writeJSON(w, http.StatusOK, drone)
+}
+
+func (api *droneAPI) SetDestination(w http.ResponseWriter, r *http.Request) {
+	id, location, err := locationFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	drone, err := api.droneUsecase.SetDestination(id, location)
+	if err != nil {
+		status := droneErrorStatus(err)
+		if errors.Is(err, usecase.ErrDroneFlying) {
+			status = http.StatusConflict
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	writeJSON(w, http.StatusOK, drone)
+}
+
+// ListDronesNearby lists the drones within radius kilometers of lat,lng.
+func (api *droneAPI) ListDronesNearby(w http.ResponseWriter, r *http.Request) {
+	values := r.URL.Query()
+	if values.Get("lat") == "" || values.Get("lng") == "" {
+		http.Error(w, "lat and lng are not provided", http.StatusBadRequest)
+		return
+	}
+	var query usecase.NearbyQuery
+	var err error
+	for key, target := range map[string]*float64{
+		"lat":    &query.Latitude,
+		"lng":    &query.Longitude,
+		"radius": &query.Radius,
+	} {
+		if *target, err = queryFloat(values, key); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	drones, err := api.droneUsecase.ListDronesNearby(query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, http.StatusOK, drones)
+}
+
+// CanReach estimates if the drone can deliver to lat,lng and come back to its home base.
+func (api *droneAPI) CanReach(w http.ResponseWriter, r *http.Request) {
+	id, err := droneIDFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	values := r.URL.Query()
+	if values.Get("lat") == "" || values.Get("lng") == "" {
+		http.Error(w, "lat and lng are not provided", http.StatusBadRequest)
+		return
+	}
+	var location usecase.LocationObject
+	if location.Latitude, err = queryFloat(values, "lat"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if location.Longitude, err = queryFloat(values, "lng"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	trip, err := api.droneUsecase.CanReach(id, location)
+	if err != nil {
+		http.Error(w, err.Error(), droneErrorStatus(err))
+		return
+	}
+	writeJSON(w, http.StatusOK, trip)
+}
+
+func locationFromRequest(r *http.Request) (int, usecase.LocationObject, error) {
+	id, err := droneIDFromRequest(r)
+	if err != nil {
+		return 0, usecase.LocationObject{}, err
+	}
+	var location LocationPayload
+	if r.Body == nil {
+		return 0, usecase.LocationObject{}, errors.New("location end point must have json payload")
+	}
+	if err := json.NewDecoder(r.Body).Decode(&location); err != nil {
+		return 0, usecase.LocationObject{}, errors.New("Invaild json payload")
+	}
+	return id, usecase.LocationObject(location), nil
+}
+
+func queryFloat(values url.Values, key string) (float64, error) {
+	value := values.Get(key)
+	if value == "" {
+		return 0, nil
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+		return 0, errors.New(fmt.Sprintf("Invaild %s", key))
+	}
+	return number, nil
+}
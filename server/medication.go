@@ -0,0 +1,101 @@
+package server
+
+import (
+	"drone/v2/usecase"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	usecaseEntity "drone/v2/usecase"
+
+	"github.com/gorilla/mux"
+)
+
+func (api *droneAPI) RegisterMedication(w http.ResponseWriter, r *http.Request) {
+	var medication MedicationPayload
+	if r.Body == nil {
+		http.Error(w, "register medication must have json payload", http.StatusBadRequest)
+		return
+	}
+	err := json.NewDecoder(r.Body).Decode(&medication)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	code, err := api.medicationUsecase.RegisterMedication(usecaseEntity.MedicationObject(medication))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	payload := RegisterMediactionPayload{
+		Code: code,
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+func (api *droneAPI) GetMedication(w http.ResponseWriter, r *http.Request) {
+	code := mux.Vars(r)["code"]
+	medication, err := api.medicationUsecase.GetMedication(code)
+	if err != nil {
+		http.Error(w, err.Error(), medicationErrorStatus(err))
+		return
+	}
+	writeJSON(w, http.StatusOK, medication)
+}
+
+func (api *droneAPI) ListMedications(w http.ResponseWriter, r *http.Request) {
+	medications, err := api.medicationUsecase.ListMedications()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, medications)
+}
+
+func (api *droneAPI) UpdateMedication(w http.ResponseWriter, r *http.Request) {
+	code := mux.Vars(r)["code"]
+	var medication MedicationPayload
+	if r.Body == nil {
+		http.Error(w, "update medication must have json payload", http.StatusBadRequest)
+		return
+	}
+	err := json.NewDecoder(r.Body).Decode(&medication)
+	if err != nil {
+		http.Error(w, "Invaild json payload", http.StatusBadRequest)
+		return
+	}
+	err = api.medicationUsecase.UpdateMedication(code, usecaseEntity.MedicationObject(medication))
+	if err != nil {
+		http.Error(w, err.Error(), medicationErrorStatus(err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (api *droneAPI) DeleteMedication(w http.ResponseWriter, r *http.Request) {
+	code := mux.Vars(r)["code"]
+	err := api.medicationUsecase.DeleteMedication(code)
+	if err != nil {
+		http.Error(w, err.Error(), medicationErrorStatus(err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func medicationErrorStatus(err error) int {
+	if errors.Is(err, usecase.ErrMedicationNotFound) {
+		return http.StatusNotFound
+	}
+	var inUse *usecase.MedicationInUseError
+	if errors.As(err, &inUse) {
+		return http.StatusConflict
+	}
+	return http.StatusBadRequest
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"drone/v2/usecase"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type IOrderAPI interface {
+	PlaceOrder(w http.ResponseWriter, r *http.Request)
+	GetOrder(w http.ResponseWriter, r *http.Request)
+	Dispatch(w http.ResponseWriter, r *http.Request)
+}
+
+type orderAPI struct {
+	orderUsecase usecase.IOrderUsecase
+}
+
+func NewOrderAPI(orderUsecase usecase.IOrderUsecase) IOrderAPI {
+	return &orderAPI{
+		orderUsecase: orderUsecase,
+	}
+}
+
+func (api *orderAPI) PlaceOrder(w http.ResponseWriter, r *http.Request) {
+	var payload OrderPayload
+	if r.Body == nil {
+		http.Error(w, "place order must have json payload", http.StatusBadRequest)
+		return
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "Invaild json payload", http.StatusBadRequest)
+		return
+	}
+	object := usecase.OrderObject{
+		Destination:          payload.Destination,
+		Priority:             payload.Priority,
+		DestinationLatitude:  payload.DestinationLatitude,
+		DestinationLongitude: payload.DestinationLongitude,
+	}
+	for _, item := range payload.Items {
+		object.Items = append(object.Items, usecase.OrderItemObject(item))
+	}
+	order, err := api.orderUsecase.PlaceOrder(object)
+	if err != nil {
+		// a saved order failed while it was loaded, otherwise the order was rejected
+		status := http.StatusBadRequest
+		if order.ID != 0 {
+			status = http.StatusConflict
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	writeJSON(w, http.StatusCreated, order)
+}
+
+func (api *orderAPI) GetOrder(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invaild order id", http.StatusBadRequest)
+		return
+	}
+	order, err := api.orderUsecase.GetOrder(id)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, usecase.ErrOrderNotFound) {
+			status = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	writeJSON(w, http.StatusOK, order)
+}
+
+// Dispatch loads the pending orders onto the fleet, with dry_run=true it only returns the plan.
+func (api *orderAPI) Dispatch(w http.ResponseWriter, r *http.Request) {
+	dryRun := false
+	if value := r.URL.Query().Get("dry_run"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			http.Error(w, "Invaild dry_run", http.StatusBadRequest)
+			return
+		}
+		dryRun = parsed
+	}
+	dispatch := api.orderUsecase.Dispatch
+	if dryRun {
+		dispatch = api.orderUsecase.PlanDispatch
+	}
+	plan, err := dispatch()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, plan)
+}
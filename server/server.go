@@ -1,7 +1,8 @@
 package server
 
 import (
-	"flag"
+	"drone/v2/settings"
+	"encoding/json"
 	"log"
 	"net/http"
 
@@ -9,23 +10,72 @@ import (
 )
 
 type APIs struct {
-	DroneAPI IDroneAPI
-	LogsAPI  LogsAPI
+	DroneAPI     IDroneAPI
+	LogsAPI      LogsAPI
+	DockAPI      IDockAPI
+	OrderAPI     IOrderAPI
+	TelemetryAPI ITelemetryAPI
+	EventAPI     IEventAPI
+	WebhookAPI   IWebhookAPI
+	AlertAPI     IAlertAPI
 }
 
-func StartServer(apis APIs) {
-	port := flag.String("port", "4000", "Port to listen on")
-	flag.Parse()
-
+func StartServer(apis APIs, config settings.Server) {
 	r := mux.NewRouter().PathPrefix("/api").Subrouter()
 
 	droneSubRouter := r.PathPrefix("/drone").Subrouter()
 	droneSubRouter.HandleFunc("/", apis.DroneAPI.RegisterDrone).Methods("POST")
+	droneSubRouter.HandleFunc("/{id:[0-9]+}", apis.DroneAPI.GetDrone).Methods("GET")
+	droneSubRouter.HandleFunc("/{id:[0-9]+}", apis.DroneAPI.UpdateDrone).Methods("PATCH")
+	droneSubRouter.HandleFunc("/{id:[0-9]+}", apis.DroneAPI.DecommissionDrone).Methods("DELETE")
 	droneSubRouter.HandleFunc("/{id}/load-medication", apis.DroneAPI.LoadingMedication).Methods("POST")
+	droneSubRouter.HandleFunc("/{id}/state", apis.DroneAPI.CheckLoadingMedication).Methods("GET")
 	droneSubRouter.HandleFunc("/{id}/check-battery", apis.DroneAPI.CheckDroneBattery).Methods("GET")
+	droneSubRouter.HandleFunc("/{id}/recalculate-payload", apis.DroneAPI.RecalculatePayload).Methods("POST")
+	droneSubRouter.HandleFunc("/{id}/medications/{code}", apis.DroneAPI.UnloadMedication).Methods("DELETE")
+	droneSubRouter.HandleFunc("/{id}/unload", apis.DroneAPI.UnloadDrone).Methods("POST")
+	droneSubRouter.HandleFunc("/{id}/transition", apis.DroneAPI.TransitionDrone).Methods("POST")
+	droneSubRouter.HandleFunc("/{id}/start-delivery", apis.DroneAPI.StartDelivery).Methods("POST")
+	droneSubRouter.HandleFunc("/{id}/mark-delivered", apis.DroneAPI.MarkDelivered).Methods("POST")
+	droneSubRouter.HandleFunc("/{id}/return", apis.DroneAPI.ReturnDrone).Methods("POST")
+	droneSubRouter.HandleFunc("/{id}/dock", apis.DockAPI.DockDrone).Methods("POST")
+	droneSubRouter.HandleFunc("/{id}/undock", apis.DockAPI.UndockDrone).Methods("POST")
+	droneSubRouter.HandleFunc("/{id}/position", apis.DroneAPI.UpdatePosition).Methods("PUT")
+	droneSubRouter.HandleFunc("/{id}/destination", apis.DroneAPI.SetDestination).Methods("PUT")
+	droneSubRouter.HandleFunc("/{id}/can-reach", apis.DroneAPI.CanReach).Methods("GET")
+	droneSubRouter.HandleFunc("/{id}/telemetry", apis.TelemetryAPI.RecordTelemetry).Methods("POST")
+	droneSubRouter.HandleFunc("/{id}/telemetry/batch", apis.TelemetryAPI.RecordTelemetryBatch).Methods("POST")
+	droneSubRouter.HandleFunc("/{id}/telemetry", apis.TelemetryAPI.ListTelemetry).Methods("GET")
 	droneSubRouter.HandleFunc("/available-drone", apis.DroneAPI.CheckAvailableDrones).Methods("GET")
 	droneSubRouter.HandleFunc("/log", apis.LogsAPI.List).Methods("GET")
-	start(*port, r)
+	r.HandleFunc("/drones", apis.DroneAPI.ListDrones).Methods("GET")
+	r.HandleFunc("/drones/nearby", apis.DroneAPI.ListDronesNearby).Methods("GET")
+	r.HandleFunc("/jobs/{id}", apis.DroneAPI.GetLoadingJob).Methods("GET")
+	r.HandleFunc("/docks", apis.DockAPI.RegisterDock).Methods("POST")
+	r.HandleFunc("/docks", apis.DockAPI.ListDocks).Methods("GET")
+	r.HandleFunc("/docks/{id:[0-9]+}", apis.DockAPI.GetDock).Methods("GET")
+	r.HandleFunc("/orders", apis.OrderAPI.PlaceOrder).Methods("POST")
+	r.HandleFunc("/orders/dispatch", apis.OrderAPI.Dispatch).Methods("POST")
+	r.HandleFunc("/orders/{id:[0-9]+}", apis.OrderAPI.GetOrder).Methods("GET")
+	r.HandleFunc("/events", apis.EventAPI.StreamEvents).Methods("GET")
+	r.HandleFunc("/events/ws", apis.EventAPI.WebSocketEvents).Methods("GET")
+	r.HandleFunc("/webhooks", apis.WebhookAPI.CreateWebhook).Methods("POST")
+	r.HandleFunc("/webhooks", apis.WebhookAPI.ListWebhooks).Methods("GET")
+	r.HandleFunc("/webhooks/{id:[0-9]+}", apis.WebhookAPI.GetWebhook).Methods("GET")
+	r.HandleFunc("/webhooks/{id:[0-9]+}", apis.WebhookAPI.DeleteWebhook).Methods("DELETE")
+	r.HandleFunc("/webhooks/{id:[0-9]+}/deliveries", apis.WebhookAPI.ListDeliveries).Methods("GET")
+	r.HandleFunc("/alerts", apis.AlertAPI.ListAlerts).Methods("GET")
+	r.HandleFunc("/alerts/{id:[0-9]+}", apis.AlertAPI.GetAlert).Methods("GET")
+	r.HandleFunc("/alerts/{id:[0-9]+}/acknowledge", apis.AlertAPI.AcknowledgeAlert).Methods("POST")
+	r.HandleFunc("/alerts/{id:[0-9]+}/resolve", apis.AlertAPI.ResolveAlert).Methods("POST")
+
+	medicationSubRouter := r.PathPrefix("/medication").Subrouter()
+	medicationSubRouter.HandleFunc("", apis.DroneAPI.RegisterMedication).Methods("POST")
+	medicationSubRouter.HandleFunc("", apis.DroneAPI.ListMedications).Methods("GET")
+	medicationSubRouter.HandleFunc("/{code}", apis.DroneAPI.GetMedication).Methods("GET")
+	medicationSubRouter.HandleFunc("/{code}", apis.DroneAPI.UpdateMedication).Methods("PUT")
+	medicationSubRouter.HandleFunc("/{code}", apis.DroneAPI.DeleteMedication).Methods("DELETE")
+	start(config.Port, r)
 }
 
 func start(port string, r http.Handler) {
@@ -38,3 +88,14 @@ func loggingHandler(h http.Handler) http.Handler {
 		h.ServeHTTP(w, r)
 	})
 }
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(data)
+}
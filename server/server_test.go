@@ -1,15 +1,21 @@
 package server
 
 import (
+	"bufio"
+	"drone/v2/clock"
 	"drone/v2/usecase"
 	mockUsecase "drone/v2/usecase/mocks"
+	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
 )
 
 func Test_droneAPI_RegisterDrone(t *testing.T) {
@@ -240,12 +246,25 @@ func Test_droneAPI_LoadingMedication(t *testing.T) {
 				medicationUsecase: mockUsecase.NewMedicationMockUsecase(),
 			},
 			args: args{
-				id:      "4",
+				id:      "1",
 				payload: strings.NewReader(`{}`),
 			},
-			wantStatus: http.StatusCreated,
+			wantStatus: http.StatusAccepted,
 			want:       "",
 		},
+		{
+			name: "Test can not load medication on drone that not exist",
+			fields: fields{
+				droneUsecase:      mockUsecase.NewDroneMockUsecase(),
+				medicationUsecase: mockUsecase.NewMedicationMockUsecase(),
+			},
+			args: args{
+				id:      "2",
+				payload: strings.NewReader(`{}`),
+			},
+			wantStatus: http.StatusNotFound,
+			want:       "drone is not exist\n",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -268,7 +287,7 @@ func Test_droneAPI_LoadingMedication(t *testing.T) {
 				t.Errorf("handler returned wrong status code: got %v want %v",
 					status, http.StatusCreated)
 			}
-			if tt.wantStatus == http.StatusBadRequest {
+			if tt.wantStatus == http.StatusBadRequest || tt.wantStatus == http.StatusNotFound {
 				got := response.Body.String()
 				if got != tt.want {
 					t.Errorf("got %q, want %q", got, tt.want)
@@ -319,3 +338,1413 @@ func Test_logsAPI_List(t *testing.T) {
 		})
 	}
 }
+
+func Test_droneAPI_TransitionDrone(t *testing.T) {
+	type args struct {
+		id      string
+		payload io.Reader
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantStatus int
+		want       string
+	}{
+		{
+			name: "Test can not transition drone without drone id",
+			args: args{
+				id:      "",
+				payload: strings.NewReader(`{"state":"LOADING"}`),
+			},
+			wantStatus: http.StatusBadRequest,
+			want:       "Couldn't find id in request URL\n",
+		},
+		{
+			name: "Test can not transition drone with drone id invaild",
+			args: args{
+				id:      "a",
+				payload: strings.NewReader(`{"state":"LOADING"}`),
+			},
+			wantStatus: http.StatusBadRequest,
+			want:       "Invaild drone id\n",
+		},
+		{
+			name: "Test can not transition drone with invaild payload",
+			args: args{
+				id:      "1",
+				payload: strings.NewReader(`{,}`),
+			},
+			wantStatus: http.StatusBadRequest,
+			want:       "Invaild json payload\n",
+		},
+		{
+			name: "Test transition drone successfully",
+			args: args{
+				id:      "1",
+				payload: strings.NewReader(`{"state":"LOADING"}`),
+			},
+			wantStatus: http.StatusOK,
+			want:       `{"drone_id":1,"state":"LOADING"}`,
+		},
+		{
+			name: "Test can not transition drone that not exist",
+			args: args{
+				id:      "2",
+				payload: strings.NewReader(`{"state":"LOADING"}`),
+			},
+			wantStatus: http.StatusNotFound,
+			want:       "drone is not exist\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &droneAPI{
+				droneUsecase: mockUsecase.NewDroneMockUsecase(),
+			}
+			request, _ := http.NewRequest(http.MethodPost, "/api/drone//transition", tt.args.payload)
+			if tt.args.id != "" {
+				request = mux.SetURLVars(request, map[string]string{
+					"id": tt.args.id,
+				})
+			}
+			response := httptest.NewRecorder()
+			api.TransitionDrone(response, request)
+			if status := response.Code; status != tt.wantStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					status, tt.wantStatus)
+			}
+			if got := response.Body.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_transitionErrorStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{
+			name: "test illegal transition is conflict",
+			err:  &usecase.IllegalTransitionError{DroneID: 1, From: usecase.StateIdle, To: usecase.StateDelivered},
+			want: http.StatusConflict,
+		},
+		{
+			name: "test concurrent update is conflict",
+			err:  usecase.ErrConcurrentUpdate,
+			want: http.StatusConflict,
+		},
+		{
+			name: "test drone out of range is conflict",
+			err:  &usecase.RangeError{DroneID: 1, Needed: 55.6, Level: 50},
+			want: http.StatusConflict,
+		},
+		{
+			name: "test grounded drone is conflict",
+			err:  usecase.ErrDroneGrounded,
+			want: http.StatusConflict,
+		},
+		{
+			name: "test loading in progress is conflict",
+			err:  usecase.ErrLoadingInProgress,
+			want: http.StatusConflict,
+		},
+		{
+			name: "test drone carrying medications is conflict",
+			err:  usecase.ErrDroneNotEmpty,
+			want: http.StatusConflict,
+		},
+		{
+			name: "test unknown drone is not found",
+			err:  usecase.ErrDroneNotFound,
+			want: http.StatusNotFound,
+		},
+		{
+			name: "test unknown state is bad request",
+			err:  &usecase.UnknownStateError{State: "Loading"},
+			want: http.StatusBadRequest,
+		},
+		{
+			name: "test other errors are bad request",
+			err:  errors.New("can not found drone for this id 1"),
+			want: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := transitionErrorStatus(tt.err); got != tt.want {
+				t.Errorf("transitionErrorStatus() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_droneAPI_GetMedication(t *testing.T) {
+	tests := []struct {
+		name       string
+		code       string
+		wantStatus int
+		want       string
+	}{
+		{
+			name:       "Test get medication by code",
+			code:       "code",
+			wantStatus: http.StatusOK,
+			want:       `{"name":"medication","code":"code","weight":10,"image":""}`,
+		},
+		{
+			name:       "Test get medication that not exist",
+			code:       "not-exist",
+			wantStatus: http.StatusNotFound,
+			want:       "medication is not exist\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &droneAPI{
+				droneUsecase:      mockUsecase.NewDroneMockUsecase(),
+				medicationUsecase: mockUsecase.NewMedicationMockUsecase(),
+			}
+			request, _ := http.NewRequest(http.MethodGet, "/api/medication/"+tt.code, nil)
+			request = mux.SetURLVars(request, map[string]string{
+				"code": tt.code,
+			})
+			response := httptest.NewRecorder()
+			api.GetMedication(response, request)
+			if status := response.Code; status != tt.wantStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					status, tt.wantStatus)
+			}
+			if got := response.Body.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_droneAPI_DeleteMedication(t *testing.T) {
+	tests := []struct {
+		name       string
+		code       string
+		wantStatus int
+	}{
+		{
+			name:       "Test delete medication by code",
+			code:       "code",
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "Test delete medication that not exist",
+			code:       "not-exist",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "Test can not delete medication of a pending order",
+			code:       "ordered",
+			wantStatus: http.StatusConflict,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &droneAPI{
+				droneUsecase:      mockUsecase.NewDroneMockUsecase(),
+				medicationUsecase: mockUsecase.NewMedicationMockUsecase(),
+			}
+			request, _ := http.NewRequest(http.MethodDelete, "/api/medication/"+tt.code, nil)
+			request = mux.SetURLVars(request, map[string]string{
+				"code": tt.code,
+			})
+			response := httptest.NewRecorder()
+			api.DeleteMedication(response, request)
+			if status := response.Code; status != tt.wantStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func Test_droneAPI_GetLoadingJob(t *testing.T) {
+	tests := []struct {
+		name       string
+		id         string
+		wantStatus int
+	}{
+		{
+			name:       "Test get loading job",
+			id:         "1",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Test get loading job that not exist",
+			id:         "2",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "Test get loading job with invaild id",
+			id:         "a",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &droneAPI{
+				droneUsecase: mockUsecase.NewDroneMockUsecase(),
+			}
+			request, _ := http.NewRequest(http.MethodGet, "/api/jobs/"+tt.id, nil)
+			request = mux.SetURLVars(request, map[string]string{
+				"id": tt.id,
+			})
+			response := httptest.NewRecorder()
+			api.GetLoadingJob(response, request)
+			if status := response.Code; status != tt.wantStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func Test_droneAPI_UnloadMedication(t *testing.T) {
+	tests := []struct {
+		name       string
+		id         string
+		code       string
+		wantStatus int
+	}{
+		{
+			name:       "Test unload medication from drone",
+			id:         "1",
+			code:       "code",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Test unload medication that is not on the drone",
+			id:         "1",
+			code:       "not-exist",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "Test unload medication with invalid drone id",
+			id:         "one",
+			code:       "code",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Test unload medication from drone that not exist",
+			id:         "2",
+			code:       "code",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &droneAPI{
+				droneUsecase: mockUsecase.NewDroneMockUsecase(),
+			}
+			request, _ := http.NewRequest(http.MethodDelete, "/api/drone/"+tt.id+"/medications/"+tt.code, nil)
+			request = mux.SetURLVars(request, map[string]string{
+				"id":   tt.id,
+				"code": tt.code,
+			})
+			response := httptest.NewRecorder()
+			api.UnloadMedication(response, request)
+			if status := response.Code; status != tt.wantStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func Test_droneAPI_UnloadDrone(t *testing.T) {
+	tests := []struct {
+		name       string
+		id         string
+		wantStatus int
+	}{
+		{
+			name:       "Test unload drone",
+			id:         "1",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Test unload drone that not exist",
+			id:         "2",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "Test unload drone with invalid id",
+			id:         "one",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &droneAPI{
+				droneUsecase: mockUsecase.NewDroneMockUsecase(),
+			}
+			request, _ := http.NewRequest(http.MethodPost, "/api/drone/"+tt.id+"/unload", nil)
+			request = mux.SetURLVars(request, map[string]string{
+				"id": tt.id,
+			})
+			response := httptest.NewRecorder()
+			api.UnloadDrone(response, request)
+			if status := response.Code; status != tt.wantStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func Test_droneAPI_RecalculatePayload(t *testing.T) {
+	tests := []struct {
+		name       string
+		id         string
+		wantStatus int
+	}{
+		{
+			name:       "Test recalculate payload",
+			id:         "1",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Test recalculate payload of drone that not exist",
+			id:         "2",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "Test recalculate payload with invalid id",
+			id:         "one",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &droneAPI{
+				droneUsecase: mockUsecase.NewDroneMockUsecase(),
+			}
+			request, _ := http.NewRequest(http.MethodPost, "/api/drone/"+tt.id+"/recalculate-payload", nil)
+			request = mux.SetURLVars(request, map[string]string{
+				"id": tt.id,
+			})
+			response := httptest.NewRecorder()
+			api.RecalculatePayload(response, request)
+			if status := response.Code; status != tt.wantStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func Test_unloadErrorStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{
+			name: "drone in wrong state",
+			err:  &usecase.UnloadStateError{DroneID: 1, State: usecase.StateDelivering},
+			want: http.StatusConflict,
+		},
+		{
+			name: "loading in progress",
+			err:  usecase.ErrLoadingInProgress,
+			want: http.StatusConflict,
+		},
+		{
+			name: "medication not on drone",
+			err:  usecase.ErrMedicationNotFound,
+			want: http.StatusNotFound,
+		},
+		{
+			name: "drone not exist",
+			err:  usecase.ErrDroneNotFound,
+			want: http.StatusNotFound,
+		},
+		{
+			name: "other error",
+			err:  errors.New("can not found drone"),
+			want: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := unloadErrorStatus(tt.err); got != tt.want {
+				t.Errorf("unloadErrorStatus() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_droneAPI_GetDrone(t *testing.T) {
+	tests := []struct {
+		name       string
+		id         string
+		wantStatus int
+	}{
+		{
+			name:       "Test get drone by id",
+			id:         "1",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Test get drone that not exist",
+			id:         "2",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "Test get drone with invalid id",
+			id:         "one",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &droneAPI{
+				droneUsecase: mockUsecase.NewDroneMockUsecase(),
+			}
+			request, _ := http.NewRequest(http.MethodGet, "/api/drone/"+tt.id, nil)
+			request = mux.SetURLVars(request, map[string]string{
+				"id": tt.id,
+			})
+			response := httptest.NewRecorder()
+			api.GetDrone(response, request)
+			if status := response.Code; status != tt.wantStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func Test_droneAPI_ListDrones(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+	}{
+		{
+			name:       "Test list drones",
+			query:      "?state=IDLE&page=1&page_size=10&sort=-battery",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Test list drones with invalid page",
+			query:      "?page=first",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Test list drones with invalid min battery",
+			query:      "?min_battery=low",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &droneAPI{
+				droneUsecase: mockUsecase.NewDroneMockUsecase(),
+			}
+			request, _ := http.NewRequest(http.MethodGet, "/api/drones"+tt.query, nil)
+			response := httptest.NewRecorder()
+			api.ListDrones(response, request)
+			if status := response.Code; status != tt.wantStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func Test_droneAPI_UpdateDrone(t *testing.T) {
+	tests := []struct {
+		name       string
+		id         string
+		body       string
+		wantStatus int
+	}{
+		{
+			name:       "Test update drone",
+			id:         "1",
+			body:       `{"model":"Heavyweight","weight":400}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Test update drone that not exist",
+			id:         "2",
+			body:       `{"weight":400}`,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "Test update drone with invalid json",
+			id:         "1",
+			body:       `{"weight":"heavy"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &droneAPI{
+				droneUsecase: mockUsecase.NewDroneMockUsecase(),
+			}
+			request, _ := http.NewRequest(http.MethodPatch, "/api/drone/"+tt.id, strings.NewReader(tt.body))
+			request = mux.SetURLVars(request, map[string]string{
+				"id": tt.id,
+			})
+			response := httptest.NewRecorder()
+			api.UpdateDrone(response, request)
+			if status := response.Code; status != tt.wantStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func Test_droneAPI_DecommissionDrone(t *testing.T) {
+	tests := []struct {
+		name       string
+		id         string
+		wantStatus int
+	}{
+		{
+			name:       "Test decommission drone",
+			id:         "1",
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "Test decommission busy drone",
+			id:         "2",
+			wantStatus: http.StatusConflict,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &droneAPI{
+				droneUsecase: mockUsecase.NewDroneMockUsecase(),
+			}
+			request, _ := http.NewRequest(http.MethodDelete, "/api/drone/"+tt.id, nil)
+			request = mux.SetURLVars(request, map[string]string{
+				"id": tt.id,
+			})
+			response := httptest.NewRecorder()
+			api.DecommissionDrone(response, request)
+			if status := response.Code; status != tt.wantStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func Test_dockAPI_RegisterDock(t *testing.T) {
+	tests := []struct {
+		name       string
+		payload    io.Reader
+		wantStatus int
+		want       string
+	}{
+		{
+			name:       "Test register dock",
+			payload:    strings.NewReader(`{"name":"base","latitude":30.04,"longitude":31.23,"slots":2,"charge_rate":2}`),
+			wantStatus: http.StatusCreated,
+			want:       `{"dock_id":1}`,
+		},
+		{
+			name:       "Test register dock with invalid json",
+			payload:    strings.NewReader(`{"name":`),
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &dockAPI{
+				dockUsecase: mockUsecase.NewDockMockUsecase(),
+			}
+			request, _ := http.NewRequest(http.MethodPost, "/api/docks", tt.payload)
+			response := httptest.NewRecorder()
+			api.RegisterDock(response, request)
+			if status := response.Code; status != tt.wantStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					status, tt.wantStatus)
+			}
+			if tt.want != "" && response.Body.String() != tt.want {
+				t.Errorf("handler returned unexpected body: got %v want %v",
+					response.Body.String(), tt.want)
+			}
+		})
+	}
+}
+
+func Test_dockAPI_GetDock(t *testing.T) {
+	tests := []struct {
+		name       string
+		id         string
+		wantStatus int
+	}{
+		{
+			name:       "Test get dock",
+			id:         "1",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Test get missing dock",
+			id:         "2",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &dockAPI{
+				dockUsecase: mockUsecase.NewDockMockUsecase(),
+			}
+			request, _ := http.NewRequest(http.MethodGet, "/api/docks/"+tt.id, nil)
+			request = mux.SetURLVars(request, map[string]string{
+				"id": tt.id,
+			})
+			response := httptest.NewRecorder()
+			api.GetDock(response, request)
+			if status := response.Code; status != tt.wantStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func Test_dockAPI_DockDrone(t *testing.T) {
+	tests := []struct {
+		name       string
+		id         string
+		payload    string
+		wantStatus int
+	}{
+		{
+			name:       "Test dock drone",
+			id:         "1",
+			payload:    `{"dock_id":1}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Test dock drone in missing dock",
+			id:         "1",
+			payload:    `{"dock_id":2}`,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "Test dock drone in full dock",
+			id:         "2",
+			payload:    `{"dock_id":1}`,
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "Test dock drone with invalid json",
+			id:         "1",
+			payload:    `{"dock_id":`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &dockAPI{
+				dockUsecase: mockUsecase.NewDockMockUsecase(),
+			}
+			request, _ := http.NewRequest(http.MethodPost, "/api/drone/"+tt.id+"/dock", strings.NewReader(tt.payload))
+			request = mux.SetURLVars(request, map[string]string{
+				"id": tt.id,
+			})
+			response := httptest.NewRecorder()
+			api.DockDrone(response, request)
+			if status := response.Code; status != tt.wantStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func Test_dockErrorStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "missing dock", err: usecase.ErrDockNotFound, want: http.StatusNotFound},
+		{name: "missing drone", err: usecase.ErrDroneNotFound, want: http.StatusNotFound},
+		{name: "flying drone", err: &usecase.DockStateError{DroneID: 1, State: usecase.StateDelivering}, want: http.StatusConflict},
+		{name: "drone not docked", err: usecase.ErrDroneNotDocked, want: http.StatusConflict},
+		{name: "other error", err: errors.New("other"), want: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dockErrorStatus(tt.err); got != tt.want {
+				t.Errorf("dockErrorStatus() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_orderAPI_PlaceOrder(t *testing.T) {
+	tests := []struct {
+		name       string
+		payload    io.Reader
+		wantStatus int
+	}{
+		{
+			name:       "Test place order",
+			payload:    strings.NewReader(`{"destination":"hospital","priority":1,"items":[{"code":"code","quantity":2}]}`),
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "Test place order without items",
+			payload:    strings.NewReader(`{"destination":"hospital"}`),
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Test place order failed while loading",
+			payload:    strings.NewReader(`{"destination":"hospital","items":[{"code":"other"}]}`),
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "Test place order with invalid json",
+			payload:    strings.NewReader(`{"destination":`),
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &orderAPI{
+				orderUsecase: mockUsecase.NewOrderMockUsecase(),
+			}
+			request, _ := http.NewRequest(http.MethodPost, "/api/orders", tt.payload)
+			response := httptest.NewRecorder()
+			api.PlaceOrder(response, request)
+			if status := response.Code; status != tt.wantStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func Test_orderAPI_GetOrder(t *testing.T) {
+	tests := []struct {
+		name       string
+		id         string
+		wantStatus int
+	}{
+		{
+			name:       "Test get order",
+			id:         "1",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Test get missing order",
+			id:         "2",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &orderAPI{
+				orderUsecase: mockUsecase.NewOrderMockUsecase(),
+			}
+			request, _ := http.NewRequest(http.MethodGet, "/api/orders/"+tt.id, nil)
+			request = mux.SetURLVars(request, map[string]string{
+				"id": tt.id,
+			})
+			response := httptest.NewRecorder()
+			api.GetOrder(response, request)
+			if status := response.Code; status != tt.wantStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func Test_orderAPI_Dispatch(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantDryRun bool
+	}{
+		{
+			name:       "Test dispatch orders",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Test dispatch plan",
+			query:      "?dry_run=true",
+			wantStatus: http.StatusOK,
+			wantDryRun: true,
+		},
+		{
+			name:       "Test dispatch with invalid dry run",
+			query:      "?dry_run=maybe",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &orderAPI{
+				orderUsecase: mockUsecase.NewOrderMockUsecase(),
+			}
+			request, _ := http.NewRequest(http.MethodPost, "/api/orders/dispatch"+tt.query, nil)
+			response := httptest.NewRecorder()
+			api.Dispatch(response, request)
+			if status := response.Code; status != tt.wantStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					status, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusOK && strings.Contains(response.Body.String(), `"dry_run":true`) != tt.wantDryRun {
+				t.Errorf("handler returned unexpected body: got %v, want dry run %v", response.Body.String(), tt.wantDryRun)
+			}
+		})
+	}
+}
+
+func Test_droneAPI_UpdatePosition(t *testing.T) {
+	tests := []struct {
+		name       string
+		id         string
+		body       string
+		wantStatus int
+	}{
+		{
+			name:       "Test update drone position",
+			id:         "1",
+			body:       `{"latitude":30.04,"longitude":31.23}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Test update position of drone that not exist",
+			id:         "2",
+			body:       `{"latitude":30.04,"longitude":31.23}`,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "Test update drone position with invalid json",
+			id:         "1",
+			body:       `{"latitude":"north"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &droneAPI{
+				droneUsecase: mockUsecase.NewDroneMockUsecase(),
+			}
+			request, _ := http.NewRequest(http.MethodPut, "/api/drone/"+tt.id+"/position", strings.NewReader(tt.body))
+			request = mux.SetURLVars(request, map[string]string{
+				"id": tt.id,
+			})
+			response := httptest.NewRecorder()
+			api.UpdatePosition(response, request)
+			if status := response.Code; status != tt.wantStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func Test_droneAPI_SetDestination(t *testing.T) {
+	tests := []struct {
+		name       string
+		id         string
+		body       string
+		wantStatus int
+	}{
+		{
+			name:       "Test set drone destination",
+			id:         "1",
+			body:       `{"latitude":30.1,"longitude":31.3}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Test set destination of flying drone",
+			id:         "2",
+			body:       `{"latitude":30.1,"longitude":31.3}`,
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "Test set drone destination without payload",
+			id:         "1",
+			body:       ``,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &droneAPI{
+				droneUsecase: mockUsecase.NewDroneMockUsecase(),
+			}
+			request, _ := http.NewRequest(http.MethodPut, "/api/drone/"+tt.id+"/destination", strings.NewReader(tt.body))
+			request = mux.SetURLVars(request, map[string]string{
+				"id": tt.id,
+			})
+			response := httptest.NewRecorder()
+			api.SetDestination(response, request)
+			if status := response.Code; status != tt.wantStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func Test_droneAPI_ListDronesNearby(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+	}{
+		{
+			name:       "Test list drones nearby",
+			query:      "?lat=30.04&lng=31.23&radius=5",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Test list drones nearby without point",
+			query:      "?radius=5",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Test list drones nearby with invalid radius",
+			query:      "?lat=30.04&lng=31.23&radius=far",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Test list drones nearby without radius",
+			query:      "?lat=30.04&lng=31.23",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &droneAPI{
+				droneUsecase: mockUsecase.NewDroneMockUsecase(),
+			}
+			request, _ := http.NewRequest(http.MethodGet, "/api/drones/nearby"+tt.query, nil)
+			response := httptest.NewRecorder()
+			api.ListDronesNearby(response, request)
+			if status := response.Code; status != tt.wantStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func Test_droneAPI_CanReach(t *testing.T) {
+	tests := []struct {
+		name       string
+		id         string
+		query      string
+		wantStatus int
+	}{
+		{
+			name:       "Test drone can reach location",
+			id:         "1",
+			query:      "?lat=30.1&lng=31.3",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Test can reach of drone that not exist",
+			id:         "2",
+			query:      "?lat=30.1&lng=31.3",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "Test can reach without location",
+			id:         "1",
+			query:      "?lat=30.1",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Test can reach with invalid location",
+			id:         "1",
+			query:      "?lat=north&lng=31.3",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &droneAPI{
+				droneUsecase: mockUsecase.NewDroneMockUsecase(),
+			}
+			request, _ := http.NewRequest(http.MethodGet, "/api/drone/"+tt.id+"/can-reach"+tt.query, nil)
+			request = mux.SetURLVars(request, map[string]string{
+				"id": tt.id,
+			})
+			response := httptest.NewRecorder()
+			api.CanReach(response, request)
+			if status := response.Code; status != tt.wantStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func Test_telemetryAPI_RecordTelemetry(t *testing.T) {
+	tests := []struct {
+		name       string
+		id         string
+		batch      bool
+		body       string
+		wantStatus int
+	}{
+		{
+			name:       "Test record drone telemetry",
+			id:         "1",
+			body:       `{"recorded_at":"2026-10-18T12:00:00Z","battery_level":90,"latitude":30.04,"longitude":31.23,"altitude":80,"speed":40,"state":"DELIVERING"}`,
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "Test record telemetry of drone that not exist",
+			id:         "2",
+			body:       `{"recorded_at":"2026-10-18T12:00:00Z","battery_level":90}`,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "Test record telemetry without time",
+			id:         "1",
+			body:       `{"battery_level":90}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Test record telemetry with invalid time",
+			id:         "1",
+			body:       `{"recorded_at":"yesterday"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Test record telemetry batch",
+			id:         "1",
+			batch:      true,
+			body:       `[{"recorded_at":"2026-10-18T12:00:00Z","battery_level":91},{"recorded_at":"2026-10-18T12:00:10Z","battery_level":90}]`,
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "Test record empty telemetry batch",
+			id:         "1",
+			batch:      true,
+			body:       `[]`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Test record telemetry batch that is not a list",
+			id:         "1",
+			batch:      true,
+			body:       `{"recorded_at":"2026-10-18T12:00:00Z","battery_level":90}`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &telemetryAPI{
+				telemetryUsecase: mockUsecase.NewTelemetryMockUsecase(),
+			}
+			path, handler := "/telemetry", api.RecordTelemetry
+			if tt.batch {
+				path, handler = "/telemetry/batch", api.RecordTelemetryBatch
+			}
+			request, _ := http.NewRequest(http.MethodPost, "/api/drone/"+tt.id+path, strings.NewReader(tt.body))
+			request = mux.SetURLVars(request, map[string]string{
+				"id": tt.id,
+			})
+			response := httptest.NewRecorder()
+			handler(response, request)
+			if status := response.Code; status != tt.wantStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func Test_telemetryAPI_ListTelemetry(t *testing.T) {
+	tests := []struct {
+		name       string
+		id         string
+		query      string
+		wantStatus int
+	}{
+		{
+			name:       "Test list drone telemetry",
+			id:         "1",
+			query:      "?from=2026-10-18T12:00:00Z&to=2026-10-18T13:00:00%2B02:00&limit=10",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Test list telemetry of drone that not exist",
+			id:         "2",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "Test list telemetry with invalid time",
+			id:         "1",
+			query:      "?from=today",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Test list telemetry with invalid limit",
+			id:         "1",
+			query:      "?limit=ten",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &telemetryAPI{
+				telemetryUsecase: mockUsecase.NewTelemetryMockUsecase(),
+			}
+			request, _ := http.NewRequest(http.MethodGet, "/api/drone/"+tt.id+"/telemetry"+tt.query, nil)
+			request = mux.SetURLVars(request, map[string]string{
+				"id": tt.id,
+			})
+			response := httptest.NewRecorder()
+			api.ListTelemetry(response, request)
+			if status := response.Code; status != tt.wantStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func newEventServer(t *testing.T) (usecase.IEventBus, *httptest.Server) {
+	t.Helper()
+	events := usecase.NewEventBus(clock.NewFake(time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)))
+	api := NewEventAPI(events)
+	r := mux.NewRouter()
+	r.HandleFunc("/api/events", api.StreamEvents).Methods("GET")
+	r.HandleFunc("/api/events/ws", api.WebSocketEvents).Methods("GET")
+	s := httptest.NewServer(r)
+	t.Cleanup(s.Close)
+	return events, s
+}
+
+func Test_eventAPI_StreamEvents(t *testing.T) {
+	events, s := newEventServer(t)
+	events.Publish(usecase.EventStateChanged, 1, usecase.StateChangedEvent{From: usecase.StateIdle, To: usecase.StateLoading})
+	events.Publish(usecase.EventStateChanged, 2, usecase.StateChangedEvent{From: usecase.StateIdle, To: usecase.StateLoading})
+	events.Publish(usecase.EventBatteryTick, 1, usecase.BatteryEvent{Level: 90})
+	events.Publish(usecase.EventStateChanged, 1, usecase.StateChangedEvent{From: usecase.StateLoading, To: usecase.StateLoaded})
+
+	request, _ := http.NewRequest(http.MethodGet, s.URL+"/api/events?drone_id=1&type=state-changed,battery-low", nil)
+	request.Header.Set("Last-Event-ID", "1")
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("Can't stream events: %v", err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK || response.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("handler returned %v %v, want an event stream", response.StatusCode, response.Header.Get("Content-Type"))
+	}
+	events.Publish(usecase.EventBatteryLow, 2, usecase.BatteryEvent{Level: 20})
+	events.Publish(usecase.EventBatteryLow, 1, usecase.BatteryEvent{Level: 20})
+
+	reader := bufio.NewReader(response.Body)
+	want := []string{
+		"id: 4", "event: state-changed", `data: {"id":4,"type":"state-changed","drone_id":1,"at":"2026-10-18T12:00:00Z","data":{"from":"LOADING","to":"LOADED"}}`, "",
+		"id: 6", "event: battery-low", `data: {"id":6,"type":"battery-low","drone_id":1,"at":"2026-10-18T12:00:00Z","data":{"level":20}}`, "",
+	}
+	for _, wantLine := range want {
+		line, err := reader.ReadString('\n')
+		if err != nil || strings.TrimSuffix(line, "\n") != wantLine {
+			t.Fatalf("stream line = %q, %v, want %q", line, err, wantLine)
+		}
+	}
+}
+
+func Test_eventAPI_StreamEvents_InvalidQuery(t *testing.T) {
+	for _, query := range []string{"?drone_id=one", "?type=drone-crashed", "?last_event_id=last"} {
+		api := NewEventAPI(usecase.NewEventBus(clock.System))
+		request, _ := http.NewRequest(http.MethodGet, "/api/events"+query, nil)
+		response := httptest.NewRecorder()
+		api.StreamEvents(response, request)
+		if status := response.Code; status != http.StatusBadRequest {
+			t.Errorf("handler returned wrong status code for %s: got %v want %v", query, status, http.StatusBadRequest)
+		}
+	}
+}
+
+func Test_eventAPI_WebSocketEvents(t *testing.T) {
+	events, s := newEventServer(t)
+	events.Publish(usecase.EventBatteryTick, 1, usecase.BatteryEvent{Level: 90})
+	events.Publish(usecase.EventDroneRegistered, 1, usecase.DroneRegisteredEvent{SerialNumber: "serial 1", Model: "Lightweight"})
+
+	url := "ws" + strings.TrimPrefix(s.URL, "http") + "/api/events/ws?type=drone-registered&last_event_id=1"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Can't open the websocket: %v", err)
+	}
+	defer conn.Close()
+	events.Publish(usecase.EventBatteryTick, 1, usecase.BatteryEvent{Level: 90})
+	events.Publish(usecase.EventDroneRegistered, 2, usecase.DroneRegisteredEvent{SerialNumber: "serial 2", Model: "Heavyweight"})
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for _, want := range []struct {
+		id      int64
+		droneID int
+	}{{id: 2, droneID: 1}, {id: 4, droneID: 2}} {
+		var event usecase.Event
+		if err := conn.ReadJSON(&event); err != nil {
+			t.Fatalf("Can't read the event: %v", err)
+		}
+		data, _ := json.Marshal(event.Data)
+		if event.ID != want.id || event.Type != usecase.EventDroneRegistered || event.DroneID != want.droneID || !strings.Contains(string(data), "serial") {
+			t.Errorf("event = %+v, want drone %d registered with id %d", event, want.droneID, want.id)
+		}
+	}
+}
+
+func Test_webhookAPI_CreateWebhook(t *testing.T) {
+	tests := []struct {
+		name       string
+		payload    string
+		wantStatus int
+	}{
+		{
+			name:       "Test create webhook",
+			payload:    `{"url": "https://erp.example.com/hooks", "events": ["drone-loaded", "drone-delivered"]}`,
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "Test create webhook without url",
+			payload:    `{"events": ["drone-loaded"]}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Test create webhook with invalid json",
+			payload:    `{"url": `,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &webhookAPI{
+				webhookUsecase: mockUsecase.NewWebhookMockUsecase(),
+			}
+			request, _ := http.NewRequest(http.MethodPost, "/api/webhooks", strings.NewReader(tt.payload))
+			response := httptest.NewRecorder()
+			api.CreateWebhook(response, request)
+			if status := response.Code; status != tt.wantStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					status, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusCreated && !strings.Contains(response.Body.String(), `"secret":"0123456789abcdef"`) {
+				t.Errorf("handler returned %s, want the webhook with its secret", response.Body.String())
+			}
+		})
+	}
+}
+
+func Test_webhookAPI_Webhook(t *testing.T) {
+	api := &webhookAPI{
+		webhookUsecase: mockUsecase.NewWebhookMockUsecase(),
+	}
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		id         string
+		handler    http.HandlerFunc
+		wantStatus int
+	}{
+		{name: "Test get webhook", method: http.MethodGet, id: "1", handler: api.GetWebhook, wantStatus: http.StatusOK},
+		{name: "Test get missing webhook", method: http.MethodGet, id: "2", handler: api.GetWebhook, wantStatus: http.StatusNotFound},
+		{name: "Test list webhook deliveries", method: http.MethodGet, path: "/deliveries", id: "1", handler: api.ListDeliveries, wantStatus: http.StatusOK},
+		{name: "Test list deliveries of missing webhook", method: http.MethodGet, path: "/deliveries", id: "2", handler: api.ListDeliveries, wantStatus: http.StatusNotFound},
+		{name: "Test delete webhook", method: http.MethodDelete, id: "1", handler: api.DeleteWebhook, wantStatus: http.StatusNoContent},
+		{name: "Test delete missing webhook", method: http.MethodDelete, id: "2", handler: api.DeleteWebhook, wantStatus: http.StatusNotFound},
+		{name: "Test delete webhook with invalid id", method: http.MethodDelete, id: "one", handler: api.DeleteWebhook, wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, _ := http.NewRequest(tt.method, "/api/webhooks/"+tt.id+tt.path, nil)
+			request = mux.SetURLVars(request, map[string]string{
+				"id": tt.id,
+			})
+			response := httptest.NewRecorder()
+			tt.handler(response, request)
+			if status := response.Code; status != tt.wantStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func Test_alertAPI_ListAlerts(t *testing.T) {
+	api := &alertAPI{
+		alertUsecase: mockUsecase.NewAlertMockUsecase(),
+	}
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+	}{
+		{name: "Test list alerts", wantStatus: http.StatusOK},
+		{name: "Test list open alerts of a drone", query: "?drone_id=1&status=OPEN", wantStatus: http.StatusOK},
+		{name: "Test list alerts with invalid drone id", query: "?drone_id=one", wantStatus: http.StatusBadRequest},
+		{name: "Test list alerts with unknown status", query: "?status=CLOSED", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, _ := http.NewRequest(http.MethodGet, "/api/alerts"+tt.query, nil)
+			response := httptest.NewRecorder()
+			api.ListAlerts(response, request)
+			if status := response.Code; status != tt.wantStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func Test_alertAPI_Alert(t *testing.T) {
+	api := &alertAPI{
+		alertUsecase: mockUsecase.NewAlertMockUsecase(),
+	}
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		id         string
+		handler    http.HandlerFunc
+		wantStatus int
+	}{
+		{name: "Test get alert", method: http.MethodGet, id: "1", handler: api.GetAlert, wantStatus: http.StatusOK},
+		{name: "Test get missing alert", method: http.MethodGet, id: "3", handler: api.GetAlert, wantStatus: http.StatusNotFound},
+		{name: "Test acknowledge alert", method: http.MethodPost, path: "/acknowledge", id: "1", handler: api.AcknowledgeAlert, wantStatus: http.StatusOK},
+		{name: "Test acknowledge resolved alert", method: http.MethodPost, path: "/acknowledge", id: "2", handler: api.AcknowledgeAlert, wantStatus: http.StatusConflict},
+		{name: "Test resolve alert", method: http.MethodPost, path: "/resolve", id: "1", handler: api.ResolveAlert, wantStatus: http.StatusOK},
+		{name: "Test resolve resolved alert", method: http.MethodPost, path: "/resolve", id: "2", handler: api.ResolveAlert, wantStatus: http.StatusConflict},
+		{name: "Test resolve missing alert", method: http.MethodPost, path: "/resolve", id: "3", handler: api.ResolveAlert, wantStatus: http.StatusNotFound},
+		{name: "Test resolve alert with invalid id", method: http.MethodPost, path: "/resolve", id: "one", handler: api.ResolveAlert, wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, _ := http.NewRequest(tt.method, "/api/alerts/"+tt.id+tt.path, nil)
+			request = mux.SetURLVars(request, map[string]string{
+				"id": tt.id,
+			})
+			response := httptest.NewRecorder()
+			tt.handler(response, request)
+			if status := response.Code; status != tt.wantStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					status, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package server
+
+import (
+	"drone/v2/usecase"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type ITelemetryAPI interface {
+	RecordTelemetry(w http.ResponseWriter, r *http.Request)
+	RecordTelemetryBatch(w http.ResponseWriter, r *http.Request)
+	ListTelemetry(w http.ResponseWriter, r *http.Request)
+}
+
+type telemetryAPI struct {
+	telemetryUsecase usecase.ITelemetryUsecase
+}
+
+func NewTelemetryAPI(telemetryUsecase usecase.ITelemetryUsecase) ITelemetryAPI {
+	return &telemetryAPI{
+		telemetryUsecase: telemetryUsecase,
+	}
+}
+
+// RecordTelemetry stores one reading of the drone and returns the drone updated from it.
+func (api *telemetryAPI) RecordTelemetry(w http.ResponseWriter, r *http.Request) {
+	var reading TelemetryPayload
+	api.record(w, r, &reading, func() []usecase.TelemetryObject {
+		return []usecase.TelemetryObject{usecase.TelemetryObject(reading)}
+	})
+}
+
+// RecordTelemetryBatch stores a json array of readings of the drone, the drone is updated from the newest.
+func (api *telemetryAPI) RecordTelemetryBatch(w http.ResponseWriter, r *http.Request) {
+	var readings []TelemetryPayload
+	api.record(w, r, &readings, func() []usecase.TelemetryObject {
+		objects := make([]usecase.TelemetryObject, 0, len(readings))
+		for _, reading := range readings {
+			objects = append(objects, usecase.TelemetryObject(reading))
+		}
+		return objects
+	})
+}
+
+func (api *telemetryAPI) record(w http.ResponseWriter, r *http.Request, payload any, readings func() []usecase.TelemetryObject) {
+	id, err := droneIDFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if r.Body == nil {
+		http.Error(w, "telemetry end point must have json payload", http.StatusBadRequest)
+		return
+	}
+	if err := json.NewDecoder(r.Body).Decode(payload); err != nil {
+		http.Error(w, "Invaild json payload", http.StatusBadRequest)
+		return
+	}
+	drone, err := api.telemetryUsecase.RecordTelemetry(id, readings())
+	if err != nil {
+		http.Error(w, err.Error(), droneErrorStatus(err))
+		return
+	}
+	writeJSON(w, http.StatusCreated, drone)
+}
+
+// ListTelemetry lists the readings of the drone recorded from the from time until before the to time,
+// both are RFC 3339 times.
+func (api *telemetryAPI) ListTelemetry(w http.ResponseWriter, r *http.Request) {
+	id, err := droneIDFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var query usecase.TelemetryQuery
+	values := r.URL.Query()
+	for key, target := range map[string]*time.Time{
+		"from": &query.From,
+		"to":   &query.To,
+	} {
+		if value := values.Get(key); value != "" {
+			if *target, err = time.Parse(time.RFC3339, value); err != nil {
+				http.Error(w, fmt.Sprintf("Invaild %s", key), http.StatusBadRequest)
+				return
+			}
+		}
+	}
+	if value := values.Get("limit"); value != "" {
+		if query.Limit, err = strconv.Atoi(value); err != nil {
+			http.Error(w, "Invaild limit", http.StatusBadRequest)
+			return
+		}
+	}
+	readings, err := api.telemetryUsecase.ListTelemetry(id, query)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, usecase.ErrDroneNotFound) {
+			status = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	writeJSON(w, http.StatusOK, readings)
+}
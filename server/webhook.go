@@ -0,0 +1,114 @@
+package server
+
+import (
+	"drone/v2/usecase"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type IWebhookAPI interface {
+	CreateWebhook(w http.ResponseWriter, r *http.Request)
+	ListWebhooks(w http.ResponseWriter, r *http.Request)
+	GetWebhook(w http.ResponseWriter, r *http.Request)
+	DeleteWebhook(w http.ResponseWriter, r *http.Request)
+	ListDeliveries(w http.ResponseWriter, r *http.Request)
+}
+
+type webhookAPI struct {
+	webhookUsecase usecase.IWebhookUsecase
+}
+
+func NewWebhookAPI(webhookUsecase usecase.IWebhookUsecase) IWebhookAPI {
+	return &webhookAPI{
+		webhookUsecase: webhookUsecase,
+	}
+}
+
+// CreateWebhook subscribes the url to the events, the response has the secret the deliveries are signed with.
+func (api *webhookAPI) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var webhook WebhookSubscriptionPayload
+	if r.Body == nil {
+		http.Error(w, "create webhook must have json payload", http.StatusBadRequest)
+		return
+	}
+	if err := json.NewDecoder(r.Body).Decode(&webhook); err != nil {
+		http.Error(w, "Invaild json payload", http.StatusBadRequest)
+		return
+	}
+	created, err := api.webhookUsecase.CreateWebhook(usecase.WebhookObject(webhook))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, http.StatusCreated, created)
+}
+
+func (api *webhookAPI) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	webhooks, err := api.webhookUsecase.ListWebhooks()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, webhooks)
+}
+
+func (api *webhookAPI) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	id, err := webhookIDFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	webhook, err := api.webhookUsecase.GetWebhook(id)
+	if err != nil {
+		http.Error(w, err.Error(), webhookErrorStatus(err))
+		return
+	}
+	writeJSON(w, http.StatusOK, webhook)
+}
+
+func (api *webhookAPI) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id, err := webhookIDFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := api.webhookUsecase.DeleteWebhook(id); err != nil {
+		http.Error(w, err.Error(), webhookErrorStatus(err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListDeliveries lists the deliveries of the webhook with every attempt, the newest first.
+func (api *webhookAPI) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	id, err := webhookIDFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	deliveries, err := api.webhookUsecase.ListDeliveries(id)
+	if err != nil {
+		http.Error(w, err.Error(), webhookErrorStatus(err))
+		return
+	}
+	writeJSON(w, http.StatusOK, deliveries)
+}
+
+func webhookIDFromRequest(r *http.Request) (int, error) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		return 0, errors.New("Invaild webhook id")
+	}
+	return id, nil
+}
+
+func webhookErrorStatus(err error) int {
+	if errors.Is(err, usecase.ErrWebhookNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
@@ -1 +1,441 @@
-package constants
+package settings
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+	// DriverMemory keeps the data in memory only, it is lost when the app stops
+	DriverMemory = "memory"
+)
+
+// droneStates are the states alert rules can be limited to.
+var droneStates = map[string]bool{"IDLE": true, "LOADING": true, "LOADED": true, "DELIVERING": true, "DELIVERED": true, "RETURNING": true}
+
+// CriticalBatteryRule names the alert fired when a drone is grounded under the critical battery level,
+// alert rules can not take the name.
+const CriticalBatteryRule = "critical-battery"
+
+// mqttSchemes are the url schemes of the brokers the bridge can connect to.
+var mqttSchemes = map[string]bool{"tcp": true, "ssl": true, "ws": true, "wss": true}
+
+// ConfigFileEnv names the environment variable holding the path of the optional config file.
+const ConfigFileEnv = "DRONE_CONFIG_FILE"
+
+type Config struct {
+	Database  Database  `json:"database" yaml:"database"`
+	Server    Server    `json:"server" yaml:"server"`
+	Cron      Cron      `json:"cron" yaml:"cron"`
+	Battery   Battery   `json:"battery" yaml:"battery"`
+	Telemetry Telemetry `json:"telemetry" yaml:"telemetry"`
+	MQTT      MQTT      `json:"mqtt" yaml:"mqtt"`
+	Webhooks  Webhooks  `json:"webhooks" yaml:"webhooks"`
+	Alerts    Alerts    `json:"alerts" yaml:"alerts"`
+}
+
+type Database struct {
+	// Driver is the storage backend, postgres, sqlite or memory
+	Driver string `json:"driver" yaml:"driver"`
+	// Schema is the postgres schema holding the tables
+	Schema string `json:"schema" yaml:"schema"`
+	// Path is the sqlite database file, ":memory:" keeps the database in memory
+	Path            string   `json:"path" yaml:"path"`
+	Host            string   `json:"host" yaml:"host"`
+	Port            int      `json:"port" yaml:"port"`
+	User            string   `json:"user" yaml:"user"`
+	Password        string   `json:"password" yaml:"password"`
+	Name            string   `json:"name" yaml:"name"`
+	SSLMode         string   `json:"ssl_mode" yaml:"ssl_mode"`
+	TimeZone        string   `json:"time_zone" yaml:"time_zone"`
+	MaxOpenConns    int      `json:"max_open_conns" yaml:"max_open_conns"`
+	MaxIdleConns    int      `json:"max_idle_conns" yaml:"max_idle_conns"`
+	ConnMaxLifetime Duration `json:"conn_max_lifetime" yaml:"conn_max_lifetime"`
+}
+
+type Server struct {
+	Port string `json:"port" yaml:"port"`
+}
+
+type Cron struct {
+	// BatteryInterval is how often the drones batteries are checked
+	BatteryInterval Duration `json:"battery_interval" yaml:"battery_interval"`
+	// ChargeInterval is how often the docked drones are charged
+	ChargeInterval Duration `json:"charge_interval" yaml:"charge_interval"`
+	// DispatchInterval is how often the pending orders are dispatched to the fleet
+	DispatchInterval Duration `json:"dispatch_interval" yaml:"dispatch_interval"`
+	// FlightInterval is how often the drones out of the base are moved along their flight
+	FlightInterval Duration `json:"flight_interval" yaml:"flight_interval"`
+	// TelemetryInterval is how often the old telemetry readings are downsampled and the expired ones deleted
+	TelemetryInterval Duration `json:"telemetry_interval" yaml:"telemetry_interval"`
+	// WebhookInterval is how often the failed webhook deliveries due for a retry are attempted again
+	WebhookInterval Duration `json:"webhook_interval" yaml:"webhook_interval"`
+}
+
+type Battery struct {
+	// MinLoadingLevel is the lowest battery level a drone can be loaded with
+	MinLoadingLevel int `json:"min_loading_level" yaml:"min_loading_level"`
+	// RechargeRate is the battery percentage drones gain per minute in docks registered without a charge rate
+	RechargeRate float64 `json:"recharge_rate" yaml:"recharge_rate"`
+	// SafetyMargin is the battery percentage a drone must have left after the round trip of a delivery
+	SafetyMargin float64 `json:"safety_margin" yaml:"safety_margin"`
+	// CriticalLevel is the battery level drones are grounded under until their alert is resolved, 0 never grounds them
+	CriticalLevel int `json:"critical_level" yaml:"critical_level"`
+}
+
+type Telemetry struct {
+	// Retention is how long the telemetry readings are kept
+	Retention Duration `json:"retention" yaml:"retention"`
+	// DownsampleAfter is the age the readings are merged into one reading per DownsampleBucket at
+	DownsampleAfter  Duration `json:"downsample_after" yaml:"downsample_after"`
+	DownsampleBucket Duration `json:"downsample_bucket" yaml:"downsample_bucket"`
+}
+
+// MQTT is the broker the drones talk to, the bridge to it only runs when Broker is set.
+type MQTT struct {
+	// Broker is the url of the broker like tcp://localhost:1883
+	Broker   string `json:"broker" yaml:"broker"`
+	ClientID string `json:"client_id" yaml:"client_id"`
+	Username string `json:"username" yaml:"username"`
+	Password string `json:"password" yaml:"password"`
+	// QoS is the quality of service the telemetry is subscribed and the commands are published with
+	QoS int `json:"qos" yaml:"qos"`
+}
+
+type Webhooks struct {
+	// Timeout is how long a receiver has to answer a delivery
+	Timeout Duration `json:"timeout" yaml:"timeout"`
+	// MaxAttempts is how many times a delivery is attempted before it is given up
+	MaxAttempts int `json:"max_attempts" yaml:"max_attempts"`
+	// RetryBackoff is the wait after the first failed attempt, it doubles after every failed attempt
+	RetryBackoff Duration `json:"retry_backoff" yaml:"retry_backoff"`
+}
+
+// Alerts are the rules evaluated for every drone whose battery level changed on a battery check.
+type Alerts struct {
+	Rules []AlertRule `json:"rules" yaml:"rules"`
+}
+
+// AlertRule fires for a drone when all the conditions it sets hold.
+type AlertRule struct {
+	Name string `json:"name" yaml:"name"`
+	// BelowLevel fires under the battery level, 0 does not check the level
+	BelowLevel int `json:"below_level" yaml:"below_level"`
+	// States limits the rule to drones in the states, drones in every state are checked when it is empty
+	States []string `json:"states" yaml:"states"`
+	// MaxDrain fires when the battery drops faster than the percentage per minute, 0 does not check the drain
+	MaxDrain float64 `json:"max_drain" yaml:"max_drain"`
+}
+
+// Duration is a time.Duration written as a string like "1m30s" in config files.
+type Duration struct {
+	time.Duration
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	duration, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	d.Duration = duration
+	return nil
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// DSN is the postgres connection string of the database.
+func (d Database) DSN() string {
+	return fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=%s TimeZone=%s",
+		d.Host, d.User, d.Password, d.Name, d.Port, d.SSLMode, d.TimeZone)
+}
+
+func Default() Config {
+	return Config{
+		Database: Database{
+			Driver:          DriverPostgres,
+			Schema:          "drone",
+			Path:            "drone.db",
+			Host:            "localhost",
+			Port:            5432,
+			User:            "postgres",
+			Password:        "postgres",
+			Name:            "drone",
+			SSLMode:         "disable",
+			TimeZone:        "Africa/Cairo",
+			MaxOpenConns:    10,
+			MaxIdleConns:    5,
+			ConnMaxLifetime: Duration{time.Hour},
+		},
+		Server: Server{
+			Port: "4000",
+		},
+		Cron: Cron{
+			BatteryInterval:   Duration{time.Minute},
+			ChargeInterval:    Duration{time.Minute},
+			DispatchInterval:  Duration{time.Minute},
+			FlightInterval:    Duration{10 * time.Second},
+			TelemetryInterval: Duration{time.Hour},
+			WebhookInterval:   Duration{10 * time.Second},
+		},
+		Battery: Battery{
+			MinLoadingLevel: 25,
+			RechargeRate:    2,
+			SafetyMargin:    10,
+			CriticalLevel:   10,
+		},
+		Telemetry: Telemetry{
+			Retention:        Duration{30 * 24 * time.Hour},
+			DownsampleAfter:  Duration{24 * time.Hour},
+			DownsampleBucket: Duration{5 * time.Minute},
+		},
+		MQTT: MQTT{
+			ClientID: "drone-service",
+			QoS:      1,
+		},
+		Webhooks: Webhooks{
+			Timeout:      Duration{10 * time.Second},
+			MaxAttempts:  6,
+			RetryBackoff: Duration{30 * time.Second},
+		},
+		Alerts: Alerts{
+			Rules: []AlertRule{
+				{Name: "low-battery-loaded", BelowLevel: 30, States: []string{"LOADING", "LOADED"}},
+				{Name: "fast-drain", MaxDrain: 2},
+			},
+		},
+	}
+}
+
+// Load builds the config from the defaults, then the config file at path if it is not empty,
+// then the environment variables, and validates the result.
+func Load(path string) (Config, error) {
+	config := Default()
+	if path != "" {
+		if err := loadFile(path, &config); err != nil {
+			return Config{}, err
+		}
+	}
+	if err := loadEnv(&config); err != nil {
+		return Config{}, err
+	}
+	if err := config.Validate(); err != nil {
+		return Config{}, err
+	}
+	return config, nil
+}
+
+func loadFile(path string, config *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, config)
+	case ".json":
+		err = json.Unmarshal(data, config)
+	default:
+		return errors.New(fmt.Sprintf("config file %s must be yaml or json", path))
+	}
+	if err != nil {
+		return errors.New(fmt.Sprintf("can not read config file %s: %v", path, err))
+	}
+	return nil
+}
+
+func loadEnv(config *Config) error {
+	for name, target := range map[string]*string{
+		"DRONE_DB_DRIVER":      &config.Database.Driver,
+		"DRONE_DB_SCHEMA":      &config.Database.Schema,
+		"DRONE_DB_PATH":        &config.Database.Path,
+		"DRONE_DB_HOST":        &config.Database.Host,
+		"DRONE_DB_USER":        &config.Database.User,
+		"DRONE_DB_PASSWORD":    &config.Database.Password,
+		"DRONE_DB_NAME":        &config.Database.Name,
+		"DRONE_DB_SSLMODE":     &config.Database.SSLMode,
+		"DRONE_DB_TIMEZONE":    &config.Database.TimeZone,
+		"DRONE_PORT":           &config.Server.Port,
+		"DRONE_MQTT_BROKER":    &config.MQTT.Broker,
+		"DRONE_MQTT_CLIENT_ID": &config.MQTT.ClientID,
+		"DRONE_MQTT_USERNAME":  &config.MQTT.Username,
+		"DRONE_MQTT_PASSWORD":  &config.MQTT.Password,
+	} {
+		if value, found := os.LookupEnv(name); found {
+			*target = value
+		}
+	}
+	for name, target := range map[string]*int{
+		"DRONE_DB_PORT":                   &config.Database.Port,
+		"DRONE_DB_MAX_OPEN_CONNS":         &config.Database.MaxOpenConns,
+		"DRONE_DB_MAX_IDLE_CONNS":         &config.Database.MaxIdleConns,
+		"DRONE_BATTERY_MIN_LOADING_LEVEL": &config.Battery.MinLoadingLevel,
+		"DRONE_BATTERY_CRITICAL_LEVEL":    &config.Battery.CriticalLevel,
+		"DRONE_MQTT_QOS":                  &config.MQTT.QoS,
+		"DRONE_WEBHOOK_MAX_ATTEMPTS":      &config.Webhooks.MaxAttempts,
+	} {
+		if value, found := os.LookupEnv(name); found {
+			number, err := strconv.Atoi(value)
+			if err != nil {
+				return errors.New(fmt.Sprintf("%s must be a number", name))
+			}
+			*target = number
+		}
+	}
+	for name, target := range map[string]*float64{
+		"DRONE_BATTERY_RECHARGE_RATE": &config.Battery.RechargeRate,
+		"DRONE_BATTERY_SAFETY_MARGIN": &config.Battery.SafetyMargin,
+	} {
+		if value, found := os.LookupEnv(name); found {
+			number, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return errors.New(fmt.Sprintf("%s must be a number", name))
+			}
+			*target = number
+		}
+	}
+	for name, target := range map[string]*Duration{
+		"DRONE_DB_CONN_MAX_LIFETIME":        &config.Database.ConnMaxLifetime,
+		"DRONE_CRON_BATTERY_INTERVAL":       &config.Cron.BatteryInterval,
+		"DRONE_CRON_CHARGE_INTERVAL":        &config.Cron.ChargeInterval,
+		"DRONE_CRON_DISPATCH_INTERVAL":      &config.Cron.DispatchInterval,
+		"DRONE_CRON_FLIGHT_INTERVAL":        &config.Cron.FlightInterval,
+		"DRONE_CRON_TELEMETRY_INTERVAL":     &config.Cron.TelemetryInterval,
+		"DRONE_TELEMETRY_RETENTION":         &config.Telemetry.Retention,
+		"DRONE_TELEMETRY_DOWNSAMPLE_AFTER":  &config.Telemetry.DownsampleAfter,
+		"DRONE_TELEMETRY_DOWNSAMPLE_BUCKET": &config.Telemetry.DownsampleBucket,
+		"DRONE_CRON_WEBHOOK_INTERVAL":       &config.Cron.WebhookInterval,
+		"DRONE_WEBHOOK_TIMEOUT":             &config.Webhooks.Timeout,
+		"DRONE_WEBHOOK_RETRY_BACKOFF":       &config.Webhooks.RetryBackoff,
+	} {
+		if value, found := os.LookupEnv(name); found {
+			if err := target.UnmarshalText([]byte(value)); err != nil {
+				return errors.New(fmt.Sprintf("%s must be a duration", name))
+			}
+		}
+	}
+	return nil
+}
+
+func (c Config) Validate() error {
+	switch c.Database.Driver {
+	case DriverPostgres:
+		if c.Database.Host == "" || c.Database.Name == "" || c.Database.User == "" {
+			return errors.New("database host, name and user are required")
+		}
+		if c.Database.Port < 1 || c.Database.Port > 65535 {
+			return errors.New(fmt.Sprintf("database port %d is not valid", c.Database.Port))
+		}
+	case DriverSQLite:
+		if c.Database.Path == "" {
+			return errors.New("sqlite database path is required")
+		}
+	case DriverMemory:
+	default:
+		return errors.New(fmt.Sprintf("database driver %q is not supported, use %s, %s or %s", c.Database.Driver, DriverPostgres, DriverSQLite, DriverMemory))
+	}
+	if c.Database.MaxOpenConns < 0 || c.Database.MaxIdleConns < 0 || c.Database.ConnMaxLifetime.Duration < 0 {
+		return errors.New("database pool sizes and connection lifetime can not be negative")
+	}
+	if c.Database.MaxOpenConns > 0 && c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+		return errors.New("database max idle connections can not be more than max open connections")
+	}
+	if port, err := strconv.Atoi(c.Server.Port); err != nil || port < 1 || port > 65535 {
+		return errors.New(fmt.Sprintf("server port %s is not valid", c.Server.Port))
+	}
+	if c.Cron.BatteryInterval.Duration <= 0 {
+		return errors.New("battery check interval must be positive")
+	}
+	if c.Cron.ChargeInterval.Duration <= 0 {
+		return errors.New("dock charge interval must be positive")
+	}
+	if c.Cron.DispatchInterval.Duration <= 0 {
+		return errors.New("order dispatch interval must be positive")
+	}
+	if c.Cron.FlightInterval.Duration <= 0 {
+		return errors.New("flight simulation interval must be positive")
+	}
+	if c.Cron.TelemetryInterval.Duration <= 0 {
+		return errors.New("telemetry compaction interval must be positive")
+	}
+	if c.Cron.WebhookInterval.Duration <= 0 {
+		return errors.New("webhook retry interval must be positive")
+	}
+	if c.Battery.MinLoadingLevel < 0 || c.Battery.MinLoadingLevel > 100 {
+		return errors.New(fmt.Sprintf("minimum loading battery level %d must be between 0 and 100", c.Battery.MinLoadingLevel))
+	}
+	if c.Battery.CriticalLevel < 0 || c.Battery.CriticalLevel > c.Battery.MinLoadingLevel {
+		return errors.New(fmt.Sprintf("critical battery level %d must be between 0 and the minimum loading level %d", c.Battery.CriticalLevel, c.Battery.MinLoadingLevel))
+	}
+	if c.Battery.RechargeRate < 0 {
+		return errors.New("battery recharge rate can not be negative")
+	}
+	if c.Battery.SafetyMargin < 0 || c.Battery.SafetyMargin > 100 {
+		return errors.New(fmt.Sprintf("battery safety margin %v must be between 0 and 100", c.Battery.SafetyMargin))
+	}
+	if c.Telemetry.Retention.Duration <= 0 || c.Telemetry.DownsampleAfter.Duration <= 0 || c.Telemetry.DownsampleBucket.Duration <= 0 {
+		return errors.New("telemetry retention, downsampling age and bucket must be positive")
+	}
+	if c.Telemetry.DownsampleAfter.Duration >= c.Telemetry.Retention.Duration {
+		return errors.New(fmt.Sprintf("telemetry downsampling age %v must be less than the retention %v", c.Telemetry.DownsampleAfter.Duration, c.Telemetry.Retention.Duration))
+	}
+	if c.MQTT.Broker != "" {
+		broker, err := url.Parse(c.MQTT.Broker)
+		if err != nil || broker.Host == "" || !mqttSchemes[broker.Scheme] {
+			return errors.New(fmt.Sprintf("mqtt broker %s must be a tcp, ssl, ws or wss url", c.MQTT.Broker))
+		}
+		if c.MQTT.ClientID == "" {
+			return errors.New("mqtt client id is required")
+		}
+	}
+	if c.MQTT.QoS < 0 || c.MQTT.QoS > 2 {
+		return errors.New(fmt.Sprintf("mqtt qos %d must be 0, 1 or 2", c.MQTT.QoS))
+	}
+	if c.Webhooks.Timeout.Duration <= 0 || c.Webhooks.RetryBackoff.Duration <= 0 {
+		return errors.New("webhook timeout and retry backoff must be positive")
+	}
+	if c.Webhooks.MaxAttempts < 1 {
+		return errors.New(fmt.Sprintf("webhook max attempts %d must be at least 1", c.Webhooks.MaxAttempts))
+	}
+	return c.Alerts.validate()
+}
+
+func (a Alerts) validate() error {
+	names := map[string]bool{}
+	for _, rule := range a.Rules {
+		if rule.Name == "" {
+			return errors.New("alert rule name is required")
+		}
+		if names[rule.Name] || rule.Name == CriticalBatteryRule {
+			return errors.New(fmt.Sprintf("alert rule name %s is already taken", rule.Name))
+		}
+		names[rule.Name] = true
+		if rule.BelowLevel < 0 || rule.BelowLevel > 100 {
+			return errors.New(fmt.Sprintf("alert rule %s battery level %d must be between 0 and 100", rule.Name, rule.BelowLevel))
+		}
+		if rule.MaxDrain < 0 {
+			return errors.New(fmt.Sprintf("alert rule %s drain can not be negative", rule.Name))
+		}
+		if rule.BelowLevel == 0 && rule.MaxDrain == 0 {
+			return errors.New(fmt.Sprintf("alert rule %s must set a battery level or a drain", rule.Name))
+		}
+		for _, state := range rule.States {
+			if !droneStates[state] {
+				return errors.New(fmt.Sprintf("alert rule %s state %q is not exist", rule.Name, state))
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,310 @@
+package settings
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func writeConfigFile(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Can't write config file: %v", err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		env     map[string]string
+		check   func(config Config) bool
+		wantErr bool
+	}{
+		{
+			name:  "defaults",
+			check: func(config Config) bool { return reflect.DeepEqual(config, Default()) },
+		},
+		{
+			name:    "yaml file",
+			file:    "config.yaml",
+			content: "database:\n  host: db\n  max_open_conns: 20\nserver:\n  port: \"8080\"\ncron:\n  battery_interval: 30s\n",
+			check: func(config Config) bool {
+				return config.Database.Host == "db" && config.Database.MaxOpenConns == 20 && config.Database.Name == "drone" &&
+					config.Server.Port == "8080" && config.Cron.BatteryInterval.Duration == 30*time.Second
+			},
+		},
+		{
+			name:    "json file",
+			file:    "config.json",
+			content: `{"database": {"port": 6543}, "battery": {"min_loading_level": 30}}`,
+			check: func(config Config) bool {
+				return config.Database.Port == 6543 && config.Battery.MinLoadingLevel == 30
+			},
+		},
+		{
+			name:    "environment overrides file",
+			file:    "config.json",
+			content: `{"database": {"host": "db"}}`,
+			env:     map[string]string{"DRONE_DB_HOST": "env-db", "DRONE_PORT": "9000", "DRONE_CRON_BATTERY_INTERVAL": "2m", "DRONE_BATTERY_RECHARGE_RATE": "1.5", "DRONE_BATTERY_SAFETY_MARGIN": "15"},
+			check: func(config Config) bool {
+				return config.Database.Host == "env-db" && config.Server.Port == "9000" && config.Cron.BatteryInterval.Duration == 2*time.Minute &&
+					config.Battery.RechargeRate == 1.5 && config.Battery.SafetyMargin == 15
+			},
+		},
+		{
+			name:    "telemetry from file and environment",
+			file:    "config.yaml",
+			content: "telemetry:\n  retention: 48h\n  downsample_bucket: 1m\n",
+			env:     map[string]string{"DRONE_TELEMETRY_DOWNSAMPLE_AFTER": "12h", "DRONE_CRON_TELEMETRY_INTERVAL": "30m"},
+			check: func(config Config) bool {
+				return config.Telemetry.Retention.Duration == 48*time.Hour && config.Telemetry.DownsampleBucket.Duration == time.Minute &&
+					config.Telemetry.DownsampleAfter.Duration == 12*time.Hour && config.Cron.TelemetryInterval.Duration == 30*time.Minute
+			},
+		},
+		{
+			name: "mqtt from environment",
+			env:  map[string]string{"DRONE_MQTT_BROKER": "tcp://broker:1883", "DRONE_MQTT_USERNAME": "drone", "DRONE_MQTT_QOS": "0"},
+			check: func(config Config) bool {
+				return config.MQTT.Broker == "tcp://broker:1883" && config.MQTT.Username == "drone" && config.MQTT.QoS == 0 &&
+					config.MQTT.ClientID == "drone-service"
+			},
+		},
+		{
+			name: "webhooks from environment",
+			env:  map[string]string{"DRONE_WEBHOOK_MAX_ATTEMPTS": "3", "DRONE_WEBHOOK_RETRY_BACKOFF": "1m", "DRONE_CRON_WEBHOOK_INTERVAL": "5s"},
+			check: func(config Config) bool {
+				return config.Webhooks.MaxAttempts == 3 && config.Webhooks.RetryBackoff.Duration == time.Minute &&
+					config.Webhooks.Timeout.Duration == 10*time.Second && config.Cron.WebhookInterval.Duration == 5*time.Second
+			},
+		},
+		{
+			name:    "alerts from file and environment",
+			file:    "config.yaml",
+			content: "alerts:\n  rules:\n    - name: idle-low\n      below_level: 20\n      states: [IDLE]\n",
+			env:     map[string]string{"DRONE_BATTERY_CRITICAL_LEVEL": "5"},
+			check: func(config Config) bool {
+				return reflect.DeepEqual(config.Alerts.Rules, []AlertRule{{Name: "idle-low", BelowLevel: 20, States: []string{"IDLE"}}}) &&
+					config.Battery.CriticalLevel == 5
+			},
+		},
+		{
+			name:    "unsupported file",
+			file:    "config.toml",
+			content: "port = 1",
+			wantErr: true,
+		},
+		{
+			name:    "invalid number in environment",
+			env:     map[string]string{"DRONE_DB_PORT": "five"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid config",
+			file:    "config.yaml",
+			content: "database:\n  max_open_conns: 2\n  max_idle_conns: 5\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			path := ""
+			if tt.file != "" {
+				path = writeConfigFile(t, tt.file, tt.content)
+			}
+			got, err := Load(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !tt.check(got) {
+				t.Errorf("Load() = %+v", got)
+			}
+		})
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func(config *Config)
+		wantErr bool
+	}{
+		{
+			name:   "default config",
+			change: func(config *Config) {},
+		},
+		{
+			name: "sqlite without postgres settings",
+			change: func(config *Config) {
+				config.Database = Database{Driver: DriverSQLite, Path: ":memory:"}
+			},
+		},
+		{
+			name:    "sqlite without path",
+			change:  func(config *Config) { config.Database.Driver, config.Database.Path = DriverSQLite, "" },
+			wantErr: true,
+		},
+		{
+			name: "memory storage",
+			change: func(config *Config) {
+				config.Database = Database{Driver: DriverMemory}
+			},
+		},
+		{
+			name:    "unknown driver",
+			change:  func(config *Config) { config.Database.Driver = "mysql" },
+			wantErr: true,
+		},
+		{
+			name:    "missing database host",
+			change:  func(config *Config) { config.Database.Host = "" },
+			wantErr: true,
+		},
+		{
+			name:    "invalid server port",
+			change:  func(config *Config) { config.Server.Port = "http" },
+			wantErr: true,
+		},
+		{
+			name:    "zero battery interval",
+			change:  func(config *Config) { config.Cron.BatteryInterval = Duration{} },
+			wantErr: true,
+		},
+		{
+			name:    "zero charge interval",
+			change:  func(config *Config) { config.Cron.ChargeInterval = Duration{} },
+			wantErr: true,
+		},
+		{
+			name:    "zero dispatch interval",
+			change:  func(config *Config) { config.Cron.DispatchInterval = Duration{} },
+			wantErr: true,
+		},
+		{
+			name:    "zero flight interval",
+			change:  func(config *Config) { config.Cron.FlightInterval = Duration{} },
+			wantErr: true,
+		},
+		{
+			name:    "zero telemetry interval",
+			change:  func(config *Config) { config.Cron.TelemetryInterval = Duration{} },
+			wantErr: true,
+		},
+		{
+			name:    "zero telemetry bucket",
+			change:  func(config *Config) { config.Telemetry.DownsampleBucket = Duration{} },
+			wantErr: true,
+		},
+		{
+			name:    "downsampling after the retention",
+			change:  func(config *Config) { config.Telemetry.DownsampleAfter = config.Telemetry.Retention },
+			wantErr: true,
+		},
+		{
+			name:    "mqtt broker without scheme",
+			change:  func(config *Config) { config.MQTT.Broker = "broker:1883" },
+			wantErr: true,
+		},
+		{
+			name:    "mqtt broker without client id",
+			change:  func(config *Config) { config.MQTT.Broker, config.MQTT.ClientID = "tcp://broker:1883", "" },
+			wantErr: true,
+		},
+		{
+			name:    "mqtt qos out of range",
+			change:  func(config *Config) { config.MQTT.QoS = 3 },
+			wantErr: true,
+		},
+		{
+			name:    "zero webhook interval",
+			change:  func(config *Config) { config.Cron.WebhookInterval = Duration{} },
+			wantErr: true,
+		},
+		{
+			name:    "zero webhook timeout",
+			change:  func(config *Config) { config.Webhooks.Timeout = Duration{} },
+			wantErr: true,
+		},
+		{
+			name:    "no webhook attempts",
+			change:  func(config *Config) { config.Webhooks.MaxAttempts = 0 },
+			wantErr: true,
+		},
+		{
+			name:    "negative recharge rate",
+			change:  func(config *Config) { config.Battery.RechargeRate = -1 },
+			wantErr: true,
+		},
+		{
+			name:    "safety margin out of range",
+			change:  func(config *Config) { config.Battery.SafetyMargin = -5 },
+			wantErr: true,
+		},
+		{
+			name:    "battery level out of range",
+			change:  func(config *Config) { config.Battery.MinLoadingLevel = 120 },
+			wantErr: true,
+		},
+		{
+			name:    "critical level above loading level",
+			change:  func(config *Config) { config.Battery.CriticalLevel = 30 },
+			wantErr: true,
+		},
+		{
+			name:   "no alert rules",
+			change: func(config *Config) { config.Alerts.Rules = nil },
+		},
+		{
+			name:    "alert rule without a name",
+			change:  func(config *Config) { config.Alerts.Rules = []AlertRule{{BelowLevel: 20}} },
+			wantErr: true,
+		},
+		{
+			name: "duplicate alert rule",
+			change: func(config *Config) {
+				config.Alerts.Rules = append(config.Alerts.Rules, AlertRule{Name: "fast-drain", MaxDrain: 5})
+			},
+			wantErr: true,
+		},
+		{
+			name:    "alert rule named as the critical alert",
+			change:  func(config *Config) { config.Alerts.Rules = []AlertRule{{Name: CriticalBatteryRule, BelowLevel: 20}} },
+			wantErr: true,
+		},
+		{
+			name:    "alert rule without conditions",
+			change:  func(config *Config) { config.Alerts.Rules = []AlertRule{{Name: "idle", States: []string{"IDLE"}}} },
+			wantErr: true,
+		},
+		{
+			name: "alert rule with an unknown state",
+			change: func(config *Config) {
+				config.Alerts.Rules = []AlertRule{{Name: "low", BelowLevel: 20, States: []string{"FLYING"}}}
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := Default()
+			tt.change(&config)
+			if err := config.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Config.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDatabase_DSN(t *testing.T) {
+	want := "host=localhost user=postgres password=postgres dbname=drone port=5432 sslmode=disable TimeZone=Africa/Cairo"
+	if got := Default().Database.DSN(); got != want {
+		t.Errorf("Database.DSN() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,227 @@
+package usecase
+
+import (
+	"drone/v2/clock"
+	repo "drone/v2/repository"
+	"drone/v2/settings"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+)
+
+const (
+	AlertOpen         = "OPEN"
+	AlertAcknowledged = "ACKNOWLEDGED"
+	AlertResolved     = "RESOLVED"
+)
+
+const (
+	AlertWarning  = "WARNING"
+	AlertCritical = "CRITICAL"
+)
+
+var alertStatuses = map[string]bool{
+	AlertOpen:         true,
+	AlertAcknowledged: true,
+	AlertResolved:     true,
+}
+
+// alertFilter picks the fleet events the alert rules are evaluated on
+var alertFilter = EventFilter{Types: []string{EventBatteryTick, EventDroneGrounded}}
+
+var ErrAlertNotFound = errors.New("alert is not exist")
+
+// ErrAlertChanged is returned when the alert was acknowledged or resolved by another request
+// between reading and persisting it.
+var ErrAlertChanged = repo.ErrAlertChanged
+
+type AlertStatusError struct {
+	AlertID int
+	Status  string
+	Action  string
+}
+
+func (e *AlertStatusError) Error() string {
+	return fmt.Sprintf("alert %d is %s and can not be %s", e.AlertID, e.Status, e.Action)
+}
+
+type IAlertUsecase interface {
+	ListAlerts(droneID int, status string) ([]repo.Alert, error)
+	GetAlert(id int) (repo.Alert, error)
+	AcknowledgeAlert(id int) (repo.Alert, error)
+	ResolveAlert(id int) (repo.Alert, error)
+}
+
+type alertUsecase struct {
+	alertRepo repo.IAlertRepository
+	droneRepo repo.IDroneRepository
+	events    IEventBus
+	rules     []settings.AlertRule
+	clock     clock.Clock
+}
+
+// NewAlertUsecase starts evaluating the alert rules on the battery ticks of the fleet, and recording
+// a critical alert for every grounded drone that stays grounded until the alert is resolved.
+func NewAlertUsecase(a repo.IAlertRepository, d repo.IDroneRepository, events IEventBus, config settings.Alerts, clock clock.Clock) IAlertUsecase {
+	usecase := &alertUsecase{
+		alertRepo: a,
+		droneRepo: d,
+		events:    events,
+		rules:     config.Rules,
+		clock:     clock,
+	}
+	if subscription, err := events.Subscribe(alertFilter, 0); err != nil {
+		log.Println(err.Error())
+	} else {
+		go usecase.listen(subscription)
+	}
+	return usecase
+}
+
+func (a *alertUsecase) ListAlerts(droneID int, status string) ([]repo.Alert, error) {
+	if status != "" && !alertStatuses[status] {
+		return nil, errors.New(fmt.Sprintf("alert status %q is not exist", status))
+	}
+	return a.alertRepo.List(repo.AlertFilter{DroneID: droneID, Status: status})
+}
+
+func (a *alertUsecase) GetAlert(id int) (repo.Alert, error) {
+	alert, err := a.alertRepo.Get(id)
+	if err != nil {
+		return repo.Alert{}, alertError(err)
+	}
+	return alert, nil
+}
+
+// AcknowledgeAlert marks an open alert as seen, the rule does not fire again for the drone until it is resolved.
+func (a *alertUsecase) AcknowledgeAlert(id int) (repo.Alert, error) {
+	alert, err := a.GetAlert(id)
+	if err != nil {
+		return repo.Alert{}, err
+	}
+	if alert.Status != AlertOpen {
+		return repo.Alert{}, &AlertStatusError{AlertID: id, Status: alert.Status, Action: "acknowledged"}
+	}
+	now := a.clock.Now()
+	alert.Status, alert.AcknowledgedAt = AlertAcknowledged, &now
+	if err := a.alertRepo.Update(alert, AlertOpen); err != nil {
+		return repo.Alert{}, alertError(err)
+	}
+	return alert, nil
+}
+
+// ResolveAlert closes the alert so its rule can fire again for the drone, resolving
+// a critical battery alert releases the grounded drone.
+func (a *alertUsecase) ResolveAlert(id int) (repo.Alert, error) {
+	alert, err := a.GetAlert(id)
+	if err != nil {
+		return repo.Alert{}, err
+	}
+	if alert.Status == AlertResolved {
+		return repo.Alert{}, &AlertStatusError{AlertID: id, Status: alert.Status, Action: "resolved"}
+	}
+	from := alert.Status
+	now := a.clock.Now()
+	alert.Status, alert.ResolvedAt = AlertResolved, &now
+	if err := a.alertRepo.Update(alert, from); err != nil {
+		return repo.Alert{}, alertError(err)
+	}
+	if alert.Rule == settings.CriticalBatteryRule {
+		// a decommissioned drone has nothing to be released from
+		if err := a.droneRepo.SetGrounded(alert.DroneID, false); err != nil && !errors.Is(err, repo.ErrRecordNotFound) {
+			return repo.Alert{}, err
+		}
+	}
+	return alert, nil
+}
+
+// listen evaluates the events of the subscription, it subscribes again after the last
+// evaluated event when it was dropped for falling behind.
+func (a *alertUsecase) listen(subscription *Subscription) {
+	var lastEventID int64
+	for {
+		for event := range subscription.Events {
+			lastEventID = event.ID
+			a.evaluate(event)
+		}
+		var err error
+		if subscription, err = a.events.Subscribe(alertFilter, lastEventID); err != nil {
+			log.Println(err.Error())
+			return
+		}
+	}
+}
+
+func (a *alertUsecase) evaluate(event Event) {
+	battery, ok := event.Data.(BatteryEvent)
+	if !ok {
+		return
+	}
+	if event.Type == EventDroneGrounded {
+		a.fire(repo.Alert{
+			DroneID:  event.DroneID,
+			Rule:     settings.CriticalBatteryRule,
+			Severity: AlertCritical,
+			Message:  fmt.Sprintf("battery is %d%%, the drone is grounded until the alert is resolved", battery.Level),
+		}, battery)
+		return
+	}
+	for _, rule := range a.rules {
+		if ruleMatches(rule, battery) {
+			a.fire(repo.Alert{DroneID: event.DroneID, Rule: rule.Name, Severity: AlertWarning, Message: ruleMessage(rule, battery)}, battery)
+		}
+	}
+}
+
+// fire records the alert unless the rule already has an alert for the drone that is not resolved.
+func (a *alertUsecase) fire(alert repo.Alert, battery BatteryEvent) {
+	if _, err := a.alertRepo.FindOpen(alert.DroneID, alert.Rule); !errors.Is(err, repo.ErrRecordNotFound) {
+		if err != nil {
+			log.Println(err.Error())
+		}
+		return
+	}
+	alert.BatteryLevel, alert.DroneState, alert.Status = battery.Level, battery.State, AlertOpen
+	if _, err := a.alertRepo.Create(&alert); err != nil {
+		log.Println(err.Error())
+		return
+	}
+	a.events.Publish(EventAlertFired, alert.DroneID, alert)
+}
+
+func ruleMatches(rule settings.AlertRule, battery BatteryEvent) bool {
+	if rule.BelowLevel > 0 && battery.Level >= rule.BelowLevel {
+		return false
+	}
+	if rule.MaxDrain > 0 && battery.Drain <= rule.MaxDrain {
+		return false
+	}
+	if len(rule.States) == 0 {
+		return true
+	}
+	for _, state := range rule.States {
+		if state == battery.State {
+			return true
+		}
+	}
+	return false
+}
+
+func ruleMessage(rule settings.AlertRule, battery BatteryEvent) string {
+	parts := []string{}
+	if rule.BelowLevel > 0 {
+		parts = append(parts, fmt.Sprintf("battery is %d%%, under %d%%", battery.Level, rule.BelowLevel))
+	}
+	if rule.MaxDrain > 0 {
+		parts = append(parts, fmt.Sprintf("battery drops %v%% per minute, faster than %v%%", battery.Drain, rule.MaxDrain))
+	}
+	return fmt.Sprintf("%s while %s", strings.Join(parts, " and "), battery.State)
+}
+
+func alertError(err error) error {
+	if errors.Is(err, repo.ErrRecordNotFound) {
+		return ErrAlertNotFound
+	}
+	return err
+}
@@ -0,0 +1,141 @@
+package usecase
+
+import (
+	"drone/v2/clock"
+	repo "drone/v2/repository"
+	"drone/v2/settings"
+	"testing"
+	"time"
+)
+
+var testAlertRules = settings.Alerts{Rules: []settings.AlertRule{
+	{Name: "low-battery-loaded", BelowLevel: 30, States: []string{StateLoading, StateLoaded}},
+	{Name: "fast-drain", MaxDrain: 2},
+}}
+
+func newMemoryAlertUsecase(t *testing.T, drones ...repo.Drone) (*alertUsecase, repo.IDroneRepository, IEventBus, *clock.Fake) {
+	t.Helper()
+	clock := clock.NewFake(testTime)
+	droneRepo := repo.NewMemoryDroneRepo(nil)
+	createDrones(t, droneRepo, drones...)
+	events := NewEventBus(clock)
+	u := NewAlertUsecase(repo.NewMemoryAlertRepo(clock), droneRepo, events, testAlertRules, clock)
+	return u.(*alertUsecase), droneRepo, events, clock
+}
+
+// waitForAlerts waits until the subscription got the next n fired alerts, they are the newest
+// of the alerts the usecase returns, the newest first.
+func waitForAlerts(t *testing.T, u *alertUsecase, fired *Subscription, n int) []repo.Alert {
+	t.Helper()
+	events := nextEvents(t, fired, n)
+	alerts, err := u.ListAlerts(0, "")
+	if err != nil || len(alerts) < n {
+		t.Fatalf("alerts = %+v, %v, want at least %d", alerts, err, n)
+	}
+	for i, event := range events {
+		if event.Data.(repo.Alert).ID != alerts[n-1-i].ID {
+			t.Fatalf("fired events = %+v, want the newest of the alerts %+v", events, alerts)
+		}
+	}
+	return alerts
+}
+
+func Test_ruleMatches(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    settings.AlertRule
+		battery BatteryEvent
+		want    bool
+	}{
+		{name: "under the level", rule: testAlertRules.Rules[0], battery: BatteryEvent{Level: 29, State: StateLoaded}, want: true},
+		{name: "at the level", rule: testAlertRules.Rules[0], battery: BatteryEvent{Level: 30, State: StateLoaded}},
+		{name: "in another state", rule: testAlertRules.Rules[0], battery: BatteryEvent{Level: 20, State: StateIdle}},
+		{name: "drains too fast", rule: testAlertRules.Rules[1], battery: BatteryEvent{Level: 90, State: StateIdle, Drain: 2.5}, want: true},
+		{name: "drains at the limit", rule: testAlertRules.Rules[1], battery: BatteryEvent{Level: 90, State: StateIdle, Drain: 2}},
+		{
+			name:    "every condition",
+			rule:    settings.AlertRule{Name: "both", BelowLevel: 50, MaxDrain: 1},
+			battery: BatteryEvent{Level: 40, Drain: 0.5},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ruleMatches(tt.rule, tt.battery); got != tt.want {
+				t.Errorf("ruleMatches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_alertUsecase_Rules(t *testing.T) {
+	u, _, events, clock := newMemoryAlertUsecase(t)
+	fired, _ := events.Subscribe(EventFilter{Types: []string{EventAlertFired}}, 0)
+
+	events.Publish(EventBatteryTick, 1, BatteryEvent{Level: 40, State: StateLoaded, Drain: 0.2})
+	events.Publish(EventBatteryTick, 1, BatteryEvent{Level: 29, State: StateLoaded, Drain: 0.2})
+	// the open alert of the rule is not fired again for the drone
+	events.Publish(EventBatteryTick, 1, BatteryEvent{Level: 28, State: StateLoaded, Drain: 0.2})
+	events.Publish(EventBatteryTick, 2, BatteryEvent{Level: 80, State: StateIdle, Drain: 3})
+	alerts := waitForAlerts(t, u, fired, 2)
+	if len(alerts) != 2 {
+		t.Fatalf("alerts = %+v, want 2", alerts)
+	}
+	low, drain := alerts[1], alerts[0]
+	if low.DroneID != 1 || low.Rule != "low-battery-loaded" || low.Severity != AlertWarning || low.Status != AlertOpen ||
+		low.BatteryLevel != 29 || low.DroneState != StateLoaded || low.Message != "battery is 29%, under 30% while LOADED" {
+		t.Errorf("alert = %+v, want the low battery of the loaded drone", low)
+	}
+	if drain.DroneID != 2 || drain.Rule != "fast-drain" || drain.Message != "battery drops 3% per minute, faster than 2% while IDLE" {
+		t.Errorf("alert = %+v, want the fast drain of the idle drone", drain)
+	}
+
+	clock.Advance(time.Minute)
+	acknowledged, err := u.AcknowledgeAlert(low.ID)
+	if err != nil || acknowledged.Status != AlertAcknowledged || acknowledged.AcknowledgedAt == nil || !acknowledged.AcknowledgedAt.Equal(testTime.Add(time.Minute)) {
+		t.Fatalf("alertUsecase.AcknowledgeAlert() = %+v, %v, want it acknowledged now", acknowledged, err)
+	}
+	if _, err := u.AcknowledgeAlert(low.ID); err == nil || err.Error() != "alert 1 is ACKNOWLEDGED and can not be acknowledged" {
+		t.Errorf("alertUsecase.AcknowledgeAlert() again error = %v", err)
+	}
+	resolved, err := u.ResolveAlert(low.ID)
+	if err != nil || resolved.Status != AlertResolved || resolved.ResolvedAt == nil || resolved.AcknowledgedAt == nil {
+		t.Fatalf("alertUsecase.ResolveAlert() = %+v, %v, want it resolved", resolved, err)
+	}
+	if _, err := u.ResolveAlert(low.ID); err == nil || err.Error() != "alert 1 is RESOLVED and can not be resolved" {
+		t.Errorf("alertUsecase.ResolveAlert() again error = %v", err)
+	}
+	// the rule fires again once its alert is resolved
+	events.Publish(EventBatteryTick, 1, BatteryEvent{Level: 26, State: StateLoaded})
+	if alerts := waitForAlerts(t, u, fired, 1); len(alerts) != 3 || alerts[0].BatteryLevel != 26 || alerts[0].Status != AlertOpen {
+		t.Errorf("alerts = %+v, want a new alert at 26%%", alerts)
+	}
+
+	if alerts, err := u.ListAlerts(1, AlertResolved); err != nil || len(alerts) != 1 || alerts[0].ID != low.ID {
+		t.Errorf("alertUsecase.ListAlerts() = %+v, %v, want the resolved alert", alerts, err)
+	}
+	if _, err := u.ListAlerts(0, "CLOSED"); err == nil || err.Error() != `alert status "CLOSED" is not exist` {
+		t.Errorf("alertUsecase.ListAlerts() error = %v", err)
+	}
+	if _, err := u.GetAlert(10); err != ErrAlertNotFound {
+		t.Errorf("alertUsecase.GetAlert() error = %v, wantErr %v", err, ErrAlertNotFound)
+	}
+}
+
+func Test_alertUsecase_Grounded(t *testing.T) {
+	u, droneRepo, events, _ := newMemoryAlertUsecase(t, repo.Drone{SerialNumber: "serial 1", State: StateIdle, BatteryCapacity: 9})
+	fired, _ := events.Subscribe(EventFilter{Types: []string{EventAlertFired}}, 0)
+	droneRepo.SetGrounded(1, true)
+
+	events.Publish(EventDroneGrounded, 1, BatteryEvent{Level: 9, State: StateIdle})
+	alert := waitForAlerts(t, u, fired, 1)[0]
+	if alert.Rule != settings.CriticalBatteryRule || alert.Severity != AlertCritical || alert.BatteryLevel != 9 ||
+		alert.Message != "battery is 9%, the drone is grounded until the alert is resolved" {
+		t.Errorf("alert = %+v, want the critical battery alert", alert)
+	}
+	if _, err := u.ResolveAlert(alert.ID); err != nil {
+		t.Fatalf("alertUsecase.ResolveAlert() error = %v", err)
+	}
+	if drone, _ := droneRepo.Get(1); drone.Grounded {
+		t.Errorf("drone = %+v, want it released once the critical alert is resolved", drone)
+	}
+}
@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	repo "drone/v2/repository"
+	"errors"
+	"log"
+	"math"
+	"sync"
+	"time"
+)
+
+// modelBatteryCapacity is the energy every model holds with a full battery, heavier
+// models carry bigger batteries so the same drain takes a smaller share of them.
+var modelBatteryCapacity = map[string]float64{
+	"Lightweight":   100,
+	"Middleweight":  150,
+	"Cruiserweight": 200,
+	"Heavyweight":   250,
+}
+
+// stateDrain is the energy a drone uses per minute in every state without payload,
+// flying drones also use deliveringDrainPerGram for every gram they carry.
+var stateDrain = map[string]float64{
+	StateIdle:       0.1,
+	StateLoading:    0.2,
+	StateLoaded:     0.2,
+	StateDelivering: 1.5,
+	StateDelivered:  0.3,
+	StateReturning:  1.5,
+}
+
+const deliveringDrainPerGram = 0.01
+
+var ErrDroneGrounded = errors.New("drone is grounded until its critical battery alert is resolved")
+
+// batteryMeter keeps the exact battery level of every drone between checks, the stored
+// level is a whole percentage so small drains are summed until they take a full percent.
+type batteryMeter struct {
+	mu     sync.Mutex
+	levels map[int]float64
+}
+
+func newBatteryMeter() *batteryMeter {
+	return &batteryMeter{
+		levels: map[int]float64{},
+	}
+}
+
+// level is the exact level of the drone, it starts again from the stored level when
+// the battery was changed by someone else.
+func (m *batteryMeter) level(drone repo.Drone) float64 {
+	level, found := m.levels[drone.ID]
+	if !found || int(math.Round(level)) != drone.BatteryCapacity {
+		return float64(drone.BatteryCapacity)
+	}
+	return level
+}
+
+func batteryCapacity(model string) float64 {
+	if capacity, found := modelBatteryCapacity[model]; found {
+		return capacity
+	}
+	return modelBatteryCapacity["Lightweight"]
+}
+
+// batteryChange is the battery percentage the drone uses per minute as a negative number.
+func batteryChange(drone repo.Drone) float64 {
+	drain := stateDrain[drone.State]
+	if drone.State == StateDelivering {
+		drain += float64(drone.CurrentPayload) * deliveringDrainPerGram
+	}
+	return -drain / batteryCapacity(drone.Model) * 100
+}
+
+// CheckDronesBatteries simulates the batteries of all drones for the elapsed time, drones drain by
+// their state and payload. Docked drones are skipped as they are charged by the dock, and drones out
+// of the base are skipped as they are drained by the flight simulator. Every changed level is published
+// by BatteryChanged.
+func (d *droneUsecase) CheckDronesBatteries(elapsed time.Duration) {
+	drones, _, err := d.droneRepo.List(repo.DroneFilter{})
+	if err != nil {
+		log.Println(err.Error())
+		return
+	}
+	d.batteries.mu.Lock()
+	defer d.batteries.mu.Unlock()
+	levels := map[int]int{}
+	exact := map[int]float64{}
+	drains := map[int]float64{}
+	for _, drone := range drones {
+		if drone.DockID != nil || flightStates[drone.State] {
+			continue
+		}
+		level := d.batteries.level(drone) + batteryChange(drone)*elapsed.Minutes()
+		level = math.Max(0, math.Min(100, level))
+		exact[drone.ID] = level
+		previous, found := d.batteries.levels[drone.ID]
+		if !found {
+			previous = float64(drone.BatteryCapacity)
+		}
+		drains[drone.ID] = drainRate(previous, level, elapsed)
+		if rounded := int(math.Round(level)); rounded != drone.BatteryCapacity {
+			levels[drone.ID] = rounded
+		}
+	}
+	if err := d.droneRepo.UpdateBatteries(levels, ""); err != nil {
+		log.Println(err.Error())
+		return
+	}
+	d.batteries.levels = exact
+	for _, drone := range drones {
+		if _, checked := exact[drone.ID]; !checked {
+			continue
+		}
+		level, changed := levels[drone.ID]
+		if !changed {
+			level = drone.BatteryCapacity
+		}
+		d.BatteryChanged(drone, level, drains[drone.ID])
+	}
+}
+
+// BatteryChanged publishes the level the battery of the drone was set to, the drone is as it was before the
+// change. Drones that went below the minimum loading level are published too and drones under the critical
+// level are grounded, whether the level was set by the battery check, a flight, a dock or the drone telemetry.
+func (d *droneUsecase) BatteryChanged(drone repo.Drone, level int, drain float64) {
+	if level != drone.BatteryCapacity {
+		d.publishEvent(EventBatteryTick, drone.ID, BatteryEvent{Level: level, State: drone.State, Drain: drain})
+		if level < d.minLoadingBattery && drone.BatteryCapacity >= d.minLoadingBattery {
+			d.publishEvent(EventBatteryLow, drone.ID, BatteryEvent{Level: level})
+		}
+	}
+	if level < d.criticalBattery && !drone.Grounded {
+		d.ground(drone, level)
+	}
+}
+
+// ground takes the drone out of the loadable pool until its critical battery alert is resolved.
+func (d *droneUsecase) ground(drone repo.Drone, level int) {
+	if err := d.droneRepo.SetGrounded(drone.ID, true); err != nil {
+		log.Println(err.Error())
+		return
+	}
+	d.publishEvent(EventDroneGrounded, drone.ID, BatteryEvent{Level: level, State: drone.State})
+}
+
+// drainRate is the battery percentage per minute the level dropped in the elapsed time, rounded to a hundredth.
+func drainRate(previous float64, level float64, elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return 0
+	}
+	return math.Round((previous-level)/elapsed.Minutes()*100) / 100
+}
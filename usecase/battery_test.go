@@ -0,0 +1,143 @@
+package usecase
+
+import (
+	repo "drone/v2/repository"
+	"math"
+	"testing"
+	"time"
+)
+
+func Test_batteryChange(t *testing.T) {
+	tests := []struct {
+		name  string
+		drone repo.Drone
+		want  float64
+	}{
+		{
+			name:  "idle drone trickles",
+			drone: repo.Drone{State: StateIdle, Model: "Lightweight"},
+			want:  -0.1,
+		},
+		{
+			name:  "loaded drone trickles",
+			drone: repo.Drone{State: StateLoaded, Model: "Lightweight", CurrentPayload: 300},
+			want:  -0.2,
+		},
+		{
+			name:  "delivering drone drains by payload",
+			drone: repo.Drone{State: StateDelivering, Model: "Lightweight", CurrentPayload: 250},
+			want:  -4,
+		},
+		{
+			name:  "heavyweight has a bigger battery",
+			drone: repo.Drone{State: StateDelivering, Model: "Heavyweight", CurrentPayload: 250},
+			want:  -1.6,
+		},
+		{
+			name:  "returning drone drains without payload",
+			drone: repo.Drone{State: StateReturning, Model: "Middleweight"},
+			want:  -1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := batteryChange(tt.drone); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("batteryChange() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_droneUsecase_CheckDronesBatteries(t *testing.T) {
+	d, droneRepo, logRepo := newMemoryDroneUsecase(t, repo.Drone{
+		SerialNumber:    "loaded",
+		Model:           "Lightweight",
+		State:           StateLoaded,
+		BatteryCapacity: 50,
+	})
+	for _, drone := range []repo.Drone{
+		{SerialNumber: "idle", Model: "Lightweight", State: StateIdle, BatteryCapacity: 99},
+		{SerialNumber: "delivering", Model: "Lightweight", State: StateDelivering, BatteryCapacity: 5, CurrentPayload: 250},
+		{SerialNumber: "docked", Model: "Lightweight", State: StateIdle, BatteryCapacity: 50},
+	} {
+		drone := drone
+		droneRepo.Create(&drone)
+	}
+	droneRepo.Dock(4, 1, 1)
+
+	// the loaded drone uses 0.2% a minute so it takes 3 minutes to round down to 49%,
+	// the docked drone is left to the dock charging and the delivering one to the flight simulator
+	want := map[int][]int{
+		1: {50, 50, 49, 49, 49},
+		2: {99, 99, 99, 99, 99},
+		3: {5, 5, 5, 5, 5},
+		4: {50, 50, 50, 50, 50},
+	}
+	for minute := 0; minute < 5; minute++ {
+		d.CheckDronesBatteries(time.Minute)
+		for id, levels := range want {
+			drone, _ := droneRepo.Get(id)
+			if drone.BatteryCapacity != levels[minute] {
+				t.Errorf("minute %d battery of %s = %v, want %v", minute+1, drone.SerialNumber, drone.BatteryCapacity, levels[minute])
+			}
+		}
+	}
+	logs, _ := logRepo.List()
+	if len(logs) != 2 || logs[0].Description != "docked at dock 1" {
+		t.Errorf("battery logs = %v, want a log for every changed level", logs)
+	}
+
+	// a level set by someone else is taken as it is
+	droneRepo.UpdateBatteries(map[int]int{1: 80}, "")
+	d.CheckDronesBatteries(time.Minute)
+	if drone, _ := droneRepo.Get(1); drone.BatteryCapacity != 80 {
+		t.Errorf("battery = %v, want 80", drone.BatteryCapacity)
+	}
+}
+
+func Test_droneUsecase_CheckDronesBatteries_Grounding(t *testing.T) {
+	d, droneRepo, _ := newMemoryDroneUsecase(t, repo.Drone{
+		SerialNumber:    "idle",
+		Model:           "Lightweight",
+		Weight:          500,
+		State:           StateIdle,
+		BatteryCapacity: 10,
+	})
+	loaded := repo.Drone{SerialNumber: "loaded", Model: "Lightweight", Weight: 500, State: StateLoaded, BatteryCapacity: 9}
+	droneRepo.Create(&loaded)
+	d.criticalBattery = 10
+	d.events = NewEventBus(d.jobs.clock)
+	subscription, _ := d.events.Subscribe(EventFilter{Types: []string{EventDroneGrounded}}, 0)
+
+	// the loaded drone is already under the critical level and the idle one trickles under it
+	d.CheckDronesBatteries(time.Minute)
+	events := nextEvents(t, subscription, 1)
+	if events[0].DroneID != loaded.ID || events[0].Data != (BatteryEvent{Level: 9, State: StateLoaded}) {
+		t.Errorf("grounded event = %+v, want the loaded drone at 9%%", events[0])
+	}
+	d.CheckDronesBatteries(5 * time.Minute)
+	events = nextEvents(t, subscription, 1)
+	if events[0].DroneID != 1 || events[0].Data != (BatteryEvent{Level: 9, State: StateIdle}) {
+		t.Errorf("grounded event = %+v, want the idle drone at 9%%", events[0])
+	}
+	d.CheckDronesBatteries(time.Minute)
+	select {
+	case event := <-subscription.Events:
+		t.Errorf("event = %+v, want the grounded drones not grounded again", event)
+	default:
+	}
+
+	if available := d.CheckAvailableDroneForLoading(); len(available) != 0 {
+		t.Errorf("droneUsecase.CheckAvailableDroneForLoading() = %+v, want no grounded drone", available)
+	}
+	if _, err := d.LoadingMedication(1, LoadObject{Code: "code"}); err != ErrDroneGrounded {
+		t.Errorf("droneUsecase.LoadingMedication() error = %v, wantErr %v", err, ErrDroneGrounded)
+	}
+	if err := d.ChangeDroneState(loaded.ID, StateDelivering); err != ErrDroneGrounded {
+		t.Errorf("droneUsecase.ChangeDroneState() error = %v, wantErr %v", err, ErrDroneGrounded)
+	}
+	// a grounded drone can still be taken back to IDLE to be unloaded and charged
+	if err := d.ChangeDroneState(loaded.ID, StateIdle); err != nil {
+		t.Errorf("droneUsecase.ChangeDroneState() to IDLE error = %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+package usecase
+
+import (
+	repo "drone/v2/repository"
+	"log"
+	"time"
+)
+
+const (
+	CommandStartDelivery = "start-delivery"
+	CommandReturn        = "return"
+)
+
+// droneCommands are the commands sent to a drone when it is moved into a state.
+var droneCommands = map[string]string{
+	StateDelivering: CommandStartDelivery,
+	StateReturning:  CommandReturn,
+}
+
+// DroneCommand tells a drone what to do after it was moved into State.
+type DroneCommand struct {
+	Command              string    `json:"command"`
+	DroneID              int       `json:"drone_id"`
+	State                string    `json:"state"`
+	DestinationLatitude  *float64  `json:"destination_latitude,omitempty"`
+	DestinationLongitude *float64  `json:"destination_longitude,omitempty"`
+	HomeLatitude         float64   `json:"home_latitude"`
+	HomeLongitude        float64   `json:"home_longitude"`
+	IssuedAt             time.Time `json:"issued_at"`
+}
+
+// ICommandPublisher delivers the commands to the drones by their serial numbers, it is called with the
+// drone locked so it queues the command rather than wait for the drone to get it.
+type ICommandPublisher interface {
+	PublishCommand(serialNumber string, command DroneCommand) error
+}
+
+// sendCommand sends the drone the command of the state it was moved into, if there is a publisher.
+// The state is saved already so a command that can not be sent is only logged.
+func (d *droneUsecase) sendCommand(drone repo.Drone, state string) {
+	name, found := droneCommands[state]
+	if !found || d.commands == nil {
+		return
+	}
+	command := DroneCommand{
+		Command:              name,
+		DroneID:              drone.ID,
+		State:                state,
+		DestinationLatitude:  drone.DestinationLatitude,
+		DestinationLongitude: drone.DestinationLongitude,
+		HomeLatitude:         drone.HomeLatitude,
+		HomeLongitude:        drone.HomeLongitude,
+		IssuedAt:             d.jobs.clock.Now(),
+	}
+	if err := d.commands.PublishCommand(drone.SerialNumber, command); err != nil {
+		log.Println(err.Error())
+	}
+}
@@ -0,0 +1,65 @@
+package usecase
+
+import (
+	repo "drone/v2/repository"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type recordingPublisher struct {
+	serials  []string
+	commands []DroneCommand
+	err      error
+}
+
+func (p *recordingPublisher) PublishCommand(serialNumber string, command DroneCommand) error {
+	p.serials = append(p.serials, serialNumber)
+	p.commands = append(p.commands, command)
+	return p.err
+}
+
+func Test_droneUsecase_sendCommand(t *testing.T) {
+	d, droneRepo, _ := newMemoryDroneUsecase(t, repo.Drone{
+		SerialNumber:         "serial 1",
+		Model:                "Lightweight",
+		Weight:               500,
+		State:                StateLoaded,
+		BatteryCapacity:      100,
+		HomeLatitude:         30,
+		HomeLongitude:        31,
+		Latitude:             30,
+		Longitude:            31,
+		DestinationLatitude:  floatPointer(30.01),
+		DestinationLongitude: floatPointer(31),
+	})
+	publisher := &recordingPublisher{}
+	d.commands = publisher
+
+	if err := d.StartDelivery(1); err != nil {
+		t.Fatalf("droneUsecase.StartDelivery() error = %v", err)
+	}
+	if err := d.MarkDelivered(1); err != nil {
+		t.Fatalf("droneUsecase.MarkDelivered() error = %v", err)
+	}
+	// a drone that misses its command is still returning
+	publisher.err = errors.New("broker is down")
+	if err := d.ReturnToBase(1); err != nil {
+		t.Fatalf("droneUsecase.ReturnToBase() error = %v", err)
+	}
+	if drone, _ := droneRepo.Get(1); drone.State != StateReturning {
+		t.Errorf("drone state = %v, want %v", drone.State, StateReturning)
+	}
+
+	if !reflect.DeepEqual(publisher.serials, []string{"serial 1", "serial 1"}) || len(publisher.commands) != 2 {
+		t.Fatalf("published to %v, want the start delivery and return commands of serial 1", publisher.serials)
+	}
+	start, back := publisher.commands[0], publisher.commands[1]
+	if start.Command != CommandStartDelivery || start.DroneID != 1 || start.State != StateDelivering ||
+		start.DestinationLatitude == nil || *start.DestinationLatitude != 30.01 || !start.IssuedAt.Equal(testTime) {
+		t.Errorf("start delivery command = %+v, want it with the destination", start)
+	}
+	if back.Command != CommandReturn || back.State != StateReturning || back.HomeLatitude != 30 || back.HomeLongitude != 31 {
+		t.Errorf("return command = %+v, want it with the home base", back)
+	}
+}
@@ -0,0 +1,204 @@
+package usecase
+
+import (
+	repo "drone/v2/repository"
+	"fmt"
+	"log"
+	"sort"
+)
+
+// DispatchPlan is how the pending orders are spread over the available drones.
+type DispatchPlan struct {
+	DryRun      bool                 `json:"dry_run"`
+	Assignments []DispatchAssignment `json:"assignments"`
+	Unassigned  []int                `json:"unassigned"`
+	Failed      []int                `json:"failed,omitempty"`
+	DronesUsed  int                  `json:"drones_used"`
+}
+
+// DispatchAssignment is the orders loaded onto one drone, Remaining is the capacity left after them.
+type DispatchAssignment struct {
+	DroneID   int     `json:"drone_id"`
+	Orders    []int   `json:"orders"`
+	Weight    float32 `json:"weight"`
+	Remaining float32 `json:"remaining"`
+}
+
+type dispatchOrder struct {
+	order  repo.Order
+	weight float32
+}
+
+// PlanDispatch returns the plan Dispatch would run without loading any drone.
+func (o *orderUsecase) PlanDispatch() (DispatchPlan, error) {
+	o.assigning.Lock()
+	defer o.assigning.Unlock()
+	plan, _, err := o.planDispatch()
+	plan.DryRun = true
+	return plan, err
+}
+
+// Dispatch assigns the pending orders to the available drones and loads them, orders a drone
+// rejected are left PENDING for the next dispatch and orders it rejected after loading a part
+// of them are FAILED. The returned plan is what was loaded.
+func (o *orderUsecase) Dispatch() (DispatchPlan, error) {
+	o.assigning.Lock()
+	defer o.assigning.Unlock()
+	plan, orders, err := o.planDispatch()
+	if err != nil {
+		return plan, err
+	}
+	done := DispatchPlan{Assignments: []DispatchAssignment{}, Unassigned: plan.Unassigned}
+	for _, assignment := range plan.Assignments {
+		loaded := DispatchAssignment{DroneID: assignment.DroneID, Orders: []int{}}
+		for _, id := range assignment.Orders {
+			order := orders[id]
+			jobs, err := o.load(assignment.DroneID, order.order.Items)
+			if err != nil {
+				log.Println(fmt.Sprintf("order %d can not be loaded on drone %d: %s", id, assignment.DroneID, err.Error()))
+			}
+			if len(jobs) == 0 {
+				done.Unassigned = append(done.Unassigned, id)
+				continue
+			}
+			status := OrderAssigned
+			if err != nil {
+				status = OrderFailed
+			}
+			if err := o.orderRepo.Assign(id, status, assignment.DroneID, jobs); err != nil {
+				return done, err
+			}
+			if status == OrderFailed {
+				done.Failed = append(done.Failed, id)
+				continue
+			}
+			if err := o.routeDrone(assignment.DroneID, order.order); err != nil {
+				return done, err
+			}
+			loaded.Orders = append(loaded.Orders, id)
+			loaded.Weight += order.weight
+		}
+		if len(loaded.Orders) > 0 {
+			loaded.Remaining = assignment.Remaining + assignment.Weight - loaded.Weight
+			done.Assignments = append(done.Assignments, loaded)
+		}
+	}
+	sort.Ints(done.Unassigned)
+	done.DronesUsed = len(done.Assignments)
+	return done, nil
+}
+
+// planDispatch packs the pending orders and returns them by id with their weights,
+// orders with medications that are not in the catalog anymore are left unassigned.
+func (o *orderUsecase) planDispatch() (DispatchPlan, map[int]dispatchOrder, error) {
+	pending, err := o.orderRepo.List(OrderPending)
+	if err != nil {
+		return DispatchPlan{}, nil, err
+	}
+	orders := map[int]dispatchOrder{}
+	queue := make([]dispatchOrder, 0, len(pending))
+	unknown := []int{}
+	for _, order := range pending {
+		weight, err := o.orderWeight(order)
+		if err != nil {
+			log.Println(fmt.Sprintf("order %d can not be dispatched: %s", order.ID, err.Error()))
+			unknown = append(unknown, order.ID)
+			continue
+		}
+		orders[order.ID] = dispatchOrder{order: order, weight: weight}
+		queue = append(queue, orders[order.ID])
+	}
+	plan := packOrders(queue, o.droneRepo.AvailableDroneForLoading(), o.minLoadingBattery, o.safetyMargin)
+	plan.Unassigned = append(plan.Unassigned, unknown...)
+	sort.Ints(plan.Unassigned)
+	return plan, orders, nil
+}
+
+func (o *orderUsecase) orderWeight(order repo.Order) (float32, error) {
+	var weight float32
+	for _, item := range order.Items {
+		medication, err := o.medicationRepo.Get(item.MedicationCode)
+		if err != nil {
+			return 0, medicationError(err)
+		}
+		weight += medication.Weight * float32(item.Quantity)
+	}
+	return weight, nil
+}
+
+// packOrders is a first fit decreasing bin packing of the orders onto the drones that have the minimum
+// battery. The orders are taken by priority then the heaviest first, every order goes to the used drone
+// it leaves the least capacity in, and a new drone is only used when no used drone can take the order,
+// the biggest one first so the following orders fit in it too. A drone only takes an order it has the
+// battery to deliver with everything it carries, and only orders going to the same destination or
+// without one are packed together.
+func packOrders(orders []dispatchOrder, drones []repo.Drone, minBattery int, margin float64) DispatchPlan {
+	sort.SliceStable(orders, func(i, j int) bool {
+		a, b := orders[i], orders[j]
+		if a.order.Priority != b.order.Priority {
+			return a.order.Priority > b.order.Priority
+		}
+		if a.weight != b.weight {
+			return a.weight > b.weight
+		}
+		return a.order.ID < b.order.ID
+	})
+	free := []repo.Drone{}
+	for _, drone := range drones {
+		if drone.BatteryCapacity >= minBattery && drone.Weight > drone.CurrentPayload {
+			free = append(free, drone)
+		}
+	}
+	sort.SliceStable(free, func(i, j int) bool {
+		a, b := free[i], free[j]
+		if left := compareCapacity(a.Weight-a.CurrentPayload, b.Weight-b.CurrentPayload); left != 0 {
+			return left > 0
+		}
+		if a.BatteryCapacity != b.BatteryCapacity {
+			return a.BatteryCapacity > b.BatteryCapacity
+		}
+		return a.ID < b.ID
+	})
+
+	plan := DispatchPlan{Assignments: []DispatchAssignment{}, Unassigned: []int{}}
+	// used are the drones of the assignments as they will be with the orders packed so far
+	used := []repo.Drone{}
+	for _, order := range orders {
+		best := -1
+		for i, assignment := range plan.Assignments {
+			if assignment.Remaining >= order.weight && (best < 0 || assignment.Remaining < plan.Assignments[best].Remaining) &&
+				canDeliver(used[i], order.order, order.weight, margin) {
+				best = i
+			}
+		}
+		if best < 0 {
+			for i, drone := range free {
+				if drone.Weight-drone.CurrentPayload >= order.weight && canDeliver(drone, order.order, order.weight, margin) {
+					plan.Assignments = append(plan.Assignments, DispatchAssignment{
+						DroneID:   drone.ID,
+						Orders:    []int{},
+						Remaining: drone.Weight - drone.CurrentPayload,
+					})
+					used = append(used, drone)
+					free = append(free[:i], free[i+1:]...)
+					best = len(plan.Assignments) - 1
+					break
+				}
+			}
+		}
+		if best < 0 {
+			plan.Unassigned = append(plan.Unassigned, order.order.ID)
+			continue
+		}
+		plan.Assignments[best].Orders = append(plan.Assignments[best].Orders, order.order.ID)
+		plan.Assignments[best].Weight += order.weight
+		plan.Assignments[best].Remaining -= order.weight
+		used[best].CurrentPayload += order.weight
+		if used[best].DestinationLatitude == nil {
+			used[best].DestinationLatitude, used[best].DestinationLongitude = order.order.DestinationLatitude, order.order.DestinationLongitude
+		}
+	}
+	sort.Ints(plan.Unassigned)
+	plan.DronesUsed = len(plan.Assignments)
+	return plan
+}
@@ -0,0 +1,193 @@
+package usecase
+
+import (
+	repo "drone/v2/repository"
+	"reflect"
+	"testing"
+)
+
+func Test_packOrders(t *testing.T) {
+	order := func(id int, priority int, weight float32) dispatchOrder {
+		return dispatchOrder{order: repo.Order{ID: id, Priority: priority}, weight: weight}
+	}
+	farOrder := func(id int, weight float32, longitude float64) dispatchOrder {
+		return dispatchOrder{order: repo.Order{ID: id, DestinationLatitude: floatPointer(0), DestinationLongitude: &longitude}, weight: weight}
+	}
+	tests := []struct {
+		name   string
+		orders []dispatchOrder
+		drones []repo.Drone
+		want   DispatchPlan
+	}{
+		{
+			name:   "orders fill the biggest drone before using another one",
+			orders: []dispatchOrder{order(1, 0, 100), order(2, 0, 300), order(3, 0, 150), order(4, 0, 200)},
+			drones: []repo.Drone{
+				{ID: 1, Weight: 300, BatteryCapacity: 100},
+				{ID: 2, Weight: 500, BatteryCapacity: 100},
+				{ID: 3, Weight: 500, BatteryCapacity: 90},
+			},
+			want: DispatchPlan{
+				Assignments: []DispatchAssignment{
+					{DroneID: 2, Orders: []int{2, 4}, Weight: 500, Remaining: 0},
+					{DroneID: 3, Orders: []int{3, 1}, Weight: 250, Remaining: 250},
+				},
+				Unassigned: []int{},
+				DronesUsed: 2,
+			},
+		},
+		{
+			name:   "order goes to the used drone it fits best",
+			orders: []dispatchOrder{order(1, 0, 400), order(2, 0, 350), order(3, 0, 90)},
+			drones: []repo.Drone{
+				{ID: 1, Weight: 500, BatteryCapacity: 100},
+				{ID: 2, Weight: 500, BatteryCapacity: 100},
+			},
+			want: DispatchPlan{
+				Assignments: []DispatchAssignment{
+					{DroneID: 1, Orders: []int{1, 3}, Weight: 490, Remaining: 10},
+					{DroneID: 2, Orders: []int{2}, Weight: 350, Remaining: 150},
+				},
+				Unassigned: []int{},
+				DronesUsed: 2,
+			},
+		},
+		{
+			name:   "high priority orders take the capacity first",
+			orders: []dispatchOrder{order(1, 0, 300), order(2, 5, 250)},
+			drones: []repo.Drone{{ID: 1, Weight: 400, BatteryCapacity: 100}},
+			want: DispatchPlan{
+				Assignments: []DispatchAssignment{{DroneID: 1, Orders: []int{2}, Weight: 250, Remaining: 150}},
+				Unassigned:  []int{1},
+				DronesUsed:  1,
+			},
+		},
+		{
+			name:   "drones below the battery floor and loaded drones are left out",
+			orders: []dispatchOrder{order(1, 0, 100), order(2, 0, 100)},
+			drones: []repo.Drone{
+				{ID: 1, Weight: 500, BatteryCapacity: 24},
+				{ID: 2, Weight: 500, CurrentPayload: 450, BatteryCapacity: 100},
+				{ID: 3, Weight: 150, BatteryCapacity: 25},
+			},
+			want: DispatchPlan{
+				Assignments: []DispatchAssignment{{DroneID: 3, Orders: []int{1}, Weight: 100, Remaining: 50}},
+				Unassigned:  []int{2},
+				DronesUsed:  1,
+			},
+		},
+		{
+			// the order is 10km away, carrying 300 grams there takes drone 1 60% of its battery
+			name:   "order goes to the drone that can deliver it",
+			orders: []dispatchOrder{order(1, 0, 200), farOrder(2, 100, 0.09)},
+			drones: []repo.Drone{
+				{ID: 1, Weight: 500, BatteryCapacity: 50},
+				{ID: 2, Weight: 500, BatteryCapacity: 45},
+			},
+			want: DispatchPlan{
+				Assignments: []DispatchAssignment{
+					{DroneID: 1, Orders: []int{1}, Weight: 200, Remaining: 300},
+					{DroneID: 2, Orders: []int{2}, Weight: 100, Remaining: 400},
+				},
+				Unassigned: []int{},
+				DronesUsed: 2,
+			},
+		},
+		{
+			name:   "orders to another destination are not packed together",
+			orders: []dispatchOrder{farOrder(1, 100, 0.01), farOrder(2, 100, 0.02), farOrder(3, 100, 0.01), order(4, 0, 100)},
+			drones: []repo.Drone{
+				{ID: 1, Weight: 500, BatteryCapacity: 100},
+				{ID: 2, Weight: 500, BatteryCapacity: 100},
+			},
+			want: DispatchPlan{
+				Assignments: []DispatchAssignment{
+					{DroneID: 1, Orders: []int{1, 3, 4}, Weight: 300, Remaining: 200},
+					{DroneID: 2, Orders: []int{2}, Weight: 100, Remaining: 400},
+				},
+				Unassigned: []int{},
+				DronesUsed: 2,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := packOrders(tt.orders, tt.drones, 25, 0); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("packOrders() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_orderUsecase_Dispatch(t *testing.T) {
+	o, droneRepo, _ := newMemoryOrderUsecase(t,
+		repo.Drone{SerialNumber: "big", Weight: 500, State: StateIdle, BatteryCapacity: 100},
+		repo.Drone{SerialNumber: "small", Weight: 100, State: StateIdle, BatteryCapacity: 100},
+	)
+	for _, order := range []repo.Order{
+		{Destination: "a", Status: OrderPending, Items: []repo.OrderItem{{MedicationCode: "other", Quantity: 4}}},
+		{Destination: "b", Status: OrderPending, Items: []repo.OrderItem{{MedicationCode: "code", Quantity: 5}}},
+		{Destination: "c", Status: OrderPending, Items: []repo.OrderItem{{MedicationCode: "other", Quantity: 20}}},
+	} {
+		order := order
+		o.orderRepo.Create(&order)
+	}
+
+	plan, err := o.PlanDispatch()
+	want := DispatchPlan{
+		DryRun:      true,
+		Assignments: []DispatchAssignment{{DroneID: 1, Orders: []int{1, 2}, Weight: 250, Remaining: 250}},
+		Unassigned:  []int{3},
+		DronesUsed:  1,
+	}
+	if err != nil || !reflect.DeepEqual(plan, want) {
+		t.Fatalf("orderUsecase.PlanDispatch() = %+v, %v, want %+v", plan, err, want)
+	}
+	if drone, _ := droneRepo.Get(1); drone.State != StateIdle {
+		t.Errorf("orderUsecase.PlanDispatch() moved the drone to %v", drone.State)
+	}
+
+	plan, err = o.Dispatch()
+	want.DryRun = false
+	if err != nil || !reflect.DeepEqual(plan, want) {
+		t.Fatalf("orderUsecase.Dispatch() = %+v, %v, want %+v", plan, err, want)
+	}
+	for id, status := range map[int]string{1: OrderAssigned, 2: OrderAssigned, 3: OrderPending} {
+		if order, _ := o.orderRepo.Get(id); order.Status != status {
+			t.Errorf("order %d status = %v, want %v", id, order.Status, status)
+		}
+	}
+	if drone, _ := droneRepo.Get(1); drone.State != StateLoading {
+		t.Errorf("dispatched drone state = %v, want %v", drone.State, StateLoading)
+	}
+}
+
+func Test_orderUsecase_Dispatch_Destinations(t *testing.T) {
+	o, droneRepo, _ := newMemoryOrderUsecase(t, repo.Drone{SerialNumber: "big", Weight: 500, State: StateIdle, BatteryCapacity: 100})
+	for _, longitude := range []float64{0.01, 0.02} {
+		longitude := longitude
+		o.orderRepo.Create(&repo.Order{
+			Status:               OrderPending,
+			DestinationLatitude:  floatPointer(0),
+			DestinationLongitude: &longitude,
+			Items:                []repo.OrderItem{{MedicationCode: "code", Quantity: 1}},
+		})
+	}
+
+	plan, err := o.Dispatch()
+	want := DispatchPlan{
+		Assignments: []DispatchAssignment{{DroneID: 1, Orders: []int{1}, Weight: 10, Remaining: 490}},
+		Unassigned:  []int{2},
+		DronesUsed:  1,
+	}
+	if err != nil || !reflect.DeepEqual(plan, want) {
+		t.Fatalf("orderUsecase.Dispatch() = %+v, %v, want %+v", plan, err, want)
+	}
+	if drone, _ := droneRepo.Get(1); drone.DestinationLongitude == nil || *drone.DestinationLongitude != 0.01 {
+		t.Errorf("drone destination = %v, want the destination of order 1", drone.DestinationLongitude)
+	}
+	// the order to the other destination waits for a drone of its own
+	if order, _ := o.orderRepo.Get(2); order.Status != OrderPending {
+		t.Errorf("order 2 status = %v, want %v", order.Status, OrderPending)
+	}
+}
@@ -0,0 +1,268 @@
+package usecase
+
+import (
+	repo "drone/v2/repository"
+	"drone/v2/settings"
+	"drone/v2/utils"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/asaskevich/govalidator"
+)
+
+var (
+	ErrDockNotFound   = errors.New("dock is not exist")
+	ErrDockFull       = errors.New("all slots of the dock are taken")
+	ErrDroneDocked    = errors.New("drone is already docked")
+	ErrDroneNotDocked = errors.New("drone is not docked")
+)
+
+// dockStates are the states a drone is at the base in, only they can be docked.
+var dockStates = map[string]bool{
+	StateIdle:    true,
+	StateLoading: true,
+	StateLoaded:  true,
+}
+
+type DockStateError struct {
+	DroneID int
+	State   string
+}
+
+func (e *DockStateError) Error() string {
+	return fmt.Sprintf("drone %d can not be docked in %s state", e.DroneID, e.State)
+}
+
+type IDockUsecase interface {
+	RegisterDock(object DockObject) (int, error)
+	GetDock(id int) (DockDetails, error)
+	ListDocks() ([]DockDetails, error)
+	DockDrone(droneID int, dockID int) (DroneDetails, error)
+	UndockDrone(droneID int) (DroneDetails, error)
+	ChargeDockedDrones(elapsed time.Duration)
+}
+
+type dockUsecase struct {
+	dockRepo          repo.IDockRepository
+	droneRepo         repo.IDroneRepository
+	drones            IDroneUsecase
+	logRepo           repo.ILogRepository
+	minLoadingBattery int
+	defaultChargeRate float64
+	// docking serializes docking requests so two drones can not take the last slot together
+	docking   sync.Mutex
+	batteries *batteryMeter
+	// flagged are the drones already logged as needing charging
+	flagged map[int]bool
+}
+
+func NewDockUsecase(dock repo.IDockRepository, d repo.IDroneRepository, drones IDroneUsecase, l repo.ILogRepository, battery settings.Battery) IDockUsecase {
+	return &dockUsecase{
+		dockRepo:          dock,
+		droneRepo:         d,
+		drones:            drones,
+		logRepo:           l,
+		minLoadingBattery: battery.MinLoadingLevel,
+		defaultChargeRate: battery.RechargeRate,
+		batteries:         newBatteryMeter(),
+		flagged:           map[int]bool{},
+	}
+}
+
+func (d *dockUsecase) RegisterDock(object DockObject) (int, error) {
+	dockValidate, err := govalidator.ValidateStruct(object)
+	if err != nil || !dockValidate {
+		return 0, err
+	}
+	if err := validateLocation(object.Latitude, object.Longitude); err != nil {
+		return 0, err
+	}
+	data, err := utils.TypeConverter[repo.Dock](&object)
+	if err != nil {
+		return 0, err
+	}
+	if data.ChargeRate == 0 {
+		data.ChargeRate = d.defaultChargeRate
+	}
+	return d.dockRepo.Create(data)
+}
+
+func (d *dockUsecase) GetDock(id int) (DockDetails, error) {
+	dock, err := d.dockRepo.Get(id)
+	if err != nil {
+		return DockDetails{}, dockError(err)
+	}
+	return d.dockDetails(dock)
+}
+
+func (d *dockUsecase) ListDocks() ([]DockDetails, error) {
+	docks, err := d.dockRepo.List()
+	if err != nil {
+		return nil, err
+	}
+	details := make([]DockDetails, 0, len(docks))
+	for _, dock := range docks {
+		detail, err := d.dockDetails(dock)
+		if err != nil {
+			return nil, err
+		}
+		details = append(details, detail)
+	}
+	return details, nil
+}
+
+// DockDrone puts the drone in a free slot of the dock, only drones at the base can be docked.
+func (d *dockUsecase) DockDrone(droneID int, dockID int) (DroneDetails, error) {
+	d.docking.Lock()
+	defer d.docking.Unlock()
+	dock, err := d.dockRepo.Get(dockID)
+	if err != nil {
+		return DroneDetails{}, dockError(err)
+	}
+	drone, err := d.droneRepo.Get(droneID)
+	if err != nil {
+		return DroneDetails{}, droneError(err)
+	}
+	if !dockStates[drone.State] {
+		return DroneDetails{}, &DockStateError{DroneID: droneID, State: drone.State}
+	}
+	if err := d.droneRepo.Dock(droneID, dockID, dock.Slots); err != nil {
+		return DroneDetails{}, dockingError(err)
+	}
+	return d.droneDetails(droneID)
+}
+
+func (d *dockUsecase) UndockDrone(droneID int) (DroneDetails, error) {
+	if err := d.droneRepo.Undock(droneID); err != nil {
+		return DroneDetails{}, dockingError(err)
+	}
+	return d.droneDetails(droneID)
+}
+
+// ChargeDockedDrones charges the drones in every dock by the dock charge rate for the elapsed time,
+// then flags the drones out of the docks whose battery is below the minimum loading level.
+func (d *dockUsecase) ChargeDockedDrones(elapsed time.Duration) {
+	docks, err := d.dockRepo.List()
+	if err != nil {
+		log.Println(err.Error())
+		return
+	}
+	d.batteries.mu.Lock()
+	defer d.batteries.mu.Unlock()
+	for _, dock := range docks {
+		if err := d.charge(dock, elapsed); err != nil {
+			log.Println(err.Error())
+		}
+	}
+	if err := d.flagDrones(); err != nil {
+		log.Println(err.Error())
+	}
+}
+
+func (d *dockUsecase) charge(dock repo.Dock, elapsed time.Duration) error {
+	drones, _, err := d.droneRepo.List(repo.DroneFilter{DockID: dock.ID})
+	if err != nil {
+		return err
+	}
+	levels := map[int]int{}
+	exact := map[int]float64{}
+	drains := map[int]float64{}
+	for _, drone := range drones {
+		previous := d.batteries.level(drone)
+		level := math.Min(100, previous+dock.ChargeRate*elapsed.Minutes())
+		exact[drone.ID] = level
+		drains[drone.ID] = drainRate(previous, level, elapsed)
+		if rounded := int(math.Round(level)); rounded != drone.BatteryCapacity {
+			levels[drone.ID] = rounded
+		}
+	}
+	if err := d.droneRepo.UpdateBatteries(levels, fmt.Sprintf("charging at dock %d", dock.ID)); err != nil {
+		return err
+	}
+	for id, level := range exact {
+		d.batteries.levels[id] = level
+	}
+	// the charged levels are published with a negative drain
+	for _, drone := range drones {
+		if level, changed := levels[drone.ID]; changed {
+			d.drones.BatteryChanged(drone, level, drains[drone.ID])
+		}
+	}
+	return nil
+}
+
+// flagDrones logs every drone that is not docked and can not be loaded with its battery anymore,
+// a drone is logged once until it is charged above the minimum loading level again.
+func (d *dockUsecase) flagDrones() error {
+	drones, _, err := d.droneRepo.List(repo.DroneFilter{})
+	if err != nil {
+		return err
+	}
+	for _, drone := range drones {
+		if drone.DockID != nil || drone.BatteryCapacity >= d.minLoadingBattery {
+			delete(d.flagged, drone.ID)
+			continue
+		}
+		if d.flagged[drone.ID] {
+			continue
+		}
+		if err := d.logRepo.Create(repo.Log{
+			DroneID:         drone.ID,
+			BatteryCapacity: drone.BatteryCapacity,
+			DroneState:      drone.State,
+			Description:     fmt.Sprintf("battery below %d%%, drone needs charging", d.minLoadingBattery),
+		}); err != nil {
+			return err
+		}
+		d.flagged[drone.ID] = true
+	}
+	return nil
+}
+
+func (d *dockUsecase) dockDetails(dock repo.Dock) (DockDetails, error) {
+	drones, _, err := d.droneRepo.List(repo.DroneFilter{DockID: dock.ID})
+	if err != nil {
+		return DockDetails{}, err
+	}
+	details := DockDetails{Dock: dock, Drones: make([]int, 0, len(drones))}
+	for _, drone := range drones {
+		details.Drones = append(details.Drones, drone.ID)
+	}
+	details.FreeSlots = dock.Slots - len(drones)
+	if details.FreeSlots < 0 {
+		details.FreeSlots = 0
+	}
+	return details, nil
+}
+
+func (d *dockUsecase) droneDetails(droneID int) (DroneDetails, error) {
+	drone, err := d.droneRepo.Get(droneID)
+	if err != nil {
+		return DroneDetails{}, droneError(err)
+	}
+	return newDroneDetails(drone, d.minLoadingBattery), nil
+}
+
+func dockError(err error) error {
+	if errors.Is(err, repo.ErrRecordNotFound) {
+		return ErrDockNotFound
+	}
+	return err
+}
+
+// dockingError maps the errors of docking and undocking a drone.
+func dockingError(err error) error {
+	switch {
+	case errors.Is(err, repo.ErrDockFull):
+		return ErrDockFull
+	case errors.Is(err, repo.ErrAlreadyDocked):
+		return ErrDroneDocked
+	case errors.Is(err, repo.ErrNotDocked):
+		return ErrDroneNotDocked
+	}
+	return droneError(err)
+}
@@ -0,0 +1,160 @@
+package usecase
+
+import (
+	"drone/v2/clock"
+	repo "drone/v2/repository"
+	"drone/v2/settings"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newMemoryDockUsecase(t *testing.T, drones ...repo.Drone) (*dockUsecase, repo.IDroneRepository, repo.ILogRepository) {
+	t.Helper()
+	logRepo := repo.NewMemoryLogRepository(clock.System)
+	droneRepo := repo.NewMemoryDroneRepo(logRepo)
+	createDrones(t, droneRepo, drones...)
+	battery := settings.Battery{MinLoadingLevel: 25, RechargeRate: 2}
+	droneUsecase := NewDroneUsecase(droneRepo, repo.NewMemoryMedicationRepo(), battery, clock.System, nil, nil)
+	d := NewDockUsecase(repo.NewMemoryDockRepo(), droneRepo, droneUsecase, logRepo, battery)
+	return d.(*dockUsecase), droneRepo, logRepo
+}
+
+func Test_dockUsecase_RegisterDock(t *testing.T) {
+	tests := []struct {
+		name           string
+		object         DockObject
+		wantErr        bool
+		wantChargeRate float64
+	}{
+		{
+			name:           "test register dock",
+			object:         DockObject{Name: "base", Latitude: 30.04, Longitude: 31.23, Slots: 2, ChargeRate: 5},
+			wantChargeRate: 5,
+		},
+		{
+			name:           "test register dock with the default charge rate",
+			object:         DockObject{Name: "base", Slots: 2},
+			wantChargeRate: 2,
+		},
+		{
+			name:    "test register dock without slots",
+			object:  DockObject{Name: "base"},
+			wantErr: true,
+		},
+		{
+			name:    "test register dock out of the map",
+			object:  DockObject{Name: "base", Latitude: 91, Slots: 2},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, _, _ := newMemoryDockUsecase(t)
+			id, err := d.RegisterDock(tt.object)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("dockUsecase.RegisterDock() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			dock, err := d.GetDock(id)
+			if err != nil || dock.ChargeRate != tt.wantChargeRate || dock.FreeSlots != tt.object.Slots {
+				t.Errorf("dockUsecase.GetDock() = %+v, %v, want charge rate %v", dock, err, tt.wantChargeRate)
+			}
+		})
+	}
+}
+
+func Test_dockUsecase_DockDrone(t *testing.T) {
+	d, _, _ := newMemoryDockUsecase(t,
+		repo.Drone{SerialNumber: "serial 1", State: StateIdle},
+		repo.Drone{SerialNumber: "serial 2", State: StateLoaded},
+		repo.Drone{SerialNumber: "serial 3", State: StateDelivering},
+	)
+	dockID, _ := d.RegisterDock(DockObject{Name: "base", Slots: 1})
+	tests := []struct {
+		name    string
+		droneID int
+		dockID  int
+		wantErr error
+	}{
+		{name: "test dock idle drone", droneID: 1, dockID: dockID},
+		{name: "test dock drone twice", droneID: 1, dockID: dockID, wantErr: ErrDroneDocked},
+		{name: "test dock in full dock", droneID: 2, dockID: dockID, wantErr: ErrDockFull},
+		{name: "test dock in missing dock", droneID: 2, dockID: 100, wantErr: ErrDockNotFound},
+		{name: "test dock missing drone", droneID: 100, dockID: dockID, wantErr: ErrDroneNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			drone, err := d.DockDrone(tt.droneID, tt.dockID)
+			if err != tt.wantErr {
+				t.Fatalf("dockUsecase.DockDrone() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (drone.DockID == nil || *drone.DockID != tt.dockID) {
+				t.Errorf("dockUsecase.DockDrone() = %+v, want docked in %d", drone, tt.dockID)
+			}
+		})
+	}
+
+	var stateErr *DockStateError
+	if _, err := d.DockDrone(3, dockID); !errors.As(err, &stateErr) {
+		t.Errorf("dockUsecase.DockDrone() of a flying drone error = %v, want DockStateError", err)
+	}
+	if dock, _ := d.GetDock(dockID); len(dock.Drones) != 1 || dock.FreeSlots != 0 {
+		t.Errorf("dockUsecase.GetDock() = %+v, want drone 1 in the only slot", dock)
+	}
+	if _, err := d.UndockDrone(1); err != nil {
+		t.Errorf("dockUsecase.UndockDrone() error = %v", err)
+	}
+	if _, err := d.UndockDrone(1); err != ErrDroneNotDocked {
+		t.Errorf("dockUsecase.UndockDrone() twice error = %v, want %v", err, ErrDroneNotDocked)
+	}
+	if _, err := d.DockDrone(2, dockID); err != nil {
+		t.Errorf("dockUsecase.DockDrone() in the freed slot error = %v", err)
+	}
+}
+
+func Test_dockUsecase_ChargeDockedDrones(t *testing.T) {
+	d, droneRepo, logRepo := newMemoryDockUsecase(t,
+		repo.Drone{SerialNumber: "docked", State: StateIdle, BatteryCapacity: 20},
+		repo.Drone{SerialNumber: "full", State: StateIdle, BatteryCapacity: 99},
+		repo.Drone{SerialNumber: "low", State: StateIdle, BatteryCapacity: 10},
+	)
+	dockID, _ := d.RegisterDock(DockObject{Name: "base", Slots: 2, ChargeRate: 1.5})
+	d.DockDrone(1, dockID)
+	d.DockDrone(2, dockID)
+
+	// 1.5% a minute takes the docked drone a percent and a half every minute
+	want := map[int][]int{
+		1: {22, 23, 25, 26},
+		2: {100, 100, 100, 100},
+		3: {10, 10, 10, 10},
+	}
+	for minute := 0; minute < 4; minute++ {
+		d.ChargeDockedDrones(time.Minute)
+		for id, levels := range want {
+			drone, _ := droneRepo.Get(id)
+			if drone.BatteryCapacity != levels[minute] {
+				t.Errorf("minute %d battery of %s = %v, want %v", minute+1, drone.SerialNumber, drone.BatteryCapacity, levels[minute])
+			}
+		}
+	}
+
+	logs, _ := logRepo.List()
+	var charging, flagged int
+	for _, log := range logs {
+		switch log.Description {
+		case "charging at dock 1":
+			charging++
+		case "battery below 25%, drone needs charging":
+			if log.DroneID != 3 {
+				t.Errorf("flagged drone %d, want only the low drone out of the dock", log.DroneID)
+			}
+			flagged++
+		}
+	}
+	if charging != 5 || flagged != 1 {
+		t.Errorf("logs = %d charging steps and %d flags, want 5 and 1", charging, flagged)
+	}
+}
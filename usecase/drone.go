@@ -1,8 +1,10 @@
 package usecase
 
 import (
+	"drone/v2/clock"
 	repo "drone/v2/repository"
 	repoEnity "drone/v2/repository"
+	"drone/v2/settings"
 	"drone/v2/utils"
 	"errors"
 	"fmt"
@@ -14,21 +16,61 @@ import (
 
 type IDroneUsecase interface {
 	RegisterDrone(object DorneObject) (int, error)
-	LoadingMedication(id int, medication MedicationObject) error
+	GetDrone(id int) (DroneDetails, error)
+	ListDrones(query DroneQuery) (DronePage, error)
+	UpdateDrone(id int, object DroneUpdateObject) (DroneDetails, error)
+	DecommissionDrone(id int) error
+	LoadingMedication(id int, load LoadObject) (LoadingJob, error)
+	GetLoadingJob(id int) (LoadingJob, error)
+	CancelLoadingJobs(droneID int, jobIDs []int) error
 	CheckLoadingMedication(id int) (string, error)
-	CheckAvailableDroneForLoading() []repo.Drone
+	CheckAvailableDroneForLoading() []DroneDetails
+	RecalculatePayload(id int) (DroneDetails, error)
+	UnloadMedication(id int, code string) (DroneDetails, error)
+	UnloadDrone(id int) (DroneDetails, error)
 	CheckBatteryLevel(id int) (string, error)
-	CheckDronesBatteries()
+	CheckDronesBatteries(elapsed time.Duration)
+	BatteryChanged(drone repo.Drone, level int, drain float64)
+	ChangeDroneState(id int, state string) error
+	StartDelivery(id int) error
+	MarkDelivered(id int) error
+	ReturnToBase(id int) error
+	UpdatePosition(id int, location LocationObject) (DroneDetails, error)
+	SetDestination(id int, location LocationObject) (DroneDetails, error)
+	ListDronesNearby(query NearbyQuery) ([]NearbyDrone, error)
+	CanReach(id int, location LocationObject) (Feasibility, error)
 }
 
 type droneUsecase struct {
-	droneRepo repo.IDroneRepository
+	droneRepo          repo.IDroneRepository
+	medicationRepo     repo.IMedicationRepository
+	jobs               *loadingQueue
+	loadingTimePerGram time.Duration
+	minLoadingBattery  int
+	criticalBattery    int
+	safetyMargin       float64
+	batteries          *batteryMeter
+	// commands sends the drones the commands of their transitions, nil when they are not sent
+	commands ICommandPublisher
+	// events gets the events of the drones, nil when they are not published
+	events IEventBus
 }
 
-func NewDroneUsecase(d repo.IDroneRepository) IDroneUsecase {
-	return &droneUsecase{
-		droneRepo: d,
+func NewDroneUsecase(d repo.IDroneRepository, m repo.IMedicationRepository, battery settings.Battery, clock clock.Clock, commands ICommandPublisher, events IEventBus) IDroneUsecase {
+	usecase := &droneUsecase{
+		droneRepo:          d,
+		medicationRepo:     m,
+		jobs:               newLoadingQueue(loadingQueueSize, clock),
+		loadingTimePerGram: loadingTimePerGram,
+		minLoadingBattery:  battery.MinLoadingLevel,
+		criticalBattery:    battery.CriticalLevel,
+		safetyMargin:       battery.SafetyMargin,
+		batteries:          newBatteryMeter(),
+		commands:           commands,
+		events:             events,
 	}
+	usecase.jobs.start(loadingWorkers, usecase.loadMedication, usecase.finishLoading)
+	return usecase
 }
 
 func (d *droneUsecase) RegisterDrone(object DorneObject) (int, error) {
@@ -36,45 +78,94 @@ func (d *droneUsecase) RegisterDrone(object DorneObject) (int, error) {
 	if err != nil || !droneValidate {
 		return 0, err
 	}
+	// a new drone has nothing loaded, so it can only start IDLE
+	if object.State != "" && object.State != StateIdle {
+		return 0, errors.New(fmt.Sprintf("drone can not be registered in %s state, it starts %s", object.State, StateIdle))
+	}
+	if err := validateLocation(object.HomeLatitude, object.HomeLongitude); err != nil {
+		return 0, err
+	}
 	data, err := utils.TypeConverter[repo.Drone](&object)
 	if err != nil {
 		log.Println(err.Error())
 	}
-	return d.droneRepo.Create(data)
+	data.Latitude, data.Longitude = data.HomeLatitude, data.HomeLongitude
+	id, err := d.droneRepo.Create(data)
+	if err != nil {
+		return id, err
+	}
+	d.publishEvent(EventDroneRegistered, id, DroneRegisteredEvent{SerialNumber: data.SerialNumber, Model: data.Model})
+	return id, nil
 }
 
-func (d *droneUsecase) LoadingMedication(id int, medication MedicationObject) error {
+// LoadingMedication validates the load and moves the drone to LOADING, the medication
+// itself is loaded in the background by the returned job.
+func (d *droneUsecase) LoadingMedication(id int, load LoadObject) (LoadingJob, error) {
+	unlock := d.jobs.lockDrone(id)
+	defer unlock()
 	drone, err := d.droneRepo.Get(id)
 	if err != nil {
-		return err
+		return LoadingJob{}, droneError(err)
 	}
-	err = utils.ValidateMedicationName(medication.Name)
+	loadValidate, err := govalidator.ValidateStruct(load)
+	if err != nil || !loadValidate {
+		return LoadingJob{}, err
+	}
+	if load.Quantity == 0 {
+		load.Quantity = 1
+	}
+	medication, err := d.medicationRepo.Get(load.Code)
 	if err != nil {
-		return err
+		return LoadingJob{}, medicationError(err)
+	}
+	weight := medication.Weight * float32(load.Quantity)
+	// medications still waiting in the loading queue are already reserved on the drone
+	drone.CurrentPayload += d.jobs.pendingWeight(id)
+	if err := validateDroneForLoadingMedication(drone, weight, d.minLoadingBattery); err != nil {
+		return LoadingJob{}, err
 	}
-	medicationValidate, err := govalidator.ValidateStruct(medication)
-	if err != nil || !medicationValidate {
-		return err
+	if err := d.jobs.reserve(); err != nil {
+		return LoadingJob{}, err
 	}
-	if err := validateDroneForLoadingMedication(drone, medication.Weight); err == nil {
-		data, err := utils.TypeConverter[repo.Medication](&medication)
-		if err != nil {
-			log.Println(err.Error())
+	if drone.State != StateLoading {
+		if err := d.transition(drone, StateLoading); err != nil {
+			d.jobs.release()
+			return LoadingJob{}, err
 		}
-		// simulation Loading item time based on medication weight into drone
-		// Let now be Fixed time
-		time.Sleep(5 * time.Second)
-		return d.droneRepo.AddMedication(id, data)
 	}
-	return err
+	job := d.jobs.add(LoadingJob{
+		DroneID:   id,
+		Code:      medication.Code,
+		Quantity:  load.Quantity,
+		LotNumber: load.LotNumber,
+		Weight:    weight,
+	})
+	d.jobs.push(job.ID)
+	return job, nil
 }
 
 func (d *droneUsecase) CheckLoadingMedication(id int) (string, error) {
 	return d.droneRepo.CheckLoadingMedication(id)
 }
 
-func (d *droneUsecase) CheckAvailableDroneForLoading() []repo.Drone {
-	return d.droneRepo.AvailableDroneForLoading()
+func (d *droneUsecase) CheckAvailableDroneForLoading() []DroneDetails {
+	drones := d.droneRepo.AvailableDroneForLoading()
+	details := make([]DroneDetails, 0, len(drones))
+	for _, drone := range drones {
+		details = append(details, newDroneDetails(drone, d.minLoadingBattery))
+	}
+	return details
+}
+
+func (d *droneUsecase) RecalculatePayload(id int) (DroneDetails, error) {
+	if _, err := d.droneRepo.RecalculatePayload(id); err != nil {
+		return DroneDetails{}, droneError(err)
+	}
+	drone, err := d.droneRepo.Get(id)
+	if err != nil {
+		return DroneDetails{}, droneError(err)
+	}
+	return newDroneDetails(drone, d.minLoadingBattery), nil
 }
 
 func (d *droneUsecase) CheckBatteryLevel(id int) (string, error) {
@@ -86,9 +177,12 @@ func (d *droneUsecase) CheckBatteryLevel(id int) (string, error) {
 	return batteryFormat, nil
 }
 
-func validateDroneForLoadingMedication(drone repoEnity.Drone, weight float32) error {
-	if drone.BatteryCapacity < 25 {
-		errorMsg := fmt.Sprintf(`drone can not be loaded because battery capacity less that %d`, drone.BatteryCapacity)
+func validateDroneForLoadingMedication(drone repoEnity.Drone, weight float32, minBattery int) error {
+	if drone.Grounded {
+		return ErrDroneGrounded
+	}
+	if drone.BatteryCapacity < minBattery {
+		errorMsg := fmt.Sprintf(`drone can not be loaded because battery capacity less than %d`, minBattery)
 		return errors.New(errorMsg)
 	}
 	if drone.CurrentPayload+weight > drone.Weight {
@@ -97,8 +191,3 @@ func validateDroneForLoadingMedication(drone repoEnity.Drone, weight float32) er
 	}
 	return nil
 }
-
-func (d *droneUsecase) CheckDronesBatteries() {
-	d.droneRepo.ReduceBatteries()
-
-}
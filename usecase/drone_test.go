@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"bytes"
+	"drone/v2/clock"
 	repo "drone/v2/repository"
 	repoEnity "drone/v2/repository"
 	mosks "drone/v2/repository/mocks"
@@ -179,6 +180,22 @@ func Test_droneUsecase_Register(t *testing.T) {
 			wantErr:     true,
 			ErrorMsgExp: "state: State not exist does not validate as matches.*",
 		},
+		{
+			name: "test cant not register drone that is not idle",
+			d:    &droneUsecase{droneRepo: mosks.NewDroneRepoMock()},
+			args: args{
+				object: DorneObject{
+					SerialNumber: generateRandomSerialNumber(50),
+					Model:        "Lightweight",
+					State:        "LOADED",
+					Weight:       250,
+					Battery:      100,
+				},
+			},
+			want:        0,
+			wantErr:     true,
+			ErrorMsgExp: "^drone can not be registered in LOADED state, it starts IDLE$",
+		},
 		{
 			name: "test cant not register drone with negative battery charge",
 			d:    &droneUsecase{droneRepo: mosks.NewDroneRepoMock()},
@@ -211,6 +228,21 @@ func Test_droneUsecase_Register(t *testing.T) {
 			wantErr:     true,
 			ErrorMsgExp: "battery: [0-9]* does not validate as range.*",
 		},
+		{
+			name: "test register drone without state",
+			d:    &droneUsecase{droneRepo: mosks.NewDroneRepoMock()},
+			args: args{
+				object: DorneObject{
+					SerialNumber: generateRandomSerialNumber(50),
+					Model:        "Lightweight",
+					Weight:       250,
+					Battery:      100,
+				},
+			},
+			want:        1,
+			wantErr:     false,
+			ErrorMsgExp: "",
+		},
 		{
 			name: "test register drone successfully",
 			d:    &droneUsecase{droneRepo: mosks.NewDroneRepoMock()},
@@ -268,8 +300,8 @@ func getModelsAsstring(m map[string]string) string {
 
 func Test_droneUsecase_LoadingMedication(t *testing.T) {
 	type args struct {
-		id         int
-		medication MedicationObject
+		id   int
+		load LoadObject
 	}
 	tests := []struct {
 		name     string
@@ -279,134 +311,111 @@ func Test_droneUsecase_LoadingMedication(t *testing.T) {
 		errorMsg string
 	}{
 		{
-			name: "test can not register medication without name",
+			name: "test can not load medication without code",
 			d: &droneUsecase{
-				droneRepo: mosks.NewDroneRepoMock(),
+				droneRepo:      mosks.NewDroneRepoMock(),
+				medicationRepo: mosks.NewMedicationRepoMock(),
+				jobs:           newLoadingQueue(loadingQueueSize, clock.System),
 			},
 			args: args{
 				id: 1,
-				medication: MedicationObject{
-					Code:   "code",
-					Weight: 10,
+				load: LoadObject{
+					Quantity: 1,
 				},
 			},
 			wantErr:  true,
-			errorMsg: "Medication name is not provided",
+			errorMsg: "Medication code is not provided",
 		},
 		{
-			name: "test can not register medication with invaild name format",
+			name: "test can not load medication that not exist in catalog",
 			d: &droneUsecase{
-				droneRepo: mosks.NewDroneRepoMock(),
+				droneRepo:      mosks.NewDroneRepoMock(),
+				medicationRepo: mosks.NewMedicationRepoMock(),
+				jobs:           newLoadingQueue(loadingQueueSize, clock.System),
 			},
 			args: args{
 				id: 1,
-				medication: MedicationObject{
-					Name:   "###test",
-					Code:   "code",
-					Weight: 10,
+				load: LoadObject{
+					Code:     "not exist",
+					Quantity: 1,
 				},
 			},
 			wantErr:  true,
-			errorMsg: "invald format for medciation name, can be only combination of (letters, numbers, -, _)",
+			errorMsg: ErrMedicationNotFound.Error(),
 		},
 		{
-			name: "test can not register medication without code",
+			name: "test can not load medication with quantity more than 100",
 			d: &droneUsecase{
-				droneRepo: mosks.NewDroneRepoMock(),
+				droneRepo:      mosks.NewDroneRepoMock(),
+				medicationRepo: mosks.NewMedicationRepoMock(),
+				jobs:           newLoadingQueue(loadingQueueSize, clock.System),
 			},
 			args: args{
 				id: 1,
-				medication: MedicationObject{
-					Name:   "test",
-					Weight: 10,
+				load: LoadObject{
+					Code:     "code",
+					Quantity: 101,
 				},
 			},
 			wantErr:  true,
-			errorMsg: "Medication code is not provided",
+			errorMsg: "quantity: 101 does not validate as range(1|100)",
 		},
 		{
-			name: "test can not register medication without weight",
+			name: "test can not load medication quantity heavier than drone weight",
 			d: &droneUsecase{
-				droneRepo: mosks.NewDroneRepoMock(),
+				droneRepo:      mosks.NewDroneRepoMock(),
+				medicationRepo: mosks.NewMedicationRepoMock(),
+				jobs:           newLoadingQueue(loadingQueueSize, clock.System),
 			},
 			args: args{
 				id: 1,
-				medication: MedicationObject{
-					Name: "test",
-					Code: "code",
+				load: LoadObject{
+					Code:     "code",
+					Quantity: 51,
 				},
 			},
 			wantErr:  true,
-			errorMsg: "Medication weight is not provided",
+			errorMsg: fmt.Sprintf(`drone can not be loaded with %f weight, because current weight is %f and Max weight is %f`, 510.0, 0.0, 500.0),
 		},
 		{
-			name: "test can not register medication without mandatory data",
+			name: "test can not load medication on drone that not exist",
 			d: &droneUsecase{
-				droneRepo: mosks.NewDroneRepoMock(),
-			},
-			args: args{
-				id:         1,
-				medication: MedicationObject{},
-			},
-			wantErr:  true,
-			errorMsg: "Medication code is not provided;Medication name is not provided;Medication weight is not provided",
-		},
-		{
-			name: "test medication image field should be vaild url format",
-			d: &droneUsecase{
-				droneRepo: mosks.NewDroneRepoMock(),
+				droneRepo:      repo.NewMemoryDroneRepo(repo.NewMemoryLogRepository(clock.System)),
+				medicationRepo: mosks.NewMedicationRepoMock(),
+				jobs:           newLoadingQueue(loadingQueueSize, clock.System),
 			},
 			args: args{
-				id: 1,
-				medication: MedicationObject{
-					Name:   "test",
-					Code:   "code",
-					Weight: 10,
-					Image:  "invaild _url_format",
+				id: 2,
+				load: LoadObject{
+					Code:     "code",
+					Quantity: 1,
 				},
 			},
 			wantErr:  true,
-			errorMsg: "image: invaild _url_format does not validate as url",
+			errorMsg: ErrDroneNotFound.Error(),
 		},
 		{
-			name: "test register medication successfully",
+			name: "test load medication by code successfully",
 			d: &droneUsecase{
-				droneRepo: mosks.NewDroneRepoMock(),
+				droneRepo:      mosks.NewDroneRepoMock(),
+				medicationRepo: mosks.NewMedicationRepoMock(),
+				jobs:           newLoadingQueue(loadingQueueSize, clock.System),
 			},
 			args: args{
 				id: 1,
-				medication: MedicationObject{
-					Name:   "name123_jhh-",
-					Code:   "code",
-					Weight: 10,
-					Image:  "http://test/image",
+				load: LoadObject{
+					Code:      "code",
+					Quantity:  3,
+					LotNumber: "lot-1",
 				},
 			},
 			wantErr:  false,
 			errorMsg: "",
 		},
-		{
-			name: "test cant not register medication with weight less that 1",
-			d: &droneUsecase{
-				droneRepo: mosks.NewDroneRepoMock(),
-			},
-			args: args{
-				id: 1,
-				medication: MedicationObject{
-					Name:   "name",
-					Code:   "code",
-					Weight: -1,
-					Image:  "http://test/image",
-				},
-			},
-			wantErr:  true,
-			errorMsg: "weight: -1 does not validate as range(1|500)",
-		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// d := &droneUsecase{}
-			if err := tt.d.LoadingMedication(tt.args.id, tt.args.medication); (err != nil) != tt.wantErr {
+			if _, err := tt.d.LoadingMedication(tt.args.id, tt.args.load); (err != nil) != tt.wantErr {
 				t.Errorf("droneUsecase.LoadingMedication() error = %v, wantErr %v", err, tt.wantErr)
 			} else if err != nil && tt.errorMsg != err.Error() {
 				t.Errorf("droneUsecase.LoadingMedication() error = %v, wantErr %v", err.Error(), tt.errorMsg)
@@ -450,12 +459,12 @@ func Test_validateDroneForLoadingMedication(t *testing.T) {
 				weight: 50,
 			},
 			wantErr: true,
-			wantMsg: fmt.Sprintf(`drone can not be loaded because battery capacity less that %d`, 24),
+			wantMsg: fmt.Sprintf(`drone can not be loaded because battery capacity less than %d`, 25),
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := validateDroneForLoadingMedication(tt.args.drone, tt.args.weight); (err != nil) != tt.wantErr {
+			if err := validateDroneForLoadingMedication(tt.args.drone, tt.args.weight, 25); (err != nil) != tt.wantErr {
 				t.Errorf("validateDroneForLoadingMedication() error = %v, wantErr %v", err, tt.wantErr)
 			} else if err != nil && err.Error() != tt.wantMsg {
 				t.Errorf("validateDroneForLoadingMedication() error = %v, wantErr %v", err.Error(), tt.wantMsg)
@@ -545,30 +554,39 @@ func Test_droneUsecase_CheckAvailableDroneForLoading(t *testing.T) {
 	tests := []struct {
 		name   string
 		fields fields
-		want   []repo.Drone
+		want   []DroneDetails
 	}{
 		{
 			name: "test not available drone for loading",
 			fields: fields{
 				droneRepo: mosks.NewDroneRepoFailMock(),
 			},
-			want: []repoEnity.Drone{},
+			want: []DroneDetails{},
 		},
 		{
 			name: "test available drone for loading",
 			fields: fields{
 				droneRepo: mosks.NewDroneRepoMock(),
 			},
-			want: []repoEnity.Drone{
+			want: []DroneDetails{
 				{
-					ID:           1,
-					SerialNumber: "test serial 1",
-					Weight:       120,
+					Drone: repoEnity.Drone{
+						ID:           1,
+						SerialNumber: "test serial 1",
+						Weight:       120,
+					},
+					MaxPayload:        120,
+					RemainingCapacity: 120,
 				},
 				{
-					ID:           2,
-					SerialNumber: "test serial 2",
-					Weight:       120,
+					Drone: repoEnity.Drone{
+						ID:             2,
+						SerialNumber:   "test serial 2",
+						Weight:         120,
+						CurrentPayload: 100,
+					},
+					MaxPayload:        120,
+					RemainingCapacity: 20,
 				},
 			},
 		},
@@ -585,6 +603,45 @@ func Test_droneUsecase_CheckAvailableDroneForLoading(t *testing.T) {
 	}
 }
 
+func Test_newDroneDetails(t *testing.T) {
+	tests := []struct {
+		name              string
+		drone             repoEnity.Drone
+		want              float32
+		wantNeedsCharging bool
+	}{
+		{
+			name:  "test empty drone has all its weight as remaining capacity",
+			drone: repoEnity.Drone{Weight: 300, BatteryCapacity: 100},
+			want:  300,
+		},
+		{
+			name:  "test loaded drone remaining capacity",
+			drone: repoEnity.Drone{Weight: 300, CurrentPayload: 120, BatteryCapacity: 25},
+			want:  180,
+		},
+		{
+			name:  "test overloaded drone has no remaining capacity",
+			drone: repoEnity.Drone{Weight: 300, CurrentPayload: 320, BatteryCapacity: 50},
+			want:  0,
+		},
+		{
+			name:              "test drone below the loading battery needs charging",
+			drone:             repoEnity.Drone{Weight: 300, BatteryCapacity: 24},
+			want:              300,
+			wantNeedsCharging: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newDroneDetails(tt.drone, 25)
+			if got.RemainingCapacity != tt.want || got.MaxPayload != tt.drone.Weight || got.NeedsCharging != tt.wantNeedsCharging {
+				t.Errorf("newDroneDetails() = %+v, want remaining capacity %v and needs charging %v", got, tt.want, tt.wantNeedsCharging)
+			}
+		})
+	}
+}
+
 func Test_droneUsecase_CheckBatteryLevel(t *testing.T) {
 	type fields struct {
 		droneRepo repo.IDroneRepository
@@ -641,3 +698,33 @@ func Test_droneUsecase_CheckBatteryLevel(t *testing.T) {
 		})
 	}
 }
+
+func Test_droneUsecase_RecalculatePayload(t *testing.T) {
+	tests := []struct {
+		name        string
+		id          int
+		wantPayload float32
+		wantErr     error
+	}{
+		{name: "test recalculate payload of the loaded drone", id: 1, wantPayload: 25},
+		{name: "test recalculate payload of drone that not exist", id: 2, wantErr: ErrDroneNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, _, _ := newMemoryDroneUsecase(t, repo.Drone{
+				SerialNumber:   "serial 1",
+				Weight:         500,
+				State:          StateLoaded,
+				CurrentPayload: 99,
+				Medications:    []repo.LoadItem{{MedicationCode: "code", Quantity: 1, Weight: 10}, {MedicationCode: "other", Quantity: 1, Weight: 15}},
+			})
+			got, err := d.RecalculatePayload(tt.id)
+			if err != tt.wantErr {
+				t.Fatalf("droneUsecase.RecalculatePayload() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got.CurrentPayload != tt.wantPayload {
+				t.Errorf("droneUsecase.RecalculatePayload() payload = %v, want %v", got.CurrentPayload, tt.wantPayload)
+			}
+		})
+	}
+}
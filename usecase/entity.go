@@ -1,11 +1,19 @@
 package usecase
 
+import (
+	repo "drone/v2/repository"
+	"time"
+)
+
 type DorneObject struct {
 	SerialNumber string  `json:"serial_number" valid:"required~Serial Number is not provided,stringlength(10|100)"`
 	Model        string  `json:"model" valid:"required~Model is not provided,matches(Lightweight|Middleweight|Cruiserweight|Heavyweight)"`
 	Weight       float32 `json:"weight" valid:"required~Weight is not provided,range(10|500)"`
 	Battery      int     `json:"battery" valid:"optional, range(10|100)"`
 	State        string  `json:"state" valid:"optional,matches(IDLE|LOADING|LOADED|DELIVERING|DELIVERED|RETURNING)"`
+	// the drone starts at its home base
+	HomeLatitude  float64 `json:"home_latitude" valid:"optional"`
+	HomeLongitude float64 `json:"home_longitude" valid:"optional"`
 }
 
 type MedicationObject struct {
@@ -14,3 +22,144 @@ type MedicationObject struct {
 	Weight float32 `json:"weight" valid:"required~Medication weight is not provided,range(1|500)"`
 	Image  string  `json:"image" valid:"optional,url"`
 }
+
+type LoadObject struct {
+	Code      string `json:"code" valid:"required~Medication code is not provided"`
+	Quantity  int    `json:"quantity" valid:"optional,range(1|100)"`
+	LotNumber string `json:"lot_number" valid:"optional,stringlength(1|50)"`
+}
+
+type DroneUpdateObject struct {
+	Model  string  `json:"model" valid:"optional,matches(^(Lightweight|Middleweight|Cruiserweight|Heavyweight)$)"`
+	Weight float32 `json:"weight" valid:"optional,range(10|500)"`
+	// the home base is only moved when both coordinates are given
+	HomeLatitude  *float64 `json:"home_latitude" valid:"-"`
+	HomeLongitude *float64 `json:"home_longitude" valid:"-"`
+}
+
+// LocationObject is a point on the map in degrees.
+type LocationObject struct {
+	Latitude  float64 `json:"latitude" valid:"optional"`
+	Longitude float64 `json:"longitude" valid:"optional"`
+}
+
+// NearbyQuery looks for the drones within Radius kilometers of a point.
+type NearbyQuery struct {
+	Latitude  float64 `valid:"optional"`
+	Longitude float64 `valid:"optional"`
+	Radius    float64 `valid:"required~Radius is not provided,range(0|20000)"`
+}
+
+// NearbyDrone is a drone with its distance in kilometers from the point it was looked up around.
+type NearbyDrone struct {
+	DroneDetails
+	Distance float64 `json:"distance"`
+}
+
+type DroneQuery struct {
+	State      string `valid:"optional,matches(^(IDLE|LOADING|LOADED|DELIVERING|DELIVERED|RETURNING)$)"`
+	Model      string `valid:"optional,matches(^(Lightweight|Middleweight|Cruiserweight|Heavyweight)$)"`
+	MinBattery int    `valid:"optional,range(0|100)"`
+	Page       int    `valid:"optional,range(1|100000)"`
+	PageSize   int    `valid:"optional,range(1|100)"`
+	Sort       string
+}
+
+// DronePage is one page of the drones listing.
+type DronePage struct {
+	Drones   []DroneDetails `json:"drones"`
+	Page     int            `json:"page"`
+	PageSize int            `json:"page_size"`
+	Total    int64          `json:"total"`
+}
+
+// DroneDetails is the drone with its loading capacity, drones with a battery below
+// the minimum loading level are flagged as needing charging.
+type DroneDetails struct {
+	repo.Drone
+	MaxPayload        float32 `json:"max_payload"`
+	RemainingCapacity float32 `json:"remaining_capacity"`
+	NeedsCharging     bool    `json:"needs_charging"`
+}
+
+func newDroneDetails(drone repo.Drone, minBattery int) DroneDetails {
+	remaining := drone.Weight - drone.CurrentPayload
+	if remaining < 0 {
+		remaining = 0
+	}
+	return DroneDetails{
+		Drone:             drone,
+		MaxPayload:        drone.Weight,
+		RemainingCapacity: remaining,
+		NeedsCharging:     drone.BatteryCapacity < minBattery,
+	}
+}
+
+type DockObject struct {
+	Name       string  `json:"name" valid:"required~Dock name is not provided,stringlength(1|100)"`
+	Latitude   float64 `json:"latitude" valid:"optional"`
+	Longitude  float64 `json:"longitude" valid:"optional"`
+	Slots      int     `json:"slots" valid:"required~Dock slots are not provided,range(1|100)"`
+	ChargeRate float64 `json:"charge_rate" valid:"optional,range(0|100)"`
+}
+
+// DockDetails is the dock with the drones docked in it.
+type DockDetails struct {
+	repo.Dock
+	Drones    []int `json:"drones"`
+	FreeSlots int   `json:"free_slots"`
+}
+
+type OrderItemObject struct {
+	Code     string `json:"code" valid:"required~Medication code is not provided"`
+	Quantity int    `json:"quantity" valid:"optional,range(1|100)"`
+}
+
+type OrderObject struct {
+	Destination string `json:"destination" valid:"required~Destination is not provided,stringlength(1|200)"`
+	Priority    int    `json:"priority" valid:"optional,range(0|10)"`
+	// the coordinates of the destination are optional, drones are sent to them when the order is assigned
+	DestinationLatitude  *float64          `json:"destination_latitude" valid:"-"`
+	DestinationLongitude *float64          `json:"destination_longitude" valid:"-"`
+	Items                []OrderItemObject `json:"items" valid:"-"`
+}
+
+// TelemetryObject is a reading reported by a drone, the values that are not reported are left as they are
+// on the drone and an empty state keeps the state of the drone.
+type TelemetryObject struct {
+	RecordedAt   time.Time `json:"recorded_at" valid:"-"`
+	BatteryLevel *float64  `json:"battery_level" valid:"optional,range(0|100)"`
+	Latitude     *float64  `json:"latitude" valid:"optional"`
+	Longitude    *float64  `json:"longitude" valid:"optional"`
+	Altitude     *float64  `json:"altitude" valid:"optional,range(0|10000)"`
+	Speed        *float64  `json:"speed" valid:"optional,range(0|500)"`
+	State        string    `json:"state" valid:"optional,matches(^(IDLE|LOADING|LOADED|DELIVERING|DELIVERED|RETURNING)$)"`
+}
+
+// TelemetryQuery lists the readings recorded from From until before To, zero times are not filtered on.
+type TelemetryQuery struct {
+	From  time.Time `valid:"-"`
+	To    time.Time `valid:"-"`
+	Limit int       `valid:"optional,range(1|1000)"`
+}
+
+// WebhookObject subscribes the url to the events, no events subscribes it to every event.
+// A secret is generated when none is given.
+type WebhookObject struct {
+	URL    string   `json:"url" valid:"required~Webhook url is not provided,requrl"`
+	Secret string   `json:"secret" valid:"optional,stringlength(16|256)"`
+	Events []string `json:"events" valid:"-"`
+}
+
+type WebhookDetails struct {
+	ID        int       `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreatedWebhook is only returned once, the secret is not shown again.
+type CreatedWebhook struct {
+	WebhookDetails
+	Secret string `json:"secret"`
+}
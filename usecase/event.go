@@ -0,0 +1,191 @@
+package usecase
+
+import (
+	"drone/v2/clock"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	EventDroneRegistered  = "drone-registered"
+	EventMedicationLoaded = "medication-loaded"
+	EventStateChanged     = "state-changed"
+	EventBatteryLow       = "battery-low"
+	EventBatteryTick      = "battery-tick"
+	EventDroneGrounded    = "drone-grounded"
+	EventAlertFired       = "alert-fired"
+)
+
+var eventTypes = map[string]bool{
+	EventDroneRegistered:  true,
+	EventMedicationLoaded: true,
+	EventStateChanged:     true,
+	EventBatteryLow:       true,
+	EventBatteryTick:      true,
+	EventDroneGrounded:    true,
+	EventAlertFired:       true,
+}
+
+const (
+	// eventHistorySize is how many of the latest events are kept for the subscribers resuming after them
+	eventHistorySize = 1000
+	// eventBufferSize is how many events a subscriber can fall behind before it is dropped
+	eventBufferSize = 100
+)
+
+type Event struct {
+	ID      int64     `json:"id"`
+	Type    string    `json:"type"`
+	DroneID int       `json:"drone_id"`
+	At      time.Time `json:"at"`
+	Data    any       `json:"data"`
+}
+
+type DroneRegisteredEvent struct {
+	SerialNumber string `json:"serial_number"`
+	Model        string `json:"model"`
+}
+
+type MedicationLoadedEvent struct {
+	Code      string  `json:"code"`
+	Quantity  int     `json:"quantity"`
+	LotNumber string  `json:"lot_number,omitempty"`
+	Weight    float32 `json:"weight"`
+}
+
+type StateChangedEvent struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// BatteryEvent is the battery level of a drone, battery ticks also have the state of the drone
+// and the percentage its battery dropped per minute since the previous check.
+type BatteryEvent struct {
+	Level int     `json:"level"`
+	State string  `json:"state,omitempty"`
+	Drain float64 `json:"drain,omitempty"`
+}
+
+// EventFilter picks the events of a subscription, an empty filter picks every event.
+type EventFilter struct {
+	DroneID int
+	Types   []string
+}
+
+func (f EventFilter) matches(event Event) bool {
+	if f.DroneID != 0 && event.DroneID != f.DroneID {
+		return false
+	}
+	if len(f.Types) == 0 {
+		return true
+	}
+	for _, eventType := range f.Types {
+		if eventType == event.Type {
+			return true
+		}
+	}
+	return false
+}
+
+type Subscription struct {
+	// Events is closed when the subscription is closed or when it falls too far behind
+	Events <-chan Event
+	events chan Event
+	filter EventFilter
+}
+
+type IEventBus interface {
+	Publish(eventType string, droneID int, data any) Event
+	Subscribe(filter EventFilter, lastEventID int64) (*Subscription, error)
+	Unsubscribe(subscription *Subscription)
+}
+
+type eventBus struct {
+	mu          sync.Mutex
+	clock       clock.Clock
+	lastID      int64
+	history     []Event
+	subscribers map[*Subscription]bool
+}
+
+func NewEventBus(clock clock.Clock) IEventBus {
+	return &eventBus{
+		clock:       clock,
+		subscribers: map[*Subscription]bool{},
+	}
+}
+
+// Publish sends the event to the subscribers that want it, a subscriber too slow to take it
+// is dropped and can resume after the last event it got.
+func (b *eventBus) Publish(eventType string, droneID int, data any) Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.lastID++
+	event := Event{ID: b.lastID, Type: eventType, DroneID: droneID, At: b.clock.Now().UTC(), Data: data}
+	b.history = append(b.history, event)
+	if len(b.history) > eventHistorySize {
+		b.history = b.history[len(b.history)-eventHistorySize:]
+	}
+	for subscription := range b.subscribers {
+		if !subscription.filter.matches(event) {
+			continue
+		}
+		select {
+		case subscription.events <- event:
+		default:
+			b.remove(subscription)
+		}
+	}
+	return event
+}
+
+// Subscribe starts with the kept events after the last event id, 0 starts with the next event.
+func (b *eventBus) Subscribe(filter EventFilter, lastEventID int64) (*Subscription, error) {
+	for _, eventType := range filter.Types {
+		if !eventTypes[eventType] {
+			return nil, errors.New(fmt.Sprintf("event type %q is not exist", eventType))
+		}
+	}
+	if lastEventID < 0 {
+		return nil, errors.New("Invaild last event id")
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	missed := []Event{}
+	if lastEventID > 0 {
+		for _, event := range b.history {
+			if event.ID > lastEventID && filter.matches(event) {
+				missed = append(missed, event)
+			}
+		}
+	}
+	events := make(chan Event, eventBufferSize+len(missed))
+	for _, event := range missed {
+		events <- event
+	}
+	subscription := &Subscription{Events: events, events: events, filter: filter}
+	b.subscribers[subscription] = true
+	return subscription, nil
+}
+
+func (b *eventBus) Unsubscribe(subscription *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.remove(subscription)
+}
+
+func (b *eventBus) remove(subscription *Subscription) {
+	if b.subscribers[subscription] {
+		delete(b.subscribers, subscription)
+		close(subscription.events)
+	}
+}
+
+// publishEvent publishes the event of the drone if there is an event bus.
+func (d *droneUsecase) publishEvent(eventType string, droneID int, data any) {
+	if d.events != nil {
+		d.events.Publish(eventType, droneID, data)
+	}
+}
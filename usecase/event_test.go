@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"drone/v2/clock"
+	repo "drone/v2/repository"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// nextEvents takes the next n events of the subscription.
+func nextEvents(t *testing.T, subscription *Subscription, n int) []Event {
+	t.Helper()
+	events := []Event{}
+	for len(events) < n {
+		select {
+		case event, open := <-subscription.Events:
+			if !open {
+				t.Fatalf("subscription closed after %+v", events)
+			}
+			events = append(events, event)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("got events %+v, want %d", events, n)
+		}
+	}
+	return events
+}
+
+func eventIDs(events []Event) []int64 {
+	ids := []int64{}
+	for _, event := range events {
+		ids = append(ids, event.ID)
+	}
+	return ids
+}
+
+func Test_eventBus_Subscribe(t *testing.T) {
+	tests := []struct {
+		name        string
+		filter      EventFilter
+		lastEventID int64
+		want        []int64
+		wantErr     string
+	}{
+		{name: "new events only", want: []int64{4, 5, 6}},
+		{name: "resume", lastEventID: 1, want: []int64{2, 3, 4, 5, 6}},
+		{name: "resume by drone", filter: EventFilter{DroneID: 1}, lastEventID: 1, want: []int64{2, 4, 5}},
+		{name: "resume by type", filter: EventFilter{Types: []string{EventStateChanged}}, lastEventID: 2, want: []int64{3, 5, 6}},
+		{name: "unknown type", filter: EventFilter{Types: []string{"drone-crashed"}}, wantErr: `event type "drone-crashed" is not exist`},
+		{name: "negative last event id", lastEventID: -1, wantErr: "Invaild last event id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bus := NewEventBus(clock.NewFake(testTime))
+			bus.Publish(EventDroneRegistered, 1, nil)
+			bus.Publish(EventStateChanged, 1, nil)
+			bus.Publish(EventStateChanged, 2, nil)
+			subscription, err := bus.Subscribe(tt.filter, tt.lastEventID)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("eventBus.Subscribe() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("eventBus.Subscribe() error = %v", err)
+			}
+			defer bus.Unsubscribe(subscription)
+			bus.Publish(EventBatteryTick, 1, BatteryEvent{Level: 50})
+			bus.Publish(EventStateChanged, 1, nil)
+			bus.Publish(EventStateChanged, 2, nil)
+			if got := eventIDs(nextEvents(t, subscription, len(tt.want))); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("eventBus.Subscribe() events = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_eventBus_SlowSubscriber(t *testing.T) {
+	bus := NewEventBus(clock.NewFake(testTime))
+	slow, _ := bus.Subscribe(EventFilter{}, 0)
+	for i := 0; i <= eventBufferSize; i++ {
+		bus.Publish(EventBatteryTick, 1, BatteryEvent{Level: 50})
+	}
+	received := 0
+	for range slow.Events {
+		received++
+	}
+	if received != eventBufferSize {
+		t.Errorf("slow subscriber got %d events before it was dropped, want %d", received, eventBufferSize)
+	}
+	// unsubscribing a dropped subscriber is harmless
+	bus.Unsubscribe(slow)
+
+	resumed, _ := bus.Subscribe(EventFilter{}, int64(received))
+	defer bus.Unsubscribe(resumed)
+	if got := nextEvents(t, resumed, 1); got[0].ID != eventBufferSize+1 || !got[0].At.Equal(testTime) {
+		t.Errorf("resumed events = %+v, want the missed event %d", got, eventBufferSize+1)
+	}
+}
+
+func Test_droneUsecase_Events(t *testing.T) {
+	d, droneRepo, _ := newMemoryDroneUsecase(t, repo.Drone{
+		SerialNumber:    "serial 1",
+		Model:           "Lightweight",
+		Weight:          500,
+		State:           StateIdle,
+		BatteryCapacity: 100,
+	})
+	d.events = NewEventBus(d.jobs.clock)
+	d.jobs.start(1, d.loadMedication, d.finishLoading)
+	subscription, _ := d.events.Subscribe(EventFilter{}, 0)
+	defer d.events.Unsubscribe(subscription)
+
+	id, err := d.RegisterDrone(DorneObject{SerialNumber: "serial 1234", Model: "Lightweight", Weight: 500, State: StateIdle})
+	if err != nil {
+		t.Fatalf("droneUsecase.RegisterDrone() error = %v", err)
+	}
+	if _, err := d.LoadingMedication(1, LoadObject{Code: "code", Quantity: 2}); err != nil {
+		t.Fatalf("droneUsecase.LoadingMedication() error = %v", err)
+	}
+	events := nextEvents(t, subscription, 4)
+	want := []Event{
+		{ID: 1, Type: EventDroneRegistered, DroneID: id, Data: DroneRegisteredEvent{SerialNumber: "serial 1234", Model: "Lightweight"}},
+		{ID: 2, Type: EventStateChanged, DroneID: 1, Data: StateChangedEvent{From: StateIdle, To: StateLoading}},
+		{ID: 3, Type: EventMedicationLoaded, DroneID: 1, Data: MedicationLoadedEvent{Code: "code", Quantity: 2, Weight: 20}},
+		{ID: 4, Type: EventStateChanged, DroneID: 1, Data: StateChangedEvent{From: StateLoading, To: StateLoaded}},
+	}
+	for i := range events {
+		events[i].At = time.Time{}
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("events = %+v, want %+v", events, want)
+	}
+
+	// the loaded drone trickles from 100 and the registered idle drone goes below the loading level
+	if err := droneRepo.UpdateBatteries(map[int]int{id: 25}, ""); err != nil {
+		t.Fatalf("Can't update the battery: %v", err)
+	}
+	d.CheckDronesBatteries(10 * time.Minute)
+	events = nextEvents(t, subscription, 3)
+	for i := range events {
+		events[i].ID, events[i].At = 0, time.Time{}
+	}
+	want = []Event{
+		{Type: EventBatteryTick, DroneID: 1, Data: BatteryEvent{Level: 98, State: StateLoaded, Drain: 0.2}},
+		{Type: EventBatteryTick, DroneID: id, Data: BatteryEvent{Level: 24, State: StateIdle, Drain: 0.1}},
+		{Type: EventBatteryLow, DroneID: id, Data: BatteryEvent{Level: 24}},
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("battery events = %+v, want %+v", events, want)
+	}
+}
@@ -0,0 +1,106 @@
+package usecase
+
+import (
+	repo "drone/v2/repository"
+	"fmt"
+)
+
+// flightSpeed is the kilometers a drone flies in a minute.
+const flightSpeed = 1.0
+
+// RangeError is returned when the drone does not have the battery to fly to its destination and back home.
+type RangeError struct {
+	DroneID int
+	Needed  float64
+	Level   int
+}
+
+func (e *RangeError) Error() string {
+	return fmt.Sprintf("drone %d needs %.1f%% battery for the round trip but has %d%%", e.DroneID, e.Needed, e.Level)
+}
+
+// Feasibility is the estimate of a delivery round trip, distances are in kilometers
+// and the battery it takes is in percent of the battery of the drone.
+type Feasibility struct {
+	DroneID          int     `json:"drone_id"`
+	OutboundDistance float64 `json:"outbound_distance"`
+	ReturnDistance   float64 `json:"return_distance"`
+	OutboundBattery  float64 `json:"outbound_battery"`
+	ReturnBattery    float64 `json:"return_battery"`
+	SafetyMargin     float64 `json:"safety_margin"`
+	Needed           float64 `json:"needed"`
+	BatteryLevel     int     `json:"battery_level"`
+	Feasible         bool    `json:"feasible"`
+}
+
+// estimateTrip estimates the battery the drone uses flying from where it is to the destination with its
+// payload, then empty back to its home base. The trip is feasible if the drone is left with the margin.
+func estimateTrip(drone repo.Drone, latitude float64, longitude float64, margin float64) Feasibility {
+	delivering, returning := drone, drone
+	delivering.State = StateDelivering
+	returning.State, returning.CurrentPayload = StateReturning, 0
+	trip := Feasibility{
+		DroneID:          drone.ID,
+		OutboundDistance: distance(drone.Latitude, drone.Longitude, latitude, longitude),
+		ReturnDistance:   distance(latitude, longitude, drone.HomeLatitude, drone.HomeLongitude),
+		SafetyMargin:     margin,
+		BatteryLevel:     drone.BatteryCapacity,
+	}
+	trip.OutboundBattery = -batteryChange(delivering) * trip.OutboundDistance / flightSpeed
+	trip.ReturnBattery = -batteryChange(returning) * trip.ReturnDistance / flightSpeed
+	trip.Needed = trip.OutboundBattery + trip.ReturnBattery + margin
+	trip.Feasible = trip.Needed <= float64(drone.BatteryCapacity)
+	return trip
+}
+
+// canDeliver tells if the drone can carry weight more to the destination of the order and come back,
+// a drone that has another destination already can not take the order and an order without a
+// destination is carried to the destination of the drone.
+func canDeliver(drone repo.Drone, order repo.Order, weight float32, margin float64) bool {
+	if !sameDestination(drone, order) {
+		return false
+	}
+	latitude, longitude := order.DestinationLatitude, order.DestinationLongitude
+	if latitude == nil || longitude == nil {
+		latitude, longitude = drone.DestinationLatitude, drone.DestinationLongitude
+	}
+	if latitude == nil || longitude == nil {
+		return true
+	}
+	drone.CurrentPayload += weight
+	return estimateTrip(drone, *latitude, *longitude, margin).Feasible
+}
+
+// sameDestination tells if the order can be carried by the drone along with what it carries already,
+// an order or a drone without a destination goes along with any destination.
+func sameDestination(drone repo.Drone, order repo.Order) bool {
+	if drone.DestinationLatitude == nil || drone.DestinationLongitude == nil ||
+		order.DestinationLatitude == nil || order.DestinationLongitude == nil {
+		return true
+	}
+	return *drone.DestinationLatitude == *order.DestinationLatitude && *drone.DestinationLongitude == *order.DestinationLongitude
+}
+
+// checkRange fails with RangeError if the drone can not make the round trip to its destination.
+func (d *droneUsecase) checkRange(drone repo.Drone) error {
+	if drone.DestinationLatitude == nil || drone.DestinationLongitude == nil {
+		return nil
+	}
+	if trip := estimateTrip(drone, *drone.DestinationLatitude, *drone.DestinationLongitude, d.safetyMargin); !trip.Feasible {
+		return &RangeError{DroneID: drone.ID, Needed: trip.Needed, Level: drone.BatteryCapacity}
+	}
+	return nil
+}
+
+// CanReach estimates if the drone can deliver what it carries and has queued for loading to the location.
+func (d *droneUsecase) CanReach(id int, location LocationObject) (Feasibility, error) {
+	if err := validateLocation(location.Latitude, location.Longitude); err != nil {
+		return Feasibility{}, err
+	}
+	drone, err := d.droneRepo.Get(id)
+	if err != nil {
+		return Feasibility{}, droneError(err)
+	}
+	drone.CurrentPayload += d.jobs.pendingWeight(id)
+	return estimateTrip(drone, location.Latitude, location.Longitude, d.safetyMargin), nil
+}
@@ -0,0 +1,195 @@
+package usecase
+
+import (
+	repo "drone/v2/repository"
+	"errors"
+	"math"
+	"testing"
+)
+
+func Test_estimateTrip(t *testing.T) {
+	// 0.1 degree on the equator is 11.12km
+	tests := []struct {
+		name         string
+		drone        repo.Drone
+		longitude    float64
+		margin       float64
+		wantOutbound float64
+		wantReturn   float64
+		wantFeasible bool
+	}{
+		{
+			name:         "test trip to the home base",
+			drone:        repo.Drone{Model: "Lightweight", BatteryCapacity: 10},
+			wantFeasible: true,
+		},
+		{
+			name:         "test empty Lightweight drone",
+			drone:        repo.Drone{Model: "Lightweight", BatteryCapacity: 40},
+			longitude:    0.1,
+			wantOutbound: 16.68,
+			wantReturn:   16.68,
+			wantFeasible: true,
+		},
+		{
+			name:         "test payload drains the outbound leg only",
+			drone:        repo.Drone{Model: "Lightweight", BatteryCapacity: 40, CurrentPayload: 100},
+			longitude:    0.1,
+			wantOutbound: 27.80,
+			wantReturn:   16.68,
+		},
+		{
+			name:         "test Heavyweight battery lasts longer",
+			drone:        repo.Drone{Model: "Heavyweight", BatteryCapacity: 40, CurrentPayload: 100},
+			longitude:    0.1,
+			wantOutbound: 11.12,
+			wantReturn:   6.67,
+			wantFeasible: true,
+		},
+		{
+			name:         "test drone away from home flies back from the destination",
+			drone:        repo.Drone{Model: "Lightweight", BatteryCapacity: 40, Longitude: 0.1},
+			longitude:    0.1,
+			wantReturn:   16.68,
+			wantFeasible: true,
+		},
+		{
+			name:         "test margin is left in the battery",
+			drone:        repo.Drone{Model: "Lightweight", BatteryCapacity: 40},
+			longitude:    0.1,
+			margin:       10,
+			wantOutbound: 16.68,
+			wantReturn:   16.68,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := estimateTrip(tt.drone, 0, tt.longitude, tt.margin)
+			if math.Abs(got.OutboundBattery-tt.wantOutbound) > 0.01 || math.Abs(got.ReturnBattery-tt.wantReturn) > 0.01 {
+				t.Errorf("estimateTrip() = %+v, want outbound %v and return %v", got, tt.wantOutbound, tt.wantReturn)
+			}
+			if got.Needed != got.OutboundBattery+got.ReturnBattery+tt.margin || got.Feasible != tt.wantFeasible {
+				t.Errorf("estimateTrip() = %+v, want feasible %v", got, tt.wantFeasible)
+			}
+		})
+	}
+}
+
+func Test_canDeliver(t *testing.T) {
+	// an empty Lightweight drone takes 33.36% of its battery for 0.1 degree and back
+	destination := func(longitude float64) (*float64, *float64) {
+		return floatPointer(0), floatPointer(longitude)
+	}
+	tests := []struct {
+		name   string
+		drone  float64
+		order  float64
+		weight float32
+		want   bool
+	}{
+		{name: "test drone and order without destination", want: true},
+		{name: "test drone without destination takes the order in range", order: 0.1, want: true},
+		{name: "test order out of range", order: 0.2},
+		{name: "test drone flying to the destination of the order", drone: 0.1, order: 0.1, want: true},
+		{name: "test drone flying to another destination", drone: 0.01, order: 0.1},
+		{name: "test order without destination goes to the destination of the drone", drone: 0.1, want: true},
+		{name: "test order weight drains the trip to the destination of the drone", drone: 0.1, weight: 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			drone := repo.Drone{Model: "Lightweight", BatteryCapacity: 40}
+			if tt.drone != 0 {
+				drone.DestinationLatitude, drone.DestinationLongitude = destination(tt.drone)
+			}
+			order := repo.Order{ID: 1}
+			if tt.order != 0 {
+				order.DestinationLatitude, order.DestinationLongitude = destination(tt.order)
+			}
+			if got := canDeliver(drone, order, tt.weight, 0); got != tt.want {
+				t.Errorf("canDeliver() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_droneUsecase_StartDelivery_Range(t *testing.T) {
+	// 0.1 degree of longitude on the equator is 11.12km, a Lightweight drone carrying 200 grams
+	// uses 3.5% a minute there and 1.5% a minute back so the round trip takes 55.6%
+	tests := []struct {
+		name        string
+		battery     int
+		margin      float64
+		destination *float64
+		wantRange   bool
+		wantNeeded  float64
+	}{
+		{name: "test drone with battery for the round trip", battery: 60, destination: floatPointer(0.1)},
+		{name: "test drone without battery for the round trip", battery: 50, destination: floatPointer(0.1), wantRange: true, wantNeeded: 55.6},
+		{name: "test drone without battery for the safety margin", battery: 60, margin: 10, destination: floatPointer(0.1), wantRange: true, wantNeeded: 65.6},
+		{name: "test drone without destination", battery: 50, margin: 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			drone := repo.Drone{
+				SerialNumber:    "serial 1",
+				Model:           "Lightweight",
+				Weight:          500,
+				State:           StateLoaded,
+				BatteryCapacity: tt.battery,
+				CurrentPayload:  200,
+			}
+			if tt.destination != nil {
+				drone.DestinationLatitude, drone.DestinationLongitude = floatPointer(0), tt.destination
+			}
+			d, droneRepo, _ := newMemoryDroneUsecase(t, drone)
+			d.safetyMargin = tt.margin
+			err := d.StartDelivery(1)
+			var outOfRange *RangeError
+			if errors.As(err, &outOfRange) != tt.wantRange {
+				t.Fatalf("droneUsecase.StartDelivery() error = %v, want range error %v", err, tt.wantRange)
+			}
+			if tt.wantRange && math.Abs(outOfRange.Needed-tt.wantNeeded) > 0.1 {
+				t.Errorf("RangeError.Needed = %v, want %v", outOfRange.Needed, tt.wantNeeded)
+			}
+			if !tt.wantRange && err != nil {
+				t.Errorf("droneUsecase.StartDelivery() error = %v", err)
+			}
+			want := StateDelivering
+			if tt.wantRange {
+				want = StateLoaded
+			}
+			if drone, _ := droneRepo.Get(1); drone.State != want {
+				t.Errorf("drone state = %s, want %s", drone.State, want)
+			}
+		})
+	}
+}
+
+func Test_droneUsecase_CanReach(t *testing.T) {
+	d, _, _ := newMemoryDroneUsecase(t, repo.Drone{SerialNumber: "serial 1", Model: "Lightweight", State: StateIdle, BatteryCapacity: 40})
+	tests := []struct {
+		name         string
+		id           int
+		location     LocationObject
+		wantErr      error
+		wantFeasible bool
+	}{
+		{name: "test location in range", id: 1, location: LocationObject{Longitude: 0.1}, wantFeasible: true},
+		{name: "test location out of range", id: 1, location: LocationObject{Longitude: 0.2}},
+		{name: "test missing drone", id: 100, wantErr: ErrDroneNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := d.CanReach(tt.id, tt.location)
+			if err != tt.wantErr {
+				t.Fatalf("droneUsecase.CanReach() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got.Feasible != tt.wantFeasible {
+				t.Errorf("droneUsecase.CanReach() = %+v, want feasible %v", got, tt.wantFeasible)
+			}
+		})
+	}
+	if _, err := d.CanReach(1, LocationObject{Latitude: -91}); err == nil {
+		t.Errorf("droneUsecase.CanReach() out of the map error = nil, want error")
+	}
+}
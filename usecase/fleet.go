@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	repo "drone/v2/repository"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/asaskevich/govalidator"
+)
+
+const (
+	defaultPageSize = 20
+)
+
+var (
+	ErrDroneNotFound = errors.New("drone is not exist")
+	ErrDroneNotIdle  = errors.New("only IDLE drones can be decommissioned")
+)
+
+// droneSortColumns maps the sort fields accepted by ListDrones to their columns.
+var droneSortColumns = map[string]string{
+	"id":              "id",
+	"serial_number":   "serial_number",
+	"model":           "model",
+	"state":           "state",
+	"weight":          "weight",
+	"battery":         "battery_capacity",
+	"current_payload": "current_payload",
+}
+
+func (d *droneUsecase) GetDrone(id int) (DroneDetails, error) {
+	drone, err := d.droneRepo.Get(id)
+	if err != nil {
+		return DroneDetails{}, droneError(err)
+	}
+	return newDroneDetails(drone, d.minLoadingBattery), nil
+}
+
+// ListDrones returns one page of the fleet, sort is a field name optionally prefixed with - for descending order.
+func (d *droneUsecase) ListDrones(query DroneQuery) (DronePage, error) {
+	queryValidate, err := govalidator.ValidateStruct(query)
+	if err != nil || !queryValidate {
+		return DronePage{}, err
+	}
+	order, err := droneOrder(query.Sort)
+	if err != nil {
+		return DronePage{}, err
+	}
+	if query.Page == 0 {
+		query.Page = 1
+	}
+	if query.PageSize == 0 {
+		query.PageSize = defaultPageSize
+	}
+	drones, total, err := d.droneRepo.List(repo.DroneFilter{
+		State:      query.State,
+		Model:      query.Model,
+		MinBattery: query.MinBattery,
+		Order:      order,
+		Offset:     (query.Page - 1) * query.PageSize,
+		Limit:      query.PageSize,
+	})
+	if err != nil {
+		return DronePage{}, err
+	}
+	page := DronePage{
+		Drones:   make([]DroneDetails, 0, len(drones)),
+		Page:     query.Page,
+		PageSize: query.PageSize,
+		Total:    total,
+	}
+	for _, drone := range drones {
+		page.Drones = append(page.Drones, newDroneDetails(drone, d.minLoadingBattery))
+	}
+	return page, nil
+}
+
+// UpdateDrone changes the model, the max weight and/or the home base of the drone, the weight
+// can not go below what the drone carries or has queued for loading.
+func (d *droneUsecase) UpdateDrone(id int, object DroneUpdateObject) (DroneDetails, error) {
+	unlock := d.jobs.lockDrone(id)
+	defer unlock()
+	drone, err := d.droneRepo.Get(id)
+	if err != nil {
+		return DroneDetails{}, droneError(err)
+	}
+	updateValidate, err := govalidator.ValidateStruct(object)
+	if err != nil || !updateValidate {
+		return DroneDetails{}, err
+	}
+	if object.Model != "" {
+		drone.Model = object.Model
+	}
+	if object.Weight != 0 {
+		if payload := drone.CurrentPayload + d.jobs.pendingWeight(id); object.Weight < payload {
+			return DroneDetails{}, errors.New(fmt.Sprintf("drone weight can not be less than its current payload %f", payload))
+		}
+		drone.Weight = object.Weight
+	}
+	if object.HomeLatitude != nil && object.HomeLongitude != nil {
+		if err := validateLocation(*object.HomeLatitude, *object.HomeLongitude); err != nil {
+			return DroneDetails{}, err
+		}
+		drone.HomeLatitude, drone.HomeLongitude = *object.HomeLatitude, *object.HomeLongitude
+	}
+	if err := d.droneRepo.Update(&drone); err != nil {
+		return DroneDetails{}, droneError(err)
+	}
+	return d.GetDrone(id)
+}
+
+// DecommissionDrone takes an IDLE drone out of the fleet, its history is kept.
+func (d *droneUsecase) DecommissionDrone(id int) error {
+	unlock := d.jobs.lockDrone(id)
+	defer unlock()
+	drone, err := d.droneRepo.Get(id)
+	if err != nil {
+		return droneError(err)
+	}
+	if drone.State != StateIdle {
+		return ErrDroneNotIdle
+	}
+	return droneError(d.droneRepo.Decommission(id, drone.Version))
+}
+
+func droneOrder(sort string) (string, error) {
+	if sort == "" {
+		return "", nil
+	}
+	direction := "asc"
+	field := sort
+	if strings.HasPrefix(sort, "-") {
+		direction = "desc"
+		field = sort[1:]
+	}
+	column, found := droneSortColumns[field]
+	if !found {
+		return "", errors.New(fmt.Sprintf("drones can not be sorted by %s", field))
+	}
+	return fmt.Sprintf("%s %s", column, direction), nil
+}
+
+func droneError(err error) error {
+	if errors.Is(err, repo.ErrRecordNotFound) {
+		return ErrDroneNotFound
+	}
+	return err
+}
@@ -0,0 +1,133 @@
+package usecase
+
+import (
+	"drone/v2/clock"
+	repo "drone/v2/repository"
+	mosks "drone/v2/repository/mocks"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// filterDroneRepo records the filter it was listed with.
+type filterDroneRepo struct {
+	repo.IDroneRepository
+	filter repo.DroneFilter
+}
+
+func (r *filterDroneRepo) List(filter repo.DroneFilter) ([]repo.Drone, int64, error) {
+	r.filter = filter
+	return r.IDroneRepository.List(filter)
+}
+
+func Test_droneUsecase_ListDrones(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      DroneQuery
+		wantFilter repo.DroneFilter
+		wantErr    bool
+	}{
+		{
+			name:       "default page",
+			query:      DroneQuery{},
+			wantFilter: repo.DroneFilter{Limit: defaultPageSize},
+		},
+		{
+			name:       "filters, page and sort",
+			query:      DroneQuery{State: StateIdle, Model: "Lightweight", MinBattery: 30, Page: 3, PageSize: 10, Sort: "-battery"},
+			wantFilter: repo.DroneFilter{State: StateIdle, Model: "Lightweight", MinBattery: 30, Order: "battery_capacity desc", Offset: 20, Limit: 10},
+		},
+		{
+			name:    "unknown sort field",
+			query:   DroneQuery{Sort: "password"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown state",
+			query:   DroneQuery{State: "FLYING"},
+			wantErr: true,
+		},
+		{
+			name:    "page size too big",
+			query:   DroneQuery{PageSize: 1000},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			droneRepo := &filterDroneRepo{IDroneRepository: mosks.NewDroneRepoMock()}
+			d := &droneUsecase{
+				droneRepo: droneRepo,
+				jobs:      newLoadingQueue(loadingQueueSize, clock.System),
+			}
+			got, err := d.ListDrones(tt.query)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("droneUsecase.ListDrones() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(droneRepo.filter, tt.wantFilter) {
+				t.Errorf("droneUsecase.ListDrones() filter = %+v, want %+v", droneRepo.filter, tt.wantFilter)
+			}
+			if got.Total != 2 || len(got.Drones) != 2 {
+				t.Errorf("droneUsecase.ListDrones() = %+v, want 2 drones", got)
+			}
+		})
+	}
+}
+
+func Test_droneUsecase_UpdateDrone(t *testing.T) {
+	tests := []struct {
+		name    string
+		object  DroneUpdateObject
+		want    repo.Drone
+		wantErr bool
+	}{
+		{
+			name:   "update model and weight",
+			object: DroneUpdateObject{Model: "Heavyweight", Weight: 400},
+			want:   repo.Drone{Model: "Heavyweight", Weight: 400},
+		},
+		{
+			name:    "weight less than current payload",
+			object:  DroneUpdateObject{Weight: 20},
+			wantErr: true,
+		},
+		{
+			name:    "unknown model",
+			object:  DroneUpdateObject{Model: "Jumbo"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, droneRepo := newLoadedDroneUsecase(t, StateLoaded)
+			_, err := d.UpdateDrone(1, tt.object)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("droneUsecase.UpdateDrone() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			drone, _ := droneRepo.Get(1)
+			if drone.Model != tt.want.Model || drone.Weight != tt.want.Weight {
+				t.Errorf("droneUsecase.UpdateDrone() = %v %v, want %v %v", drone.Model, drone.Weight, tt.want.Model, tt.want.Weight)
+			}
+		})
+	}
+}
+
+func Test_droneUsecase_DecommissionDrone(t *testing.T) {
+	d, _ := newLoadedDroneUsecase(t, StateLoaded)
+	if err := d.DecommissionDrone(1); !errors.Is(err, ErrDroneNotIdle) {
+		t.Errorf("droneUsecase.DecommissionDrone() error = %v, wantErr %v", err, ErrDroneNotIdle)
+	}
+	d = &droneUsecase{
+		droneRepo: mosks.NewDroneRepoMock(),
+		jobs:      newLoadingQueue(loadingQueueSize, clock.System),
+	}
+	if err := d.DecommissionDrone(1); err != nil {
+		t.Errorf("droneUsecase.DecommissionDrone() error = %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+package usecase
+
+import (
+	repo "drone/v2/repository"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/asaskevich/govalidator"
+)
+
+const earthRadius = 6371.0
+
+var ErrDroneFlying = errors.New("drone destination can not be changed while it is flying")
+
+// validateLocation checks the coordinates are on the map, govalidator ranges can not be negative.
+func validateLocation(latitude float64, longitude float64) error {
+	if latitude < -90 || latitude > 90 || longitude < -180 || longitude > 180 {
+		return errors.New(fmt.Sprintf("Invaild location %v,%v", latitude, longitude))
+	}
+	return nil
+}
+
+// distance is the great circle distance in kilometers between two points by the haversine formula.
+func distance(fromLatitude float64, fromLongitude float64, toLatitude float64, toLongitude float64) float64 {
+	radians := func(degrees float64) float64 { return degrees * math.Pi / 180 }
+	latitude := radians(toLatitude - fromLatitude)
+	longitude := radians(toLongitude - fromLongitude)
+	a := math.Pow(math.Sin(latitude/2), 2) +
+		math.Cos(radians(fromLatitude))*math.Cos(radians(toLatitude))*math.Pow(math.Sin(longitude/2), 2)
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// UpdatePosition saves the position reported by the drone.
+func (d *droneUsecase) UpdatePosition(id int, location LocationObject) (DroneDetails, error) {
+	if err := validateLocation(location.Latitude, location.Longitude); err != nil {
+		return DroneDetails{}, err
+	}
+	if err := d.droneRepo.UpdatePosition(id, location.Latitude, location.Longitude); err != nil {
+		return DroneDetails{}, droneError(err)
+	}
+	return d.GetDrone(id)
+}
+
+// SetDestination sets where the drone delivers its load, it can only change while the drone is at the base.
+func (d *droneUsecase) SetDestination(id int, location LocationObject) (DroneDetails, error) {
+	if err := validateLocation(location.Latitude, location.Longitude); err != nil {
+		return DroneDetails{}, err
+	}
+	unlock := d.jobs.lockDrone(id)
+	defer unlock()
+	drone, err := d.droneRepo.Get(id)
+	if err != nil {
+		return DroneDetails{}, droneError(err)
+	}
+	if !dockStates[drone.State] {
+		return DroneDetails{}, ErrDroneFlying
+	}
+	if err := d.droneRepo.SetDestination(id, &location.Latitude, &location.Longitude); err != nil {
+		return DroneDetails{}, droneError(err)
+	}
+	return d.GetDrone(id)
+}
+
+// ListDronesNearby returns the drones within the radius of the point, the nearest first.
+func (d *droneUsecase) ListDronesNearby(query NearbyQuery) ([]NearbyDrone, error) {
+	queryValidate, err := govalidator.ValidateStruct(query)
+	if err != nil || !queryValidate {
+		return nil, err
+	}
+	if err := validateLocation(query.Latitude, query.Longitude); err != nil {
+		return nil, err
+	}
+	drones, _, err := d.droneRepo.List(repo.DroneFilter{})
+	if err != nil {
+		return nil, err
+	}
+	nearby := []NearbyDrone{}
+	for _, drone := range drones {
+		if km := distance(query.Latitude, query.Longitude, drone.Latitude, drone.Longitude); km <= query.Radius {
+			nearby = append(nearby, NearbyDrone{DroneDetails: newDroneDetails(drone, d.minLoadingBattery), Distance: km})
+		}
+	}
+	sort.SliceStable(nearby, func(i, j int) bool {
+		if nearby[i].Distance != nearby[j].Distance {
+			return nearby[i].Distance < nearby[j].Distance
+		}
+		return nearby[i].ID < nearby[j].ID
+	})
+	return nearby, nil
+}
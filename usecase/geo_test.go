@@ -0,0 +1,105 @@
+package usecase
+
+import (
+	repo "drone/v2/repository"
+	"math"
+	"testing"
+)
+
+func floatPointer(value float64) *float64 {
+	return &value
+}
+
+func Test_distance(t *testing.T) {
+	tests := []struct {
+		name string
+		from [2]float64
+		to   [2]float64
+		want float64
+	}{
+		{name: "test same point", from: [2]float64{30.04, 31.23}, to: [2]float64{30.04, 31.23}, want: 0},
+		{name: "test a degree of latitude", from: [2]float64{0, 0}, to: [2]float64{1, 0}, want: 111.19},
+		{name: "test a degree of longitude on the equator", from: [2]float64{0, 179.5}, to: [2]float64{0, -179.5}, want: 111.19},
+		{name: "test pole to pole", from: [2]float64{90, 0}, to: [2]float64{-90, 0}, want: 20015.09},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := distance(tt.from[0], tt.from[1], tt.to[0], tt.to[1])
+			if math.Abs(got-tt.want) > 0.01 {
+				t.Errorf("distance() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_droneUsecase_SetDestination(t *testing.T) {
+	d, droneRepo, _ := newMemoryDroneUsecase(t, repo.Drone{SerialNumber: "serial 1", State: StateLoaded, BatteryCapacity: 100})
+	if _, err := d.SetDestination(1, LocationObject{Latitude: 91}); err == nil {
+		t.Errorf("droneUsecase.SetDestination() out of the map error = nil, want error")
+	}
+	drone, err := d.SetDestination(1, LocationObject{Latitude: 0.01, Longitude: 0.01})
+	if err != nil || drone.DestinationLatitude == nil || *drone.DestinationLatitude != 0.01 {
+		t.Fatalf("droneUsecase.SetDestination() = %+v, %v, want destination 0.01,0.01", drone, err)
+	}
+	if _, err := d.SetDestination(100, LocationObject{}); err != ErrDroneNotFound {
+		t.Errorf("droneUsecase.SetDestination() of a missing drone error = %v, want %v", err, ErrDroneNotFound)
+	}
+
+	for _, move := range []func(id int) error{d.StartDelivery, d.MarkDelivered, d.ReturnToBase} {
+		if err := move(1); err != nil {
+			t.Fatalf("moving drone error = %v", err)
+		}
+	}
+	if _, err := d.SetDestination(1, LocationObject{}); err != ErrDroneFlying {
+		t.Errorf("droneUsecase.SetDestination() of a flying drone error = %v, want %v", err, ErrDroneFlying)
+	}
+	if err := d.ChangeDroneState(1, StateIdle); err != nil {
+		t.Fatalf("droneUsecase.ChangeDroneState() error = %v", err)
+	}
+	if drone, _ := droneRepo.Get(1); drone.DestinationLatitude != nil {
+		t.Errorf("destination of the IDLE drone = %v, want none", *drone.DestinationLatitude)
+	}
+}
+
+func Test_droneUsecase_ListDronesNearby(t *testing.T) {
+	d, droneRepo, _ := newMemoryDroneUsecase(t, repo.Drone{SerialNumber: "far", Latitude: 1})
+	createDrones(t, droneRepo,
+		repo.Drone{SerialNumber: "near", Latitude: 0.01},
+		repo.Drone{SerialNumber: "here"},
+	)
+	if _, err := d.UpdatePosition(1, LocationObject{Latitude: 0.5}); err != nil {
+		t.Fatalf("droneUsecase.UpdatePosition() error = %v", err)
+	}
+	tests := []struct {
+		name    string
+		query   NearbyQuery
+		want    []string
+		wantErr bool
+	}{
+		{name: "test drones within 10km", query: NearbyQuery{Radius: 10}, want: []string{"here", "near"}},
+		{name: "test drones within 100km", query: NearbyQuery{Radius: 100}, want: []string{"here", "near", "far"}},
+		{name: "test drones around another point", query: NearbyQuery{Latitude: 0.5, Radius: 1}, want: []string{"far"}},
+		{name: "test drones without radius", query: NearbyQuery{}, wantErr: true},
+		{name: "test drones around a point out of the map", query: NearbyQuery{Longitude: 200, Radius: 1}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			drones, err := d.ListDronesNearby(tt.query)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("droneUsecase.ListDronesNearby() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			got := []string{}
+			for _, drone := range drones {
+				got = append(got, drone.SerialNumber)
+			}
+			if !tt.wantErr && len(got) != len(tt.want) {
+				t.Fatalf("droneUsecase.ListDronesNearby() = %v, want %v", got, tt.want)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("droneUsecase.ListDronesNearby() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
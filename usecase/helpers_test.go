@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"drone/v2/clock"
+	repo "drone/v2/repository"
+	"testing"
+	"time"
+)
+
+var testTime = time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+// testMedications are the medications "code" and "other" of weight 10.
+var testMedications = []repo.Medication{
+	{Name: "medication", Code: "code", Weight: 10},
+	{Name: "other medication", Code: "other", Weight: 10},
+}
+
+// createDrones stores the fixtures and returns their ids in order.
+func createDrones(t *testing.T, drones repo.IDroneRepository, fixtures ...repo.Drone) []int {
+	t.Helper()
+	ids := make([]int, 0, len(fixtures))
+	for _, drone := range fixtures {
+		drone := drone
+		id, err := drones.Create(&drone)
+		if err != nil {
+			t.Fatalf("Can't create drone %s: %v", drone.SerialNumber, err)
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// createMedications stores the fixtures.
+func createMedications(t *testing.T, medications repo.IMedicationRepository, fixtures ...repo.Medication) {
+	t.Helper()
+	for _, medication := range fixtures {
+		medication := medication
+		if _, err := medications.Create(&medication); err != nil {
+			t.Fatalf("Can't create medication %s: %v", medication.Code, err)
+		}
+	}
+}
+
+// newMemoryDroneUsecase builds the usecase on in-memory repositories holding the given drone
+// and the testMedications, the time is read from a fake clock at testTime.
+func newMemoryDroneUsecase(t *testing.T, drone repo.Drone) (*droneUsecase, repo.IDroneRepository, repo.ILogRepository) {
+	t.Helper()
+	clock := clock.NewFake(testTime)
+	logRepo := repo.NewMemoryLogRepository(clock)
+	droneRepo := repo.NewMemoryDroneRepo(logRepo)
+	createDrones(t, droneRepo, drone)
+	medicationRepo := repo.NewMemoryMedicationRepo()
+	createMedications(t, medicationRepo, testMedications...)
+	return &droneUsecase{
+		droneRepo:         droneRepo,
+		medicationRepo:    medicationRepo,
+		jobs:              newLoadingQueue(loadingQueueSize, clock),
+		minLoadingBattery: 25,
+		batteries:         newBatteryMeter(),
+	}, droneRepo, logRepo
+}
@@ -0,0 +1,376 @@
+package usecase
+
+import (
+	"drone/v2/clock"
+	repo "drone/v2/repository"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	JobPending = "PENDING"
+	JobRunning = "RUNNING"
+	JobDone    = "DONE"
+	JobFailed  = "FAILED"
+)
+
+const (
+	loadingWorkers     = 4
+	loadingQueueSize   = 100
+	loadingTimePerGram = 10 * time.Millisecond
+	// finished jobs are kept to be reported for this duration
+	loadingJobRetention = time.Hour
+	// times a job reloads the drone when it was modified while the job was adding the medication
+	loadingRetries = 3
+)
+
+var (
+	ErrJobNotFound      = errors.New("loading job is not exist")
+	ErrLoadingQueueFull = errors.New("loading queue is full, try again later")
+	ErrJobCanceled      = errors.New("loading job was canceled")
+)
+
+// LoadingStateError is returned by a loading job when its drone left the LOADING state before the medication was added.
+type LoadingStateError struct {
+	DroneID int
+	State   string
+}
+
+func (e *LoadingStateError) Error() string {
+	return fmt.Sprintf("drone %d is %s and can not be loaded", e.DroneID, e.State)
+}
+
+// LoadingJob tracks loading a medication onto a drone in the background.
+type LoadingJob struct {
+	ID         int           `json:"id"`
+	DroneID    int           `json:"drone_id"`
+	Code       string        `json:"code"`
+	Quantity   int           `json:"quantity"`
+	LotNumber  string        `json:"lot_number"`
+	Weight     float32       `json:"weight"`
+	Status     string        `json:"status"`
+	Progress   int           `json:"progress"`
+	Error      string        `json:"error,omitempty"`
+	CreatedAt  time.Time     `json:"created_at"`
+	StartedAt  time.Time     `json:"started_at"`
+	FinishedAt time.Time     `json:"finished_at"`
+	Duration   time.Duration `json:"-"`
+	// canceled jobs do not load their medication, itemID is the load item a job added to the drone
+	canceled bool
+	itemID   int
+}
+
+func (j LoadingJob) item() *repo.LoadItem {
+	return &repo.LoadItem{
+		MedicationCode: j.Code,
+		Quantity:       j.Quantity,
+		LotNumber:      j.LotNumber,
+		Weight:         j.Weight,
+	}
+}
+
+// loadingQueue keeps the loading jobs and hands them to a pool of workers,
+// it counts the unfinished jobs of every drone so the drone is only marked
+// as loaded after its last job finished.
+type loadingQueue struct {
+	mu      sync.Mutex
+	lastID  int
+	jobs    map[int]*LoadingJob
+	pending map[int]int
+	queue   chan int
+	// reserved are the slots of the queue taken by jobs that are not pushed yet
+	reserved int
+	drones   sync.Map
+	clock    clock.Clock
+}
+
+func newLoadingQueue(size int, clock clock.Clock) *loadingQueue {
+	return &loadingQueue{
+		jobs:    map[int]*LoadingJob{},
+		pending: map[int]int{},
+		queue:   make(chan int, size),
+		clock:   clock,
+	}
+}
+
+// lockDrone serializes the load requests of one drone so each request
+// validates the drone against the jobs added before it.
+func (q *loadingQueue) lockDrone(droneID int) func() {
+	lock, _ := q.drones.LoadOrStore(droneID, &sync.Mutex{})
+	mu := lock.(*sync.Mutex)
+	mu.Lock()
+	return mu.Unlock
+}
+
+// reserve takes a slot of the queue for a job that is pushed later, so a load request is refused
+// before the drone is moved and the push never blocks.
+func (q *loadingQueue) reserve() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.queue)+q.reserved >= cap(q.queue) {
+		return ErrLoadingQueueFull
+	}
+	q.reserved++
+	return nil
+}
+
+// release gives back a reserved slot whose job was not added.
+func (q *loadingQueue) release() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.reserved--
+}
+
+// add registers the job as pending for its drone, the job is not picked by
+// workers until it is pushed.
+func (q *loadingQueue) add(job LoadingJob) LoadingJob {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.prune()
+	q.lastID++
+	job.ID = q.lastID
+	job.Status = JobPending
+	job.CreatedAt = q.clock.Now()
+	q.jobs[job.ID] = &job
+	q.pending[job.DroneID]++
+	return job
+}
+
+// prune drops the finished jobs that passed the retention duration.
+func (q *loadingQueue) prune() {
+	for id, job := range q.jobs {
+		if !job.FinishedAt.IsZero() && q.clock.Now().Sub(job.FinishedAt) > loadingJobRetention {
+			delete(q.jobs, id)
+		}
+	}
+}
+
+// push queues the job into the slot reserved for it.
+func (q *loadingQueue) push(id int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.reserved--
+	q.queue <- id
+}
+
+func (q *loadingQueue) get(id int) (LoadingJob, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	job, found := q.jobs[id]
+	if !found {
+		return LoadingJob{}, ErrJobNotFound
+	}
+	result := *job
+	if result.Status == JobRunning && result.Duration > 0 {
+		result.Progress = int(q.clock.Now().Sub(result.StartedAt) * 100 / result.Duration)
+		if result.Progress > 99 {
+			result.Progress = 99
+		}
+	}
+	return result, nil
+}
+
+func (q *loadingQueue) pendingFor(droneID int) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.pending[droneID]
+}
+
+// pendingWeight is the weight of the unfinished jobs of the drone.
+func (q *loadingQueue) pendingWeight(droneID int) float32 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var weight float32
+	for _, job := range q.jobs {
+		if job.DroneID == droneID && (job.Status == JobPending || job.Status == JobRunning) {
+			weight += job.Weight
+		}
+	}
+	return weight
+}
+
+// cancel keeps the job from loading its medication and returns the load item it added already, 0 if none.
+// The drone of the job is held so a running job either added its item or never does.
+func (q *loadingQueue) cancel(id int) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	job, found := q.jobs[id]
+	if !found {
+		return 0
+	}
+	job.canceled = true
+	return job.itemID
+}
+
+func (q *loadingQueue) isCanceled(id int) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.jobs[id].canceled
+}
+
+// loaded records the load item the job added to the drone.
+func (q *loadingQueue) loaded(id int, itemID int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.jobs[id].itemID = itemID
+}
+
+func (q *loadingQueue) started(id int, duration time.Duration) LoadingJob {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	job := q.jobs[id]
+	job.Status = JobRunning
+	job.StartedAt = q.clock.Now()
+	job.Duration = duration
+	return *job
+}
+
+// finish records the result of the job and reports whether it was the last
+// unfinished job of its drone.
+func (q *loadingQueue) finish(id int, err error) (LoadingJob, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	job := q.jobs[id]
+	if err != nil {
+		job.Status = JobFailed
+		job.Error = err.Error()
+	} else {
+		job.Status = JobDone
+		job.Progress = 100
+	}
+	job.FinishedAt = q.clock.Now()
+	q.pending[job.DroneID]--
+	last := q.pending[job.DroneID] == 0
+	if last {
+		delete(q.pending, job.DroneID)
+	}
+	return *job, last
+}
+
+// start runs the workers, every job is loaded by load and drained is called
+// once a drone has no more unfinished jobs.
+func (q *loadingQueue) start(workers int, load func(job LoadingJob) error, drained func(droneID int)) {
+	for i := 0; i < workers; i++ {
+		go func() {
+			for id := range q.queue {
+				q.run(id, load, drained)
+			}
+		}()
+	}
+}
+
+func (q *loadingQueue) run(id int, load func(job LoadingJob) error, drained func(droneID int)) {
+	q.mu.Lock()
+	job := *q.jobs[id]
+	q.mu.Unlock()
+	job, last := q.finish(id, load(job))
+	if last {
+		drained(job.DroneID)
+	}
+}
+
+func (d *droneUsecase) loadingDuration(weight float32) time.Duration {
+	return time.Duration(weight * float32(d.loadingTimePerGram))
+}
+
+// loadMedication simulates the loading time based on the medication weight then
+// attaches the medication to the drone, the drone is validated again as it may
+// have changed since the job was added.
+func (d *droneUsecase) loadMedication(job LoadingJob) error {
+	job = d.jobs.started(job.ID, d.loadingDuration(job.Weight))
+	d.jobs.clock.Sleep(job.Duration)
+	unlock := d.jobs.lockDrone(job.DroneID)
+	defer unlock()
+	if d.jobs.isCanceled(job.ID) {
+		return ErrJobCanceled
+	}
+	for attempt := 0; attempt < loadingRetries; attempt++ {
+		drone, err := d.droneRepo.Get(job.DroneID)
+		if err != nil {
+			return err
+		}
+		if drone.State != StateLoading {
+			return &LoadingStateError{DroneID: drone.ID, State: drone.State}
+		}
+		if err := validateDroneForLoadingMedication(drone, job.Weight, d.minLoadingBattery); err != nil {
+			return err
+		}
+		item := job.item()
+		err = d.droneRepo.AddMedication(job.DroneID, drone.Version, item)
+		if err == nil {
+			d.jobs.loaded(job.ID, item.ID)
+			d.publishEvent(EventMedicationLoaded, job.DroneID, MedicationLoadedEvent{
+				Code:      job.Code,
+				Quantity:  job.Quantity,
+				LotNumber: job.LotNumber,
+				Weight:    job.Weight,
+			})
+		}
+		if !errors.Is(err, ErrConcurrentUpdate) {
+			return err
+		}
+	}
+	return ErrConcurrentUpdate
+}
+
+// finishLoading marks the drone as loaded after its last loading job finished, a drone
+// that got no medication because all its jobs failed goes back to IDLE.
+func (d *droneUsecase) finishLoading(droneID int) {
+	// a load request holds the drone while it adds a job, the drone keeps loading for that job
+	unlock := d.jobs.lockDrone(droneID)
+	defer unlock()
+	if d.jobs.pendingFor(droneID) > 0 {
+		return
+	}
+	drone, err := d.droneRepo.Get(droneID)
+	if err != nil {
+		return
+	}
+	if drone.State != StateLoading {
+		return
+	}
+	to := StateLoaded
+	if len(drone.Medications) == 0 {
+		to = StateIdle
+	}
+	if err := d.transition(drone, to); err != nil {
+		log.Println(err.Error())
+	}
+}
+
+// CancelLoadingJobs takes back the loading jobs of the drone, the jobs that did not load their medication
+// never do and the medications of the others are unloaded. A drone left empty without jobs goes back to IDLE.
+func (d *droneUsecase) CancelLoadingJobs(droneID int, jobIDs []int) error {
+	unlock := d.jobs.lockDrone(droneID)
+	defer unlock()
+	items := []int{}
+	for _, id := range jobIDs {
+		if itemID := d.jobs.cancel(id); itemID > 0 {
+			items = append(items, itemID)
+		}
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	drone, err := d.droneRepo.Get(droneID)
+	if err != nil {
+		return droneError(err)
+	}
+	if err := d.droneRepo.RemoveItems(droneID, drone.Version, items); err != nil {
+		return err
+	}
+	if drone, err = d.droneRepo.Get(droneID); err != nil {
+		return droneError(err)
+	}
+	if len(drone.Medications) > 0 || d.jobs.pendingFor(droneID) > 0 || (drone.State != StateLoading && drone.State != StateLoaded) {
+		return nil
+	}
+	return d.transition(drone, StateIdle)
+}
+
+func (d *droneUsecase) GetLoadingJob(id int) (LoadingJob, error) {
+	return d.jobs.get(id)
+}
@@ -0,0 +1,195 @@
+package usecase
+
+import (
+	"drone/v2/clock"
+	repo "drone/v2/repository"
+	mosks "drone/v2/repository/mocks"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func Test_droneUsecase_LoadingJob(t *testing.T) {
+	clock := clock.NewFake(testTime)
+	droneRepo := repo.NewMemoryDroneRepo(nil)
+	droneRepo.Create(&repo.Drone{SerialNumber: "serial 1", Weight: 500, State: StateIdle, BatteryCapacity: 100})
+	d := &droneUsecase{
+		droneRepo:          droneRepo,
+		medicationRepo:     mosks.NewMedicationRepoMock(),
+		jobs:               newLoadingQueue(1, clock),
+		loadingTimePerGram: loadingTimePerGram,
+	}
+	job, err := d.LoadingMedication(1, LoadObject{Code: "code", Quantity: 3})
+	if err != nil {
+		t.Fatalf("droneUsecase.LoadingMedication() error = %v", err)
+	}
+	if job.Status != JobPending || job.Weight != 30 {
+		t.Errorf("droneUsecase.LoadingMedication() = %+v, want pending job with weight 30", job)
+	}
+	if _, err := d.LoadingMedication(1, LoadObject{Code: "code"}); err != ErrLoadingQueueFull {
+		t.Errorf("droneUsecase.LoadingMedication() error = %v, wantErr %v", err, ErrLoadingQueueFull)
+	}
+
+	drained := make(chan int, 1)
+	d.jobs.start(1, d.loadMedication, func(droneID int) { drained <- droneID })
+	// loading 30 grams takes 300ms of the clock
+	clock.BlockUntil(1)
+	clock.Advance(150 * time.Millisecond)
+	if got, err := d.GetLoadingJob(job.ID); err != nil || got.Status != JobRunning || got.Progress != 50 {
+		t.Errorf("droneUsecase.GetLoadingJob() = %+v, %v, want running job half way", got, err)
+	}
+	clock.Advance(150 * time.Millisecond)
+	select {
+	case droneID := <-drained:
+		if droneID != 1 {
+			t.Errorf("drained drone = %v, want %v", droneID, 1)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("loading job was not finished")
+	}
+	got, err := d.GetLoadingJob(job.ID)
+	if err != nil {
+		t.Fatalf("droneUsecase.GetLoadingJob() error = %v", err)
+	}
+	if got.Status != JobDone || got.Progress != 100 {
+		t.Errorf("droneUsecase.GetLoadingJob() = %+v, want done job", got)
+	}
+	if _, err := d.GetLoadingJob(job.ID + 1); err != ErrJobNotFound {
+		t.Errorf("droneUsecase.GetLoadingJob() error = %v, wantErr %v", err, ErrJobNotFound)
+	}
+}
+
+func Test_droneUsecase_loadingDuration(t *testing.T) {
+	d := &droneUsecase{loadingTimePerGram: loadingTimePerGram}
+	if got, want := d.loadingDuration(50), 500*time.Millisecond; got != want {
+		t.Errorf("droneUsecase.loadingDuration() = %v, want %v", got, want)
+	}
+}
+
+func Test_droneUsecase_LoadingMedication_ReservesPendingWeight(t *testing.T) {
+	d := &droneUsecase{
+		droneRepo:      mosks.NewDroneRepoMock(),
+		medicationRepo: mosks.NewMedicationRepoMock(),
+		jobs:           newLoadingQueue(loadingQueueSize, clock.System),
+	}
+	if _, err := d.LoadingMedication(1, LoadObject{Code: "code", Quantity: 30}); err != nil {
+		t.Fatalf("droneUsecase.LoadingMedication() error = %v", err)
+	}
+	_, err := d.LoadingMedication(1, LoadObject{Code: "code", Quantity: 30})
+	want := fmt.Sprintf(`drone can not be loaded with %f weight, because current weight is %f and Max weight is %f`, 300.0, 300.0, 500.0)
+	if err == nil || err.Error() != want {
+		t.Errorf("droneUsecase.LoadingMedication() error = %v, wantErr %v", err, want)
+	}
+}
+
+func Test_droneUsecase_loadMedication_DroneLeftLoading(t *testing.T) {
+	d, droneRepo, _ := newMemoryDroneUsecase(t, repo.Drone{SerialNumber: "serial 1", Weight: 500, State: StateIdle, BatteryCapacity: 100})
+	job, err := d.LoadingMedication(1, LoadObject{Code: "code"})
+	if err != nil {
+		t.Fatalf("droneUsecase.LoadingMedication() error = %v", err)
+	}
+	if err := d.ChangeDroneState(1, StateLoaded); err != ErrLoadingInProgress {
+		t.Errorf("droneUsecase.ChangeDroneState() error = %v, wantErr %v", err, ErrLoadingInProgress)
+	}
+	// the drone is moved by the repository directly, the job finds it out of LOADING
+	if err := droneRepo.ChangeDroneState(1, StateLoading, StateIdle); err != nil {
+		t.Fatalf("Can't move drone: %v", err)
+	}
+	d.jobs.run(job.ID, d.loadMedication, d.finishLoading)
+	if got, _ := d.GetLoadingJob(job.ID); got.Status != JobFailed || got.Error != "drone 1 is IDLE and can not be loaded" {
+		t.Errorf("droneUsecase.GetLoadingJob() = %+v, want job failed on the idle drone", got)
+	}
+	if drone, _ := droneRepo.Get(1); drone.State != StateIdle || len(drone.Medications) != 0 {
+		t.Errorf("drone = %+v, want it idle without medications", drone)
+	}
+}
+
+func Test_droneUsecase_finishLoading_AllJobsFailed(t *testing.T) {
+	d, droneRepo, _ := newMemoryDroneUsecase(t, repo.Drone{SerialNumber: "serial 1", Weight: 500, State: StateIdle, BatteryCapacity: 100})
+	job, err := d.LoadingMedication(1, LoadObject{Code: "code"})
+	if err != nil {
+		t.Fatalf("droneUsecase.LoadingMedication() error = %v", err)
+	}
+	// the battery drops under the loading level before the job runs
+	droneRepo.UpdateBatteries(map[int]int{1: 20}, "")
+	d.jobs.run(job.ID, d.loadMedication, d.finishLoading)
+	if got, _ := d.GetLoadingJob(job.ID); got.Status != JobFailed {
+		t.Errorf("droneUsecase.GetLoadingJob() = %+v, want failed job", got)
+	}
+	if drone, _ := droneRepo.Get(1); drone.State != StateIdle {
+		t.Errorf("drone state = %v, want %v once its last job failed", drone.State, StateIdle)
+	}
+}
+
+func Test_droneUsecase_finishLoading_WaitsForLoadRequest(t *testing.T) {
+	d, droneRepo, _ := newMemoryDroneUsecase(t, repo.Drone{
+		SerialNumber:    "serial 1",
+		Weight:          500,
+		State:           StateLoading,
+		BatteryCapacity: 100,
+		CurrentPayload:  10,
+		Medications:     []repo.LoadItem{{MedicationCode: "code", Quantity: 1, Weight: 10}},
+	})
+	// a load request holds the drone while the last job of the drone finishes
+	unlock := d.jobs.lockDrone(1)
+	finished := make(chan struct{})
+	go func() {
+		d.finishLoading(1)
+		close(finished)
+	}()
+	d.jobs.add(LoadingJob{DroneID: 1, Code: "code", Quantity: 1, Weight: 10})
+	unlock()
+	<-finished
+	if drone, _ := droneRepo.Get(1); drone.State != StateLoading {
+		t.Errorf("drone state = %v, want %v for the job of the load request", drone.State, StateLoading)
+	}
+}
+
+func Test_loadingQueue_reserve(t *testing.T) {
+	q := newLoadingQueue(2, clock.System)
+	steps := []struct {
+		name    string
+		step    func() error
+		wantErr error
+	}{
+		{name: "first slot", step: q.reserve},
+		{name: "first job pushed", step: func() error { q.push(q.add(LoadingJob{DroneID: 1}).ID); return nil }},
+		{name: "last slot", step: q.reserve},
+		{name: "queue full", step: q.reserve, wantErr: ErrLoadingQueueFull},
+		{name: "slot released", step: func() error { q.release(); return nil }},
+		{name: "released slot", step: q.reserve},
+		{name: "queue full again", step: q.reserve, wantErr: ErrLoadingQueueFull},
+	}
+	for _, step := range steps {
+		if err := step.step(); err != step.wantErr {
+			t.Errorf("%s: error = %v, wantErr %v", step.name, err, step.wantErr)
+		}
+	}
+}
+
+func Test_droneUsecase_CancelLoadingJobs(t *testing.T) {
+	d, droneRepo, _ := newMemoryDroneUsecase(t, repo.Drone{SerialNumber: "serial 1", Weight: 500, State: StateIdle, BatteryCapacity: 100})
+	loaded, err := d.LoadingMedication(1, LoadObject{Code: "code"})
+	if err != nil {
+		t.Fatalf("droneUsecase.LoadingMedication() error = %v", err)
+	}
+	pending, err := d.LoadingMedication(1, LoadObject{Code: "other"})
+	if err != nil {
+		t.Fatalf("droneUsecase.LoadingMedication() error = %v", err)
+	}
+	d.jobs.run(loaded.ID, d.loadMedication, d.finishLoading)
+
+	if err := d.CancelLoadingJobs(1, []int{loaded.ID, pending.ID}); err != nil {
+		t.Fatalf("droneUsecase.CancelLoadingJobs() error = %v", err)
+	}
+	if drone, _ := droneRepo.Get(1); drone.State != StateLoading || drone.CurrentPayload != 0 {
+		t.Errorf("drone = %v with payload %v, want LOADING and the loaded medication taken off", drone.State, drone.CurrentPayload)
+	}
+	d.jobs.run(pending.ID, d.loadMedication, d.finishLoading)
+	if got, _ := d.GetLoadingJob(pending.ID); got.Status != JobFailed || got.Error != ErrJobCanceled.Error() {
+		t.Errorf("droneUsecase.GetLoadingJob() = %+v, want the canceled job failed", got)
+	}
+	if drone, _ := droneRepo.Get(1); drone.State != StateIdle || drone.CurrentPayload != 0 || len(drone.Medications) != 0 {
+		t.Errorf("drone = %+v, want it idle and empty", drone)
+	}
+}
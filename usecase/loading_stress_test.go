@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	repo "drone/v2/repository"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_droneUsecase_LoadingMedication_ParallelLoads(t *testing.T) {
+	d, droneRepo, _ := newMemoryDroneUsecase(t, repo.Drone{
+		SerialNumber:    "serial 1",
+		Weight:          500,
+		State:           StateIdle,
+		BatteryCapacity: 100,
+	})
+	drained := make(chan int, loadingQueueSize)
+	d.jobs.start(loadingWorkers, d.loadMedication, func(droneID int) {
+		d.finishLoading(droneID)
+		drained <- droneID
+	})
+
+	// 50 loads of 30 weight each, only 16 of them fit in the drone
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	accepted := 0
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := d.LoadingMedication(1, LoadObject{Code: "code", Quantity: 3}); err == nil {
+				mu.Lock()
+				accepted++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if accepted != 16 {
+		t.Errorf("accepted loads = %v, want %v", accepted, 16)
+	}
+
+	deadline := time.After(5 * time.Second)
+	for finished := false; !finished; {
+		select {
+		case <-drained:
+			finished = d.jobs.pendingFor(1) == 0
+		case <-deadline:
+			t.Fatal("loading jobs were not finished")
+		}
+	}
+	drone, _ := droneRepo.Get(1)
+	if drone.CurrentPayload > drone.Weight {
+		t.Errorf("drone payload = %v, exceeds max weight %v", drone.CurrentPayload, drone.Weight)
+	}
+	if drone.CurrentPayload != 480 || len(drone.Medications) != 16 {
+		t.Errorf("drone payload = %v with %v medications, want 480 with 16", drone.CurrentPayload, len(drone.Medications))
+	}
+	if drone.State != StateLoaded {
+		t.Errorf("drone state = %v, want %v", drone.State, StateLoaded)
+	}
+}
@@ -1,31 +1,130 @@
 package usecase
 
 import (
+	repo "drone/v2/repository"
 	"drone/v2/utils"
+	"errors"
+	"fmt"
 
 	"github.com/asaskevich/govalidator"
 )
 
+var ErrMedicationNotFound = errors.New("medication is not exist")
+
+// MedicationInUseError is returned when a medication is deleted while a pending order or a loaded drone still references it.
+type MedicationInUseError struct {
+	Code    string
+	OrderID int
+	DroneID int
+}
+
+func (e *MedicationInUseError) Error() string {
+	if e.OrderID != 0 {
+		return fmt.Sprintf("medication %s is used by order %d", e.Code, e.OrderID)
+	}
+	return fmt.Sprintf("medication %s is loaded on drone %d", e.Code, e.DroneID)
+}
+
 type IMedicationUsecase interface {
-	RegisterMedication(object MedicationObject) (int, error)
+	RegisterMedication(object MedicationObject) (string, error)
+	GetMedication(code string) (repo.Medication, error)
+	ListMedications() ([]repo.Medication, error)
+	UpdateMedication(code string, object MedicationObject) error
+	DeleteMedication(code string) error
 }
 
 type medicationUsecase struct {
+	medicationRepo repo.IMedicationRepository
+	orderRepo      repo.IOrderRepository
+	droneRepo      repo.IDroneRepository
+}
+
+func NewMedicationUsecase(m repo.IMedicationRepository, o repo.IOrderRepository, d repo.IDroneRepository) IMedicationUsecase {
+	return &medicationUsecase{
+		medicationRepo: m,
+		orderRepo:      o,
+		droneRepo:      d,
+	}
+}
+
+func (medication *medicationUsecase) RegisterMedication(object MedicationObject) (string, error) {
+	data, err := validateMedication(object)
+	if err != nil {
+		return "", err
+	}
+	return medication.medicationRepo.Create(data)
+}
+
+func (medication *medicationUsecase) GetMedication(code string) (repo.Medication, error) {
+	result, err := medication.medicationRepo.Get(code)
+	return result, medicationError(err)
 }
 
-func NewMedicationUsecase() IMedicationUsecase {
-	return &medicationUsecase{}
+func (medication *medicationUsecase) ListMedications() ([]repo.Medication, error) {
+	return medication.medicationRepo.List()
 }
 
-func (medication *medicationUsecase) RegisterMedication(object MedicationObject) (int, error) {
+func (medication *medicationUsecase) UpdateMedication(code string, object MedicationObject) error {
+	object.Code = code
+	data, err := validateMedication(object)
+	if err != nil {
+		return err
+	}
+	return medicationError(medication.medicationRepo.Update(data))
+}
+
+// DeleteMedication removes the medication from the catalog once no order that is not loaded or failed
+// and no drone references it anymore.
+func (medication *medicationUsecase) DeleteMedication(code string) error {
+	if err := medication.checkUnused(code); err != nil {
+		return err
+	}
+	return medicationError(medication.medicationRepo.Delete(code))
+}
+
+func (medication *medicationUsecase) checkUnused(code string) error {
+	for _, status := range []string{OrderPending, OrderAssigned} {
+		orders, err := medication.orderRepo.List(status)
+		if err != nil {
+			return err
+		}
+		for _, order := range orders {
+			for _, item := range order.Items {
+				if item.MedicationCode == code {
+					return &MedicationInUseError{Code: code, OrderID: order.ID}
+				}
+			}
+		}
+	}
+	drones, _, err := medication.droneRepo.List(repo.DroneFilter{})
+	if err != nil {
+		return err
+	}
+	for _, drone := range drones {
+		for _, item := range drone.Medications {
+			if item.MedicationCode == code {
+				return &MedicationInUseError{Code: code, DroneID: drone.ID}
+			}
+		}
+	}
+	return nil
+}
+
+func validateMedication(object MedicationObject) (*repo.Medication, error) {
 	err := utils.ValidateMedicationName(object.Name)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
-	droneValidate, err := govalidator.ValidateStruct(object)
-	if err != nil || !droneValidate {
-		return 0, err
+	medicationValidate, err := govalidator.ValidateStruct(object)
+	if err != nil || !medicationValidate {
+		return nil, err
+	}
+	return utils.TypeConverter[repo.Medication](&object)
+}
+
+func medicationError(err error) error {
+	if errors.Is(err, repo.ErrRecordNotFound) {
+		return ErrMedicationNotFound
 	}
-	// create medication
-	return 0, nil
+	return err
 }
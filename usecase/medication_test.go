@@ -1,6 +1,10 @@
 package usecase
 
 import (
+	"drone/v2/clock"
+	repo "drone/v2/repository"
+	mosks "drone/v2/repository/mocks"
+	"errors"
 	"testing"
 )
 
@@ -12,7 +16,7 @@ func Test_medicationUsecase_RegisterMedication(t *testing.T) {
 		name       string
 		medication *medicationUsecase
 		args       args
-		want       int
+		want       string
 		wantErr    bool
 		errorMsg   string
 	}{
@@ -24,7 +28,7 @@ func Test_medicationUsecase_RegisterMedication(t *testing.T) {
 					Weight: 10,
 				},
 			},
-			want:     0,
+			want:     "",
 			wantErr:  true,
 			errorMsg: "Medication name is not provided",
 		},
@@ -36,7 +40,7 @@ func Test_medicationUsecase_RegisterMedication(t *testing.T) {
 					Weight: 10,
 				},
 			},
-			want:     0,
+			want:     "",
 			wantErr:  true,
 			errorMsg: "Medication code is not provided",
 		},
@@ -48,7 +52,7 @@ func Test_medicationUsecase_RegisterMedication(t *testing.T) {
 					Code: "code",
 				},
 			},
-			want:     0,
+			want:     "",
 			wantErr:  true,
 			errorMsg: "Medication weight is not provided",
 		},
@@ -57,7 +61,7 @@ func Test_medicationUsecase_RegisterMedication(t *testing.T) {
 			args: args{
 				object: MedicationObject{},
 			},
-			want:     0,
+			want:     "",
 			wantErr:  true,
 			errorMsg: "Medication code is not provided;Medication name is not provided;Medication weight is not provided",
 		},
@@ -71,7 +75,7 @@ func Test_medicationUsecase_RegisterMedication(t *testing.T) {
 					Image:  "invaild _url_format",
 				},
 			},
-			want:     0,
+			want:     "",
 			wantErr:  true,
 			errorMsg: "image: invaild _url_format does not validate as url",
 		},
@@ -85,7 +89,7 @@ func Test_medicationUsecase_RegisterMedication(t *testing.T) {
 					Image:  "http://test/image",
 				},
 			},
-			want:     0,
+			want:     "code",
 			wantErr:  false,
 			errorMsg: "",
 		},
@@ -99,14 +103,16 @@ func Test_medicationUsecase_RegisterMedication(t *testing.T) {
 					Image:  "http://test/image",
 				},
 			},
-			want:     0,
+			want:     "",
 			wantErr:  true,
 			errorMsg: "weight: -1 does not validate as range(1|500)",
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			medication := &medicationUsecase{}
+			medication := &medicationUsecase{
+				medicationRepo: mosks.NewMedicationRepoMock(),
+			}
 			got, err := medication.RegisterMedication(tt.args.object)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("medicationUsecase.RegisterMedication() error = %v, wantErr %v", err, tt.wantErr)
@@ -118,3 +124,131 @@ func Test_medicationUsecase_RegisterMedication(t *testing.T) {
 		})
 	}
 }
+
+func Test_medicationUsecase_GetMedication(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    string
+		want    string
+		wantErr error
+	}{
+		{
+			name:    "test get medication that exist",
+			code:    "code",
+			want:    "medication",
+			wantErr: nil,
+		},
+		{
+			name:    "test get medication that not exist",
+			code:    "not exist",
+			want:    "",
+			wantErr: ErrMedicationNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			medication := &medicationUsecase{
+				medicationRepo: mosks.NewMedicationRepoMock(),
+			}
+			got, err := medication.GetMedication(tt.code)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("medicationUsecase.GetMedication() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got.Name != tt.want {
+				t.Errorf("medicationUsecase.GetMedication() = %v, want %v", got.Name, tt.want)
+			}
+		})
+	}
+}
+
+func Test_medicationUsecase_UpdateMedication(t *testing.T) {
+	tests := []struct {
+		name     string
+		code     string
+		object   MedicationObject
+		wantErr  bool
+		errorMsg string
+	}{
+		{
+			name: "test update medication with code from path",
+			code: "code",
+			object: MedicationObject{
+				Name:   "medication",
+				Weight: 20,
+			},
+			wantErr: false,
+		},
+		{
+			name: "test can not update medication with invaild data",
+			code: "code",
+			object: MedicationObject{
+				Name: "medication",
+			},
+			wantErr:  true,
+			errorMsg: "Medication weight is not provided",
+		},
+		{
+			name: "test can not update medication that not exist",
+			code: "not exist",
+			object: MedicationObject{
+				Name:   "medication",
+				Weight: 20,
+			},
+			wantErr:  true,
+			errorMsg: ErrMedicationNotFound.Error(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			medication := &medicationUsecase{
+				medicationRepo: mosks.NewMedicationRepoMock(),
+			}
+			err := medication.UpdateMedication(tt.code, tt.object)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("medicationUsecase.UpdateMedication() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil && err.Error() != tt.errorMsg {
+				t.Errorf("medicationUsecase.UpdateMedication() error = %v, wantErr %v", err.Error(), tt.errorMsg)
+			}
+		})
+	}
+}
+
+func Test_medicationUsecase_DeleteMedication(t *testing.T) {
+	clock := clock.NewFake(testTime)
+	medicationRepo := repo.NewMemoryMedicationRepo()
+	for _, code := range []string{"ordered", "failed", "loaded", "free"} {
+		if _, err := medicationRepo.Create(&repo.Medication{Name: "medication " + code, Code: code, Weight: 10}); err != nil {
+			t.Fatalf("Can't create medication: %v", err)
+		}
+	}
+	orderRepo := repo.NewMemoryOrderRepo(clock)
+	orderRepo.Create(&repo.Order{Items: []repo.OrderItem{{MedicationCode: "ordered", Quantity: 1}}})
+	failedID, _ := orderRepo.Create(&repo.Order{Items: []repo.OrderItem{{MedicationCode: "failed", Quantity: 1}}})
+	orderRepo.UpdateStatus(failedID, OrderPending, OrderFailed)
+	droneRepo := repo.NewMemoryDroneRepo(nil)
+	droneID, _ := droneRepo.Create(&repo.Drone{SerialNumber: "serial 1", Weight: 500, State: StateLoading, BatteryCapacity: 100})
+	drone, _ := droneRepo.Get(droneID)
+	droneRepo.AddMedication(droneID, drone.Version, &repo.LoadItem{MedicationCode: "loaded", Quantity: 1, Weight: 10})
+	medication := NewMedicationUsecase(medicationRepo, orderRepo, droneRepo)
+
+	tests := []struct {
+		name     string
+		code     string
+		errorMsg string
+	}{
+		{name: "test can not delete medication of a pending order", code: "ordered", errorMsg: "medication ordered is used by order 1"},
+		{name: "test can not delete medication loaded on a drone", code: "loaded", errorMsg: "medication loaded is loaded on drone 1"},
+		{name: "test delete medication of a failed order", code: "failed"},
+		{name: "test delete medication that is not used", code: "free"},
+		{name: "test can not delete medication that not exist", code: "not exist", errorMsg: ErrMedicationNotFound.Error()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := medication.DeleteMedication(tt.code)
+			if (err != nil) != (tt.errorMsg != "") || err != nil && err.Error() != tt.errorMsg {
+				t.Errorf("medicationUsecase.DeleteMedication() error = %v, wantErr %v", err, tt.errorMsg)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package mocks
+
+import (
+	repo "drone/v2/repository"
+	"drone/v2/usecase"
+	"errors"
+	"fmt"
+)
+
+type IAlertMockUsecase interface {
+	ListAlerts(droneID int, status string) ([]repo.Alert, error)
+	GetAlert(id int) (repo.Alert, error)
+	AcknowledgeAlert(id int) (repo.Alert, error)
+	ResolveAlert(id int) (repo.Alert, error)
+}
+
+type alertMockUsecase struct {
+}
+
+// NewAlertMockUsecase has the open alert 1 and the resolved alert 2.
+func NewAlertMockUsecase() IAlertMockUsecase {
+	return &alertMockUsecase{}
+}
+
+func (u alertMockUsecase) ListAlerts(droneID int, status string) ([]repo.Alert, error) {
+	if status != "" && status != usecase.AlertOpen {
+		return nil, errors.New(fmt.Sprintf("alert status %q is not exist", status))
+	}
+	return []repo.Alert{{ID: 1, DroneID: 1, Rule: "fast-drain", Severity: usecase.AlertWarning, Status: usecase.AlertOpen}}, nil
+}
+
+func (u alertMockUsecase) GetAlert(id int) (repo.Alert, error) {
+	switch id {
+	case 1:
+		return repo.Alert{ID: id, DroneID: 1, Rule: "fast-drain", Severity: usecase.AlertWarning, Status: usecase.AlertOpen}, nil
+	case 2:
+		return repo.Alert{ID: id, DroneID: 1, Rule: "fast-drain", Severity: usecase.AlertWarning, Status: usecase.AlertResolved}, nil
+	}
+	return repo.Alert{}, usecase.ErrAlertNotFound
+}
+
+func (u alertMockUsecase) AcknowledgeAlert(id int) (repo.Alert, error) {
+	return u.changeStatus(id, usecase.AlertAcknowledged, "acknowledged")
+}
+
+func (u alertMockUsecase) ResolveAlert(id int) (repo.Alert, error) {
+	return u.changeStatus(id, usecase.AlertResolved, "resolved")
+}
+
+func (u alertMockUsecase) changeStatus(id int, status string, action string) (repo.Alert, error) {
+	alert, err := u.GetAlert(id)
+	if err != nil {
+		return repo.Alert{}, err
+	}
+	if alert.Status == usecase.AlertResolved {
+		return repo.Alert{}, &usecase.AlertStatusError{AlertID: id, Status: alert.Status, Action: action}
+	}
+	alert.Status = status
+	return alert, nil
+}
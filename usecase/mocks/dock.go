@@ -0,0 +1,59 @@
+package mocks
+
+import (
+	repo "drone/v2/repository"
+	"drone/v2/usecase"
+	"time"
+)
+
+type IDockMockUsecase interface {
+	RegisterDock(object usecase.DockObject) (int, error)
+	GetDock(id int) (usecase.DockDetails, error)
+	ListDocks() ([]usecase.DockDetails, error)
+	DockDrone(droneID int, dockID int) (usecase.DroneDetails, error)
+	UndockDrone(droneID int) (usecase.DroneDetails, error)
+	ChargeDockedDrones(elapsed time.Duration)
+}
+
+type dockMockUsecase struct {
+}
+
+func NewDockMockUsecase() IDockMockUsecase {
+	return &dockMockUsecase{}
+}
+
+func (u dockMockUsecase) RegisterDock(object usecase.DockObject) (int, error) {
+	return 1, nil
+}
+
+func (u dockMockUsecase) GetDock(id int) (usecase.DockDetails, error) {
+	if id != 1 {
+		return usecase.DockDetails{}, usecase.ErrDockNotFound
+	}
+	return usecase.DockDetails{Dock: repo.Dock{ID: id, Name: "base", Slots: 2, ChargeRate: 2}, Drones: []int{1}, FreeSlots: 1}, nil
+}
+
+func (u dockMockUsecase) ListDocks() ([]usecase.DockDetails, error) {
+	dock, _ := u.GetDock(1)
+	return []usecase.DockDetails{dock}, nil
+}
+
+func (u dockMockUsecase) DockDrone(droneID int, dockID int) (usecase.DroneDetails, error) {
+	if dockID != 1 {
+		return usecase.DroneDetails{}, usecase.ErrDockNotFound
+	}
+	if droneID != 1 {
+		return usecase.DroneDetails{}, usecase.ErrDockFull
+	}
+	return usecase.DroneDetails{Drone: repo.Drone{ID: droneID, State: usecase.StateIdle, DockID: &dockID}}, nil
+}
+
+func (u dockMockUsecase) UndockDrone(droneID int) (usecase.DroneDetails, error) {
+	if droneID != 1 {
+		return usecase.DroneDetails{}, usecase.ErrDroneNotDocked
+	}
+	return usecase.DroneDetails{Drone: repo.Drone{ID: droneID, State: usecase.StateIdle}}, nil
+}
+
+func (u dockMockUsecase) ChargeDockedDrones(elapsed time.Duration) {
+}
@@ -4,15 +4,34 @@ import (
 	repo "drone/v2/repository"
 	"drone/v2/usecase"
 	"errors"
+	"time"
 )
 
 type IDroneMockUsecase interface {
 	RegisterDrone(object usecase.DorneObject) (int, error)
-	LoadingMedication(id int, medication usecase.MedicationObject) error
+	GetDrone(id int) (usecase.DroneDetails, error)
+	ListDrones(query usecase.DroneQuery) (usecase.DronePage, error)
+	UpdateDrone(id int, object usecase.DroneUpdateObject) (usecase.DroneDetails, error)
+	DecommissionDrone(id int) error
+	LoadingMedication(id int, load usecase.LoadObject) (usecase.LoadingJob, error)
+	GetLoadingJob(id int) (usecase.LoadingJob, error)
+	CancelLoadingJobs(droneID int, jobIDs []int) error
 	CheckLoadingMedication(id int) (string, error)
-	CheckAvailableDroneForLoading() []repo.Drone
+	CheckAvailableDroneForLoading() []usecase.DroneDetails
+	RecalculatePayload(id int) (usecase.DroneDetails, error)
+	UnloadMedication(id int, code string) (usecase.DroneDetails, error)
+	UnloadDrone(id int) (usecase.DroneDetails, error)
 	CheckBatteryLevel(id int) (string, error)
-	CheckDronesBatteries()
+	CheckDronesBatteries(elapsed time.Duration)
+	BatteryChanged(drone repo.Drone, level int, drain float64)
+	ChangeDroneState(id int, state string) error
+	StartDelivery(id int) error
+	MarkDelivered(id int) error
+	ReturnToBase(id int) error
+	UpdatePosition(id int, location usecase.LocationObject) (usecase.DroneDetails, error)
+	SetDestination(id int, location usecase.LocationObject) (usecase.DroneDetails, error)
+	ListDronesNearby(query usecase.NearbyQuery) ([]usecase.NearbyDrone, error)
+	CanReach(id int, location usecase.LocationObject) (usecase.Feasibility, error)
 }
 
 type droneMockUsecase struct {
@@ -26,7 +45,21 @@ func (u droneMockUsecase) RegisterDrone(object usecase.DorneObject) (int, error)
 	return 1, nil
 }
 
-func (u droneMockUsecase) LoadingMedication(id int, medication usecase.MedicationObject) error {
+func (u droneMockUsecase) LoadingMedication(id int, load usecase.LoadObject) (usecase.LoadingJob, error) {
+	if id != 1 {
+		return usecase.LoadingJob{}, usecase.ErrDroneNotFound
+	}
+	return usecase.LoadingJob{ID: 1, DroneID: id, Code: load.Code, Status: usecase.JobPending}, nil
+}
+
+func (u droneMockUsecase) GetLoadingJob(id int) (usecase.LoadingJob, error) {
+	if id != 1 {
+		return usecase.LoadingJob{}, usecase.ErrJobNotFound
+	}
+	return usecase.LoadingJob{ID: 1, DroneID: 1, Status: usecase.JobDone, Progress: 100}, nil
+}
+
+func (u droneMockUsecase) CancelLoadingJobs(droneID int, jobIDs []int) error {
 	return nil
 }
 
@@ -34,14 +67,127 @@ func (u droneMockUsecase) CheckLoadingMedication(id int) (string, error) {
 	return "", errors.New("")
 }
 
-func (u droneMockUsecase) CheckAvailableDroneForLoading() []repo.Drone {
-	return []repo.Drone{}
+func (u droneMockUsecase) CheckAvailableDroneForLoading() []usecase.DroneDetails {
+	return []usecase.DroneDetails{}
+}
+
+func (u droneMockUsecase) RecalculatePayload(id int) (usecase.DroneDetails, error) {
+	if id != 1 {
+		return usecase.DroneDetails{}, usecase.ErrDroneNotFound
+	}
+	return usecase.DroneDetails{}, nil
+}
+
+func (u droneMockUsecase) GetDrone(id int) (usecase.DroneDetails, error) {
+	if id != 1 {
+		return usecase.DroneDetails{}, usecase.ErrDroneNotFound
+	}
+	return usecase.DroneDetails{}, nil
+}
+
+func (u droneMockUsecase) ListDrones(query usecase.DroneQuery) (usecase.DronePage, error) {
+	return usecase.DronePage{Drones: []usecase.DroneDetails{}, Page: 1, PageSize: 20}, nil
+}
+
+func (u droneMockUsecase) UpdateDrone(id int, object usecase.DroneUpdateObject) (usecase.DroneDetails, error) {
+	if id != 1 {
+		return usecase.DroneDetails{}, usecase.ErrDroneNotFound
+	}
+	return usecase.DroneDetails{}, nil
+}
+
+func (u droneMockUsecase) DecommissionDrone(id int) error {
+	if id != 1 {
+		return usecase.ErrDroneNotIdle
+	}
+	return nil
+}
+
+func (u droneMockUsecase) UnloadMedication(id int, code string) (usecase.DroneDetails, error) {
+	if id != 1 {
+		return usecase.DroneDetails{}, usecase.ErrDroneNotFound
+	}
+	if code != "code" {
+		return usecase.DroneDetails{}, usecase.ErrMedicationNotFound
+	}
+	return usecase.DroneDetails{}, nil
+}
+
+func (u droneMockUsecase) UnloadDrone(id int) (usecase.DroneDetails, error) {
+	if id != 1 {
+		return usecase.DroneDetails{}, usecase.ErrDroneNotFound
+	}
+	return usecase.DroneDetails{}, nil
 }
 
 func (u droneMockUsecase) CheckBatteryLevel(id int) (string, error) {
 	return "", errors.New("")
 }
 
-func (u droneMockUsecase) CheckDronesBatteries() {
+func (u droneMockUsecase) CheckDronesBatteries(elapsed time.Duration) {
+
+}
+
+func (u droneMockUsecase) BatteryChanged(drone repo.Drone, level int, drain float64) {
+
+}
+
+func (u droneMockUsecase) ChangeDroneState(id int, state string) error {
+	if id != 1 {
+		return usecase.ErrDroneNotFound
+	}
+	return nil
+}
+
+func (u droneMockUsecase) StartDelivery(id int) error {
+	if id != 1 {
+		return usecase.ErrDroneNotFound
+	}
+	return nil
+}
+
+func (u droneMockUsecase) MarkDelivered(id int) error {
+	if id != 1 {
+		return usecase.ErrDroneNotFound
+	}
+	return nil
+}
+
+func (u droneMockUsecase) ReturnToBase(id int) error {
+	if id != 1 {
+		return usecase.ErrDroneNotFound
+	}
+	return nil
+}
+
+func (u droneMockUsecase) UpdatePosition(id int, location usecase.LocationObject) (usecase.DroneDetails, error) {
+	if id != 1 {
+		return usecase.DroneDetails{}, usecase.ErrDroneNotFound
+	}
+	details := usecase.DroneDetails{}
+	details.ID, details.Latitude, details.Longitude = id, location.Latitude, location.Longitude
+	return details, nil
+}
+
+func (u droneMockUsecase) SetDestination(id int, location usecase.LocationObject) (usecase.DroneDetails, error) {
+	if id != 1 {
+		return usecase.DroneDetails{}, usecase.ErrDroneFlying
+	}
+	details := usecase.DroneDetails{}
+	details.ID, details.DestinationLatitude, details.DestinationLongitude = id, &location.Latitude, &location.Longitude
+	return details, nil
+}
+
+func (u droneMockUsecase) ListDronesNearby(query usecase.NearbyQuery) ([]usecase.NearbyDrone, error) {
+	if query.Radius == 0 {
+		return nil, errors.New("Radius is not provided")
+	}
+	return []usecase.NearbyDrone{}, nil
+}
 
+func (u droneMockUsecase) CanReach(id int, location usecase.LocationObject) (usecase.Feasibility, error) {
+	if id != 1 {
+		return usecase.Feasibility{}, usecase.ErrDroneNotFound
+	}
+	return usecase.Feasibility{DroneID: id, Needed: 40, BatteryLevel: 100, Feasible: true}, nil
 }
@@ -1,11 +1,16 @@
 package mocks
 
 import (
+	repo "drone/v2/repository"
 	"drone/v2/usecase"
 )
 
 type IMedicationMockUsecase interface {
-	RegisterMedication(object usecase.MedicationObject) (int, error)
+	RegisterMedication(object usecase.MedicationObject) (string, error)
+	GetMedication(code string) (repo.Medication, error)
+	ListMedications() ([]repo.Medication, error)
+	UpdateMedication(code string, object usecase.MedicationObject) error
+	DeleteMedication(code string) error
 }
 
 type medicationMockUsecase struct {
@@ -15,6 +20,31 @@ func NewMedicationMockUsecase() IMedicationMockUsecase {
 	return &medicationMockUsecase{}
 }
 
-func (medication *medicationMockUsecase) RegisterMedication(object usecase.MedicationObject) (int, error) {
-	return 0, nil
+func (medication *medicationMockUsecase) RegisterMedication(object usecase.MedicationObject) (string, error) {
+	return object.Code, nil
+}
+
+func (medication *medicationMockUsecase) GetMedication(code string) (repo.Medication, error) {
+	if code != "code" {
+		return repo.Medication{}, usecase.ErrMedicationNotFound
+	}
+	return repo.Medication{Name: "medication", Code: code, Weight: 10}, nil
+}
+
+func (medication *medicationMockUsecase) ListMedications() ([]repo.Medication, error) {
+	return []repo.Medication{}, nil
+}
+
+func (medication *medicationMockUsecase) UpdateMedication(code string, object usecase.MedicationObject) error {
+	return nil
+}
+
+func (medication *medicationMockUsecase) DeleteMedication(code string) error {
+	if code == "ordered" {
+		return &usecase.MedicationInUseError{Code: code, OrderID: 1}
+	}
+	if code != "code" {
+		return usecase.ErrMedicationNotFound
+	}
+	return nil
 }
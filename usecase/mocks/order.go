@@ -0,0 +1,56 @@
+package mocks
+
+import (
+	repo "drone/v2/repository"
+	"drone/v2/usecase"
+	"errors"
+)
+
+type IOrderMockUsecase interface {
+	PlaceOrder(object usecase.OrderObject) (repo.Order, error)
+	GetOrder(id int) (repo.Order, error)
+	PlanDispatch() (usecase.DispatchPlan, error)
+	Dispatch() (usecase.DispatchPlan, error)
+}
+
+type orderMockUsecase struct {
+}
+
+func NewOrderMockUsecase() IOrderMockUsecase {
+	return &orderMockUsecase{}
+}
+
+func (u orderMockUsecase) PlaceOrder(object usecase.OrderObject) (repo.Order, error) {
+	if len(object.Items) == 0 {
+		return repo.Order{}, errors.New("Order items are not provided")
+	}
+	order := repo.Order{ID: 1, Destination: object.Destination, Status: usecase.OrderPending}
+	if object.Items[0].Code != "code" {
+		return order, usecase.ErrLoadingQueueFull
+	}
+	droneID := 1
+	order.DroneID = &droneID
+	order.Status = usecase.OrderAssigned
+	return order, nil
+}
+
+func (u orderMockUsecase) GetOrder(id int) (repo.Order, error) {
+	if id != 1 {
+		return repo.Order{}, usecase.ErrOrderNotFound
+	}
+	return repo.Order{ID: id, Destination: "hospital", Status: usecase.OrderLoaded}, nil
+}
+
+func (u orderMockUsecase) PlanDispatch() (usecase.DispatchPlan, error) {
+	plan, err := u.Dispatch()
+	plan.DryRun = true
+	return plan, err
+}
+
+func (u orderMockUsecase) Dispatch() (usecase.DispatchPlan, error) {
+	return usecase.DispatchPlan{
+		Assignments: []usecase.DispatchAssignment{{DroneID: 1, Orders: []int{1, 2}, Weight: 80, Remaining: 20}},
+		Unassigned:  []int{3},
+		DronesUsed:  1,
+	}, nil
+}
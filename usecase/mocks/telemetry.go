@@ -0,0 +1,49 @@
+package mocks
+
+import (
+	repo "drone/v2/repository"
+	"drone/v2/usecase"
+	"errors"
+)
+
+type ITelemetryMockUsecase interface {
+	RecordTelemetry(droneID int, readings []usecase.TelemetryObject) (usecase.DroneDetails, error)
+	ListTelemetry(droneID int, query usecase.TelemetryQuery) ([]repo.Telemetry, error)
+	CompactTelemetry()
+}
+
+type telemetryMockUsecase struct {
+}
+
+func NewTelemetryMockUsecase() ITelemetryMockUsecase {
+	return &telemetryMockUsecase{}
+}
+
+func (u telemetryMockUsecase) RecordTelemetry(droneID int, readings []usecase.TelemetryObject) (usecase.DroneDetails, error) {
+	if droneID != 1 {
+		return usecase.DroneDetails{}, usecase.ErrDroneNotFound
+	}
+	if len(readings) == 0 {
+		return usecase.DroneDetails{}, usecase.ErrTelemetryBatchSize
+	}
+	if readings[0].RecordedAt.IsZero() {
+		return usecase.DroneDetails{}, errors.New("Recorded at is not provided")
+	}
+	latest := readings[len(readings)-1]
+	drone := repo.Drone{ID: droneID, State: latest.State, TelemetryAt: &latest.RecordedAt}
+	if latest.BatteryLevel != nil {
+		drone.BatteryCapacity = int(*latest.BatteryLevel)
+	}
+	return usecase.DroneDetails{Drone: drone}, nil
+}
+
+func (u telemetryMockUsecase) ListTelemetry(droneID int, query usecase.TelemetryQuery) ([]repo.Telemetry, error) {
+	if droneID != 1 {
+		return nil, usecase.ErrDroneNotFound
+	}
+	battery := 90.0
+	return []repo.Telemetry{{ID: 1, DroneID: droneID, BatteryLevel: &battery, Samples: 1}}, nil
+}
+
+func (u telemetryMockUsecase) CompactTelemetry() {
+}
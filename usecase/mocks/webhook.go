@@ -0,0 +1,61 @@
+package mocks
+
+import (
+	repo "drone/v2/repository"
+	"drone/v2/usecase"
+	"errors"
+)
+
+type IWebhookMockUsecase interface {
+	CreateWebhook(object usecase.WebhookObject) (usecase.CreatedWebhook, error)
+	GetWebhook(id int) (usecase.WebhookDetails, error)
+	ListWebhooks() ([]usecase.WebhookDetails, error)
+	DeleteWebhook(id int) error
+	ListDeliveries(id int) ([]repo.WebhookDelivery, error)
+	DeliverWebhooks()
+}
+
+type webhookMockUsecase struct {
+}
+
+func NewWebhookMockUsecase() IWebhookMockUsecase {
+	return &webhookMockUsecase{}
+}
+
+func (u webhookMockUsecase) CreateWebhook(object usecase.WebhookObject) (usecase.CreatedWebhook, error) {
+	if object.URL == "" {
+		return usecase.CreatedWebhook{}, errors.New("Webhook url is not provided")
+	}
+	return usecase.CreatedWebhook{
+		WebhookDetails: usecase.WebhookDetails{ID: 1, URL: object.URL, Events: object.Events},
+		Secret:         "0123456789abcdef",
+	}, nil
+}
+
+func (u webhookMockUsecase) GetWebhook(id int) (usecase.WebhookDetails, error) {
+	if id != 1 {
+		return usecase.WebhookDetails{}, usecase.ErrWebhookNotFound
+	}
+	return usecase.WebhookDetails{ID: id, URL: "https://erp.example.com/hooks", Events: []string{}}, nil
+}
+
+func (u webhookMockUsecase) ListWebhooks() ([]usecase.WebhookDetails, error) {
+	return []usecase.WebhookDetails{{ID: 1, URL: "https://erp.example.com/hooks", Events: []string{}}}, nil
+}
+
+func (u webhookMockUsecase) DeleteWebhook(id int) error {
+	if id != 1 {
+		return usecase.ErrWebhookNotFound
+	}
+	return nil
+}
+
+func (u webhookMockUsecase) ListDeliveries(id int) ([]repo.WebhookDelivery, error) {
+	if id != 1 {
+		return nil, usecase.ErrWebhookNotFound
+	}
+	return []repo.WebhookDelivery{{ID: 1, WebhookID: id, EventType: usecase.WebhookDroneLoaded, Status: usecase.DeliverySucceeded}}, nil
+}
+
+func (u webhookMockUsecase) DeliverWebhooks() {
+}
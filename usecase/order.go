@@ -0,0 +1,259 @@
+package usecase
+
+import (
+	repo "drone/v2/repository"
+	"drone/v2/settings"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+
+	"github.com/asaskevich/govalidator"
+)
+
+const (
+	OrderPending  = "PENDING"
+	OrderAssigned = "ASSIGNED"
+	OrderLoaded   = "LOADED"
+	OrderFailed   = "FAILED"
+)
+
+var ErrOrderNotFound = errors.New("order is not exist")
+
+type IOrderUsecase interface {
+	PlaceOrder(object OrderObject) (repo.Order, error)
+	GetOrder(id int) (repo.Order, error)
+	PlanDispatch() (DispatchPlan, error)
+	Dispatch() (DispatchPlan, error)
+}
+
+type orderUsecase struct {
+	orderRepo         repo.IOrderRepository
+	droneRepo         repo.IDroneRepository
+	medicationRepo    repo.IMedicationRepository
+	drones            IDroneUsecase
+	minLoadingBattery int
+	safetyMargin      float64
+	// assigning serializes picking drones so two orders are not fitted in the same free capacity
+	assigning sync.Mutex
+}
+
+func NewOrderUsecase(o repo.IOrderRepository, d repo.IDroneRepository, m repo.IMedicationRepository, drones IDroneUsecase, battery settings.Battery) IOrderUsecase {
+	return &orderUsecase{
+		orderRepo:         o,
+		droneRepo:         d,
+		medicationRepo:    m,
+		drones:            drones,
+		minLoadingBattery: battery.MinLoadingLevel,
+		safetyMargin:      battery.SafetyMargin,
+	}
+}
+
+// PlaceOrder saves the order and assigns it to the available drone that fits it best, the order
+// items are then loaded onto the drone by loading jobs. The order stays PENDING when no drone fits it.
+func (o *orderUsecase) PlaceOrder(object OrderObject) (repo.Order, error) {
+	weight, err := o.validateOrder(object)
+	if err != nil {
+		return repo.Order{}, err
+	}
+	order := repo.Order{
+		Destination:          object.Destination,
+		Priority:             object.Priority,
+		Status:               OrderPending,
+		DestinationLatitude:  object.DestinationLatitude,
+		DestinationLongitude: object.DestinationLongitude,
+	}
+	for _, item := range object.Items {
+		order.Items = append(order.Items, repo.OrderItem{MedicationCode: item.Code, Quantity: orderQuantity(item)})
+	}
+	if _, err := o.orderRepo.Create(&order); err != nil {
+		return repo.Order{}, err
+	}
+	return o.getOrder(order.ID, o.assign(order, weight))
+}
+
+// GetOrder returns the order with its status updated from the loading jobs of its items.
+func (o *orderUsecase) GetOrder(id int) (repo.Order, error) {
+	order, err := o.orderRepo.Get(id)
+	if err != nil {
+		return repo.Order{}, orderError(err)
+	}
+	if order.Status != OrderAssigned {
+		return order, nil
+	}
+	status := OrderLoaded
+	for _, item := range order.Items {
+		job, err := o.drones.GetLoadingJob(item.JobID)
+		if err != nil {
+			// jobs are forgotten after their retention so the order keeps its last status
+			return order, nil
+		}
+		if job.Status == JobFailed {
+			status = OrderFailed
+			break
+		}
+		if job.Status != JobDone {
+			return order, nil
+		}
+	}
+	if err := o.orderRepo.UpdateStatus(id, OrderAssigned, status); err != nil && !errors.Is(err, repo.ErrOrderChanged) {
+		return repo.Order{}, err
+	}
+	return o.getOrder(id, nil)
+}
+
+// validateOrder validates the order and returns the weight of all its items.
+func (o *orderUsecase) validateOrder(object OrderObject) (float32, error) {
+	orderValidate, err := govalidator.ValidateStruct(object)
+	if err != nil || !orderValidate {
+		return 0, err
+	}
+	if (object.DestinationLatitude == nil) != (object.DestinationLongitude == nil) {
+		return 0, errors.New("Destination latitude and longitude must be provided together")
+	}
+	if object.DestinationLatitude != nil {
+		if err := validateLocation(*object.DestinationLatitude, *object.DestinationLongitude); err != nil {
+			return 0, err
+		}
+	}
+	if len(object.Items) == 0 {
+		return 0, errors.New("Order items are not provided")
+	}
+	var weight float32
+	for _, item := range object.Items {
+		itemValidate, err := govalidator.ValidateStruct(item)
+		if err != nil || !itemValidate {
+			return 0, err
+		}
+		medication, err := o.medicationRepo.Get(item.Code)
+		if err != nil {
+			return 0, medicationError(err)
+		}
+		weight += medication.Weight * float32(orderQuantity(item))
+	}
+	return weight, nil
+}
+
+func orderQuantity(item OrderItemObject) int {
+	if item.Quantity == 0 {
+		return 1
+	}
+	return item.Quantity
+}
+
+// assign loads the order onto the first candidate drone that accepts all its items and can deliver it, a drone
+// that rejects the first item is skipped. An order partly loaded when a later item is rejected is FAILED and
+// its loaded items are taken back off the drone.
+func (o *orderUsecase) assign(order repo.Order, weight float32) error {
+	o.assigning.Lock()
+	defer o.assigning.Unlock()
+	for _, drone := range orderCandidates(o.droneRepo.AvailableDroneForLoading(), weight, o.minLoadingBattery) {
+		if !canDeliver(drone, order, weight, o.safetyMargin) {
+			continue
+		}
+		jobs, err := o.load(drone.ID, order.Items)
+		if err == nil {
+			if err := o.orderRepo.Assign(order.ID, OrderAssigned, drone.ID, jobs); err != nil {
+				return err
+			}
+			return o.routeDrone(drone.ID, order)
+		}
+		if len(jobs) == 0 {
+			log.Println(fmt.Sprintf("order %d can not be loaded on drone %d: %s", order.ID, drone.ID, err.Error()))
+			continue
+		}
+		// the items loaded before the rejected one are taken back off the drone
+		jobIDs := make([]int, 0, len(jobs))
+		for _, jobID := range jobs {
+			jobIDs = append(jobIDs, jobID)
+		}
+		if cancelErr := o.drones.CancelLoadingJobs(drone.ID, jobIDs); cancelErr != nil {
+			log.Println(cancelErr.Error())
+		}
+		if assignErr := o.orderRepo.Assign(order.ID, OrderFailed, drone.ID, jobs); assignErr != nil {
+			return assignErr
+		}
+		return err
+	}
+	return nil
+}
+
+// load starts a loading job for every item and returns the job ids by item id.
+func (o *orderUsecase) load(droneID int, items []repo.OrderItem) (map[int]int, error) {
+	jobs := map[int]int{}
+	for _, item := range items {
+		job, err := o.drones.LoadingMedication(droneID, LoadObject{Code: item.MedicationCode, Quantity: item.Quantity})
+		if err != nil {
+			return jobs, err
+		}
+		jobs[item.ID] = job.ID
+	}
+	return jobs, nil
+}
+
+// routeDrone sends the drone to the destination of the order, the orders carried by
+// one drone all go to its destination.
+func (o *orderUsecase) routeDrone(droneID int, order repo.Order) error {
+	if order.DestinationLatitude == nil || order.DestinationLongitude == nil {
+		return nil
+	}
+	drone, err := o.droneRepo.Get(droneID)
+	if err != nil {
+		return droneError(err)
+	}
+	if !sameDestination(drone, order) {
+		return errors.New(fmt.Sprintf("drone %d delivers to another destination than order %d", droneID, order.ID))
+	}
+	if drone.DestinationLatitude != nil {
+		return nil
+	}
+	return o.droneRepo.SetDestination(droneID, order.DestinationLatitude, order.DestinationLongitude)
+}
+
+func (o *orderUsecase) getOrder(id int, err error) (repo.Order, error) {
+	order, getErr := o.orderRepo.Get(id)
+	if getErr != nil {
+		return repo.Order{}, orderError(getErr)
+	}
+	return order, err
+}
+
+// orderCandidates are the drones that can carry weight with enough battery, the drone with
+// the least capacity left after loading comes first so bigger drones stay free for bigger orders.
+func orderCandidates(drones []repo.Drone, weight float32, minBattery int) []repo.Drone {
+	candidates := []repo.Drone{}
+	for _, drone := range drones {
+		if drone.BatteryCapacity >= minBattery && drone.Weight-drone.CurrentPayload >= weight {
+			candidates = append(candidates, drone)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if left := compareCapacity(a.Weight-a.CurrentPayload, b.Weight-b.CurrentPayload); left != 0 {
+			return left < 0
+		}
+		if a.BatteryCapacity != b.BatteryCapacity {
+			return a.BatteryCapacity > b.BatteryCapacity
+		}
+		return a.ID < b.ID
+	})
+	return candidates
+}
+
+func compareCapacity(a float32, b float32) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func orderError(err error) error {
+	if errors.Is(err, repo.ErrRecordNotFound) {
+		return ErrOrderNotFound
+	}
+	return err
+}
@@ -0,0 +1,205 @@
+package usecase
+
+import (
+	"drone/v2/clock"
+	repo "drone/v2/repository"
+	"drone/v2/settings"
+	"reflect"
+	"testing"
+)
+
+func newMemoryOrderUsecase(t *testing.T, drones ...repo.Drone) (*orderUsecase, repo.IDroneRepository, *clock.Fake) {
+	t.Helper()
+	clock := clock.NewFake(testTime)
+	logRepo := repo.NewMemoryLogRepository(clock)
+	droneRepo := repo.NewMemoryDroneRepo(logRepo)
+	createDrones(t, droneRepo, drones...)
+	medicationRepo := repo.NewMemoryMedicationRepo()
+	createMedications(t, medicationRepo,
+		repo.Medication{Name: "medication", Code: "code", Weight: 10},
+		repo.Medication{Name: "other medication", Code: "other", Weight: 50},
+	)
+	battery := settings.Battery{MinLoadingLevel: 25}
+	droneUsecase := NewDroneUsecase(droneRepo, medicationRepo, battery, clock, nil, NewEventBus(clock))
+	o := NewOrderUsecase(repo.NewMemoryOrderRepo(clock), droneRepo, medicationRepo, droneUsecase, battery)
+	return o.(*orderUsecase), droneRepo, clock
+}
+
+func Test_orderCandidates(t *testing.T) {
+	drones := []repo.Drone{
+		{ID: 1, Weight: 500, BatteryCapacity: 100},
+		{ID: 2, Weight: 200, BatteryCapacity: 60},
+		{ID: 3, Weight: 200, BatteryCapacity: 90},
+		{ID: 4, Weight: 100, BatteryCapacity: 100},
+		{ID: 5, Weight: 300, BatteryCapacity: 20},
+		{ID: 6, Weight: 500, CurrentPayload: 350, BatteryCapacity: 100},
+	}
+	got := []int{}
+	for _, drone := range orderCandidates(drones, 150, 25) {
+		got = append(got, drone.ID)
+	}
+	// 6 has 150 left, 3 and 2 have 200 left and 3 has more battery, 4 is too small and 5 too low
+	if want := []int{6, 3, 2, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("orderCandidates() = %v, want %v", got, want)
+	}
+}
+
+func Test_orderUsecase_PlaceOrder(t *testing.T) {
+	tests := []struct {
+		name        string
+		object      OrderObject
+		wantErr     bool
+		wantStatus  string
+		wantDroneID int
+	}{
+		{
+			name:        "test order assigned to the smallest drone that fits",
+			object:      OrderObject{Destination: "hospital", Items: []OrderItemObject{{Code: "code", Quantity: 2}, {Code: "other"}}},
+			wantStatus:  OrderAssigned,
+			wantDroneID: 2,
+		},
+		{
+			name:        "test order bigger than the small drone",
+			object:      OrderObject{Destination: "hospital", Items: []OrderItemObject{{Code: "other", Quantity: 3}}},
+			wantStatus:  OrderAssigned,
+			wantDroneID: 1,
+		},
+		{
+			name:       "test order no drone fits stays pending",
+			object:     OrderObject{Destination: "hospital", Items: []OrderItemObject{{Code: "other", Quantity: 20}}},
+			wantStatus: OrderPending,
+		},
+		{
+			name:    "test order without items",
+			object:  OrderObject{Destination: "hospital"},
+			wantErr: true,
+		},
+		{
+			name:    "test order with unknown medication",
+			object:  OrderObject{Destination: "hospital", Items: []OrderItemObject{{Code: "missing"}}},
+			wantErr: true,
+		},
+		{
+			name:    "test order without destination",
+			object:  OrderObject{Items: []OrderItemObject{{Code: "code"}}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o, _, _ := newMemoryOrderUsecase(t,
+				repo.Drone{SerialNumber: "big", Weight: 500, State: StateIdle, BatteryCapacity: 100},
+				repo.Drone{SerialNumber: "small", Weight: 100, State: StateIdle, BatteryCapacity: 100},
+				repo.Drone{SerialNumber: "empty", Weight: 500, State: StateIdle, BatteryCapacity: 10},
+			)
+			order, err := o.PlaceOrder(tt.object)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("orderUsecase.PlaceOrder() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if order.Status != tt.wantStatus {
+				t.Errorf("orderUsecase.PlaceOrder() status = %v, want %v", order.Status, tt.wantStatus)
+			}
+			if tt.wantDroneID == 0 && order.DroneID != nil || tt.wantDroneID != 0 && (order.DroneID == nil || *order.DroneID != tt.wantDroneID) {
+				t.Errorf("orderUsecase.PlaceOrder() drone = %v, want %v", order.DroneID, tt.wantDroneID)
+			}
+			for _, item := range order.Items {
+				if (item.JobID != 0) != (tt.wantStatus == OrderAssigned) {
+					t.Errorf("orderUsecase.PlaceOrder() item %s job = %v", item.MedicationCode, item.JobID)
+				}
+			}
+		})
+	}
+}
+
+func Test_orderUsecase_PlaceOrder_Destination(t *testing.T) {
+	o, droneRepo, _ := newMemoryOrderUsecase(t, repo.Drone{
+		SerialNumber:    "drone",
+		Weight:          500,
+		State:           StateIdle,
+		BatteryCapacity: 100,
+		Latitude:        30.04,
+		Longitude:       31.23,
+		HomeLatitude:    30.04,
+		HomeLongitude:   31.23,
+	})
+	if _, err := o.PlaceOrder(OrderObject{Destination: "hospital", DestinationLatitude: floatPointer(30.1), Items: []OrderItemObject{{Code: "code"}}}); err == nil {
+		t.Errorf("orderUsecase.PlaceOrder() without destination longitude error = nil, want error")
+	}
+	for _, object := range []OrderObject{
+		{Destination: "hospital", DestinationLatitude: floatPointer(30.1), DestinationLongitude: floatPointer(31.3), Items: []OrderItemObject{{Code: "code"}}},
+		{Destination: "clinic", DestinationLatitude: floatPointer(30.2), DestinationLongitude: floatPointer(31.4), Items: []OrderItemObject{{Code: "code"}}},
+	} {
+		order, err := o.PlaceOrder(object)
+		if err != nil || order.DestinationLatitude == nil || *order.DestinationLatitude != *object.DestinationLatitude {
+			t.Fatalf("orderUsecase.PlaceOrder() = %+v, %v, want the order destination saved", order, err)
+		}
+	}
+	// the drone keeps the destination of the first order it was assigned
+	drone, _ := droneRepo.Get(1)
+	if drone.DestinationLatitude == nil || *drone.DestinationLatitude != 30.1 || *drone.DestinationLongitude != 31.3 {
+		t.Errorf("drone destination = %v,%v, want 30.1,31.3", drone.DestinationLatitude, drone.DestinationLongitude)
+	}
+
+	o, _, _ = newMemoryOrderUsecase(t, repo.Drone{SerialNumber: "drone", Weight: 500, State: StateIdle, BatteryCapacity: 100})
+	order, err := o.PlaceOrder(OrderObject{Destination: "far away", DestinationLatitude: floatPointer(30.1), DestinationLongitude: floatPointer(31.3), Items: []OrderItemObject{{Code: "code"}}})
+	if err != nil || order.Status != OrderPending {
+		t.Errorf("orderUsecase.PlaceOrder() out of range = %+v, %v, want a pending order", order, err)
+	}
+}
+
+func Test_orderUsecase_assign_PartlyLoaded(t *testing.T) {
+	d, droneRepo, _ := newMemoryDroneUsecase(t, repo.Drone{SerialNumber: "drone", Weight: 500, State: StateIdle, BatteryCapacity: 100})
+	orderRepo := repo.NewMemoryOrderRepo(d.jobs.clock)
+	o := NewOrderUsecase(orderRepo, droneRepo, d.medicationRepo, d, settings.Battery{MinLoadingLevel: 25}).(*orderUsecase)
+	// the second medication left the catalog after the order was placed
+	order := repo.Order{Destination: "hospital", Status: OrderPending, Items: []repo.OrderItem{
+		{MedicationCode: "code", Quantity: 2},
+		{MedicationCode: "missing", Quantity: 1},
+	}}
+	if _, err := orderRepo.Create(&order); err != nil {
+		t.Fatalf("Can't create order: %v", err)
+	}
+	if err := o.assign(order, 30); err != ErrMedicationNotFound {
+		t.Fatalf("orderUsecase.assign() error = %v, want %v", err, ErrMedicationNotFound)
+	}
+	failed, _ := o.GetOrder(order.ID)
+	if failed.Status != OrderFailed || failed.Items[0].JobID == 0 {
+		t.Fatalf("orderUsecase.GetOrder() = %+v, want a FAILED order with the job of its first item", failed)
+	}
+	d.jobs.run(failed.Items[0].JobID, d.loadMedication, d.finishLoading)
+	if drone, _ := droneRepo.Get(1); drone.State != StateIdle || drone.CurrentPayload != 0 || len(drone.Medications) != 0 {
+		t.Errorf("drone = %+v, want the items of the failed order taken back off", drone)
+	}
+}
+
+func Test_orderUsecase_GetOrder(t *testing.T) {
+	o, droneRepo, clock := newMemoryOrderUsecase(t, repo.Drone{SerialNumber: "drone", Weight: 100, State: StateIdle, BatteryCapacity: 100})
+	changes, _ := o.drones.(*droneUsecase).events.Subscribe(EventFilter{Types: []string{EventStateChanged}}, 0)
+	placed, err := o.PlaceOrder(OrderObject{Destination: "hospital", Items: []OrderItemObject{{Code: "code"}, {Code: "code", Quantity: 2}}})
+	if err != nil || placed.Status != OrderAssigned {
+		t.Fatalf("orderUsecase.PlaceOrder() = %+v, %v, want an assigned order", placed, err)
+	}
+
+	// both loading jobs sleep at once, the drone is LOADED once they are done
+	clock.BlockUntil(2)
+	clock.Advance(loadingTimePerGram * 30)
+	if events := nextEvents(t, changes, 2); events[1].Data.(StateChangedEvent).To != StateLoaded {
+		t.Fatalf("state changes = %+v, want the drone LOADED", events)
+	}
+	order, err := o.GetOrder(placed.ID)
+	if err != nil {
+		t.Fatalf("orderUsecase.GetOrder() error = %v", err)
+	}
+	if order.Status != OrderLoaded {
+		t.Fatalf("orderUsecase.GetOrder() status = %v, want %v", order.Status, OrderLoaded)
+	}
+	if drone, _ := droneRepo.Get(1); drone.State != StateLoaded || drone.CurrentPayload != 30 {
+		t.Errorf("drone = %v with payload %v, want LOADED with 30", drone.State, drone.CurrentPayload)
+	}
+	if _, err := o.GetOrder(100); err != ErrOrderNotFound {
+		t.Errorf("orderUsecase.GetOrder() of a missing order error = %v, want %v", err, ErrOrderNotFound)
+	}
+}
@@ -0,0 +1,150 @@
+package usecase
+
+import (
+	"drone/v2/clock"
+	repo "drone/v2/repository"
+	"log"
+	"math"
+	"sync"
+	"time"
+)
+
+// flightStates are the states a drone is out of the base in, the flight simulator moves them.
+var flightStates = map[string]bool{
+	StateDelivering: true,
+	StateDelivered:  true,
+	StateReturning:  true,
+}
+
+type IFlightSimulator interface {
+	Advance()
+}
+
+type flightSimulator struct {
+	droneRepo repo.IDroneRepository
+	drones    IDroneUsecase
+	clock     clock.Clock
+	// mu serializes advances so every flight minute is simulated once
+	mu        sync.Mutex
+	last      time.Time
+	batteries *batteryMeter
+}
+
+func NewFlightSimulator(d repo.IDroneRepository, drones IDroneUsecase, clock clock.Clock) IFlightSimulator {
+	return &flightSimulator{
+		droneRepo: d,
+		drones:    drones,
+		clock:     clock,
+		last:      clock.Now(),
+		batteries: newBatteryMeter(),
+	}
+}
+
+// Advance flies the drones out of the base for the time passed since the last advance. A delivering drone
+// flies to its destination where it is marked DELIVERED and unloaded, then it flies back to its home base
+// and is IDLE again. Flying drains the battery by the state and payload of the drone, the drained levels
+// are published and ground the drones like the battery check does.
+func (s *flightSimulator) Advance() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.clock.Now()
+	elapsed := now.Sub(s.last)
+	s.last = now
+	if elapsed <= 0 {
+		return
+	}
+	drones, _, err := s.droneRepo.List(repo.DroneFilter{})
+	if err != nil {
+		log.Println(err.Error())
+		return
+	}
+	s.batteries.mu.Lock()
+	defer s.batteries.mu.Unlock()
+	levels := map[int]int{}
+	exact := map[int]float64{}
+	drains := map[int]float64{}
+	for _, drone := range drones {
+		if !flightStates[drone.State] {
+			continue
+		}
+		previous := s.batteries.level(drone)
+		level, err := s.fly(drone, elapsed.Minutes())
+		if err != nil {
+			log.Println(err.Error())
+		}
+		level = math.Max(0, level)
+		exact[drone.ID] = level
+		drains[drone.ID] = drainRate(previous, level, elapsed)
+		if rounded := int(math.Round(level)); rounded != drone.BatteryCapacity {
+			levels[drone.ID] = rounded
+		}
+	}
+	if err := s.droneRepo.UpdateBatteries(levels, ""); err != nil {
+		log.Println(err.Error())
+		return
+	}
+	s.batteries.levels = exact
+	for _, drone := range drones {
+		if _, flown := exact[drone.ID]; !flown {
+			continue
+		}
+		level, changed := levels[drone.ID]
+		if !changed {
+			level = drone.BatteryCapacity
+		}
+		s.drones.BatteryChanged(drone, level, drains[drone.ID])
+	}
+}
+
+// fly moves the drone through its flight for the given minutes and returns its battery level after them.
+func (s *flightSimulator) fly(drone repo.Drone, minutes float64) (float64, error) {
+	level := s.batteries.level(drone)
+	for {
+		var err error
+		switch drone.State {
+		case StateDelivering:
+			latitude, longitude := drone.Latitude, drone.Longitude
+			if drone.DestinationLatitude != nil && drone.DestinationLongitude != nil {
+				latitude, longitude = *drone.DestinationLatitude, *drone.DestinationLongitude
+			}
+			flown, arrived, moveErr := s.move(&drone, latitude, longitude, minutes)
+			level, minutes = level+batteryChange(drone)*flown, minutes-flown
+			if moveErr != nil || !arrived {
+				return level, moveErr
+			}
+			err = s.drones.MarkDelivered(drone.ID)
+		case StateDelivered:
+			err = s.drones.ReturnToBase(drone.ID)
+		case StateReturning:
+			flown, arrived, moveErr := s.move(&drone, drone.HomeLatitude, drone.HomeLongitude, minutes)
+			level, minutes = level+batteryChange(drone)*flown, minutes-flown
+			if moveErr != nil || !arrived {
+				return level, moveErr
+			}
+			err = s.drones.ChangeDroneState(drone.ID, StateIdle)
+		default:
+			return level, nil
+		}
+		if err != nil {
+			return level, err
+		}
+		if drone, err = s.droneRepo.Get(drone.ID); err != nil {
+			return level, err
+		}
+	}
+}
+
+// move flies the drone toward the point for at most the given minutes, it returns the
+// minutes it flew and whether it got there.
+func (s *flightSimulator) move(drone *repo.Drone, latitude float64, longitude float64, minutes float64) (float64, bool, error) {
+	km := distance(drone.Latitude, drone.Longitude, latitude, longitude)
+	flown, arrived := km/flightSpeed, true
+	if flown > minutes {
+		part := minutes * flightSpeed / km
+		latitude = drone.Latitude + (latitude-drone.Latitude)*part
+		longitude = drone.Longitude + (longitude-drone.Longitude)*part
+		flown, arrived = minutes, false
+	}
+	drone.Latitude, drone.Longitude = latitude, longitude
+	return flown, arrived, s.droneRepo.UpdatePosition(drone.ID, latitude, longitude)
+}
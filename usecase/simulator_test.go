@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	"drone/v2/clock"
+	repo "drone/v2/repository"
+	"math"
+	"testing"
+	"time"
+)
+
+func Test_flightSimulator_Advance(t *testing.T) {
+	d, droneRepo, logRepo := newMemoryDroneUsecase(t, repo.Drone{
+		SerialNumber:         "serial 1",
+		Model:                "Lightweight",
+		Weight:               500,
+		State:                StateLoaded,
+		BatteryCapacity:      100,
+		DestinationLatitude:  floatPointer(0),
+		DestinationLongitude: floatPointer(0.1),
+	})
+	if err := droneRepo.AddMedication(1, 1, &repo.LoadItem{MedicationCode: "code", Quantity: 1, Weight: 100}); err != nil {
+		t.Fatalf("Can't load drone: %v", err)
+	}
+	clock := d.jobs.clock.(*clock.Fake)
+	s := NewFlightSimulator(droneRepo, d, clock)
+	if err := d.StartDelivery(1); err != nil {
+		t.Fatalf("droneUsecase.StartDelivery() error = %v", err)
+	}
+
+	// the destination is 11.12km away, carrying 100 grams takes 2.5% a minute and flying back 1.5% a minute
+	steps := []struct {
+		name          string
+		elapsed       time.Duration
+		wantState     string
+		wantLongitude float64
+		wantBattery   int
+		wantPayload   float32
+	}{
+		{name: "no time passed", wantState: StateDelivering, wantBattery: 100, wantPayload: 100},
+		{name: "on the way", elapsed: 5 * time.Minute, wantState: StateDelivering, wantLongitude: 0.045, wantBattery: 88, wantPayload: 100},
+		{name: "delivered and on the way back", elapsed: 10 * time.Minute, wantState: StateReturning, wantLongitude: 0.0651, wantBattery: 66},
+		{name: "back at the base", elapsed: 10 * time.Minute, wantState: StateIdle, wantBattery: 56},
+		{name: "idle drones stay", elapsed: 10 * time.Minute, wantState: StateIdle, wantBattery: 56},
+	}
+	for _, step := range steps {
+		clock.Advance(step.elapsed)
+		s.Advance()
+		drone, _ := droneRepo.Get(1)
+		if drone.State != step.wantState || drone.BatteryCapacity != step.wantBattery || drone.CurrentPayload != step.wantPayload {
+			t.Errorf("%s: drone = %s with %d%% battery and %v payload, want %s with %d%% and %v", step.name,
+				drone.State, drone.BatteryCapacity, drone.CurrentPayload, step.wantState, step.wantBattery, step.wantPayload)
+		}
+		if math.Abs(drone.Longitude-step.wantLongitude) > 0.001 || drone.Latitude != 0 {
+			t.Errorf("%s: drone position = %v,%v, want 0,%v", step.name, drone.Latitude, drone.Longitude, step.wantLongitude)
+		}
+	}
+
+	drone, _ := droneRepo.Get(1)
+	if len(drone.Medications) != 0 || drone.DestinationLatitude != nil {
+		t.Errorf("drone back at the base = %+v, want it unloaded without a destination", drone)
+	}
+	logs, _ := logRepo.List()
+	var unloaded bool
+	for _, log := range logs {
+		unloaded = unloaded || log.Description == "all medications unloaded" && log.DroneState == StateDelivered
+	}
+	if !unloaded {
+		t.Errorf("logs = %v, want the medications unloaded at the destination", logs)
+	}
+}
+
+func Test_flightSimulator_Advance_Grounding(t *testing.T) {
+	d, droneRepo, _ := newMemoryDroneUsecase(t, repo.Drone{
+		SerialNumber:         "serial 1",
+		Model:                "Lightweight",
+		Weight:               500,
+		State:                StateDelivering,
+		BatteryCapacity:      12,
+		DestinationLatitude:  floatPointer(0),
+		DestinationLongitude: floatPointer(1),
+	})
+	d.criticalBattery = 10
+	d.events = NewEventBus(d.jobs.clock)
+	subscription, _ := d.events.Subscribe(EventFilter{Types: []string{EventBatteryTick, EventDroneGrounded}}, 0)
+	clock := d.jobs.clock.(*clock.Fake)
+	s := NewFlightSimulator(droneRepo, d, clock)
+
+	// flying without payload drains 1.5% a minute
+	clock.Advance(2 * time.Minute)
+	s.Advance()
+	events := nextEvents(t, subscription, 2)
+	if events[0].Type != EventBatteryTick || events[0].Data != (BatteryEvent{Level: 9, State: StateDelivering, Drain: 1.5}) {
+		t.Errorf("battery event = %+v, want a tick to 9%% while delivering", events[0])
+	}
+	if events[1].Type != EventDroneGrounded || events[1].Data != (BatteryEvent{Level: 9, State: StateDelivering}) {
+		t.Errorf("grounded event = %+v, want the delivering drone grounded at 9%%", events[1])
+	}
+	if drone, _ := droneRepo.Get(1); !drone.Grounded || drone.State != StateDelivering {
+		t.Errorf("drone = %s grounded %v, want it grounded on its way", drone.State, drone.Grounded)
+	}
+}
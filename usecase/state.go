@@ -0,0 +1,157 @@
+package usecase
+
+import (
+	repo "drone/v2/repository"
+	"errors"
+	"fmt"
+	"log"
+)
+
+const (
+	StateIdle       = "IDLE"
+	StateLoading    = "LOADING"
+	StateLoaded     = "LOADED"
+	StateDelivering = "DELIVERING"
+	StateDelivered  = "DELIVERED"
+	StateReturning  = "RETURNING"
+)
+
+// droneTransitions lists for every lifecycle state the states a drone may move to next.
+var droneTransitions = map[string][]string{
+	StateIdle:       {StateLoading},
+	StateLoading:    {StateLoaded, StateIdle},
+	StateLoaded:     {StateLoading, StateDelivering, StateIdle},
+	StateDelivering: {StateDelivered},
+	StateDelivered:  {StateReturning},
+	StateReturning:  {StateIdle},
+}
+
+// ErrConcurrentUpdate is returned when the drone was changed by another request
+// between reading and persisting it.
+var ErrConcurrentUpdate = repo.ErrConcurrentUpdate
+
+// ErrDroneNotEmpty is returned when a drone carrying medications is moved back to IDLE, it is unloaded instead.
+var ErrDroneNotEmpty = errors.New("drone carries medications, unload it to move it to IDLE")
+
+type UnknownStateError struct {
+	State string
+}
+
+func (e *UnknownStateError) Error() string {
+	return fmt.Sprintf("drone state %q is not exist", e.State)
+}
+
+type IllegalTransitionError struct {
+	DroneID int
+	From    string
+	To      string
+}
+
+func (e *IllegalTransitionError) Error() string {
+	return fmt.Sprintf("drone %d can not move from %s to %s", e.DroneID, e.From, e.To)
+}
+
+func isDroneState(state string) bool {
+	_, found := droneTransitions[state]
+	return found
+}
+
+func canTransition(from string, to string) bool {
+	for _, next := range droneTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// transition validates the move of the drone into the given state and persists it,
+// the repository only applies it if the drone is still in the state it was read with.
+// A grounded drone is not loaded or sent off, a drone only leaves LOADING after its loading jobs finished,
+// only goes back to IDLE once it is unloaded and only leaves LOADED for its destination with the battery
+// for the round trip, and its destination is cleared once it is IDLE again. The drone is sent the command
+// of its new state and the change is published.
+func (d *droneUsecase) transition(drone repo.Drone, to string) error {
+	if !isDroneState(to) {
+		return &UnknownStateError{State: to}
+	}
+	if !canTransition(drone.State, to) {
+		return &IllegalTransitionError{DroneID: drone.ID, From: drone.State, To: to}
+	}
+	if drone.Grounded && (to == StateLoading || to == StateDelivering) {
+		return ErrDroneGrounded
+	}
+	if drone.State == StateLoading && d.jobs.pendingFor(drone.ID) > 0 {
+		return ErrLoadingInProgress
+	}
+	if to == StateIdle && (drone.State == StateLoading || drone.State == StateLoaded) && len(drone.Medications) > 0 {
+		return ErrDroneNotEmpty
+	}
+	if drone.State == StateLoaded && to == StateDelivering {
+		if err := d.checkRange(drone); err != nil {
+			return err
+		}
+	}
+	if err := d.droneRepo.ChangeDroneState(drone.ID, drone.State, to); err != nil {
+		return err
+	}
+	if to == StateIdle && drone.DestinationLatitude != nil {
+		// the drone is IDLE already so a destination left behind is only logged
+		if err := d.droneRepo.SetDestination(drone.ID, nil, nil); err != nil {
+			log.Println(err.Error())
+		}
+	}
+	d.sendCommand(drone, to)
+	d.publishEvent(EventStateChanged, drone.ID, StateChangedEvent{From: drone.State, To: to})
+	return nil
+}
+
+// moveDrone changes the state of the drone on request, it waits for the load requests of the drone in progress.
+func (d *droneUsecase) moveDrone(id int, to string) error {
+	unlock := d.jobs.lockDrone(id)
+	defer unlock()
+	drone, err := d.droneRepo.Get(id)
+	if err != nil {
+		return droneError(err)
+	}
+	return d.transition(drone, to)
+}
+
+func (d *droneUsecase) ChangeDroneState(id int, state string) error {
+	return d.moveDrone(id, state)
+}
+
+// StartDelivery sends the drone off, a docked drone leaves its slot free for the next drone.
+func (d *droneUsecase) StartDelivery(id int) error {
+	if err := d.moveDrone(id, StateDelivering); err != nil {
+		return err
+	}
+	if err := d.droneRepo.Undock(id); err != nil && !errors.Is(err, repo.ErrNotDocked) {
+		return err
+	}
+	return nil
+}
+
+func (d *droneUsecase) MarkDelivered(id int) error {
+	return d.moveDrone(id, StateDelivered)
+}
+
+// ReturnToBase flies the drone back home, the medications were handed over at the destination so it is unloaded.
+func (d *droneUsecase) ReturnToBase(id int) error {
+	unlock := d.jobs.lockDrone(id)
+	defer unlock()
+	drone, err := d.droneRepo.Get(id)
+	if err != nil {
+		return droneError(err)
+	}
+	// the medications are handed over at the destination
+	if drone.State == StateDelivered && len(drone.Medications) > 0 {
+		if err := d.droneRepo.Unload(id, drone.Version); err != nil {
+			return err
+		}
+		if drone, err = d.droneRepo.Get(id); err != nil {
+			return droneError(err)
+		}
+	}
+	return d.transition(drone, StateReturning)
+}
@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"drone/v2/clock"
+	repo "drone/v2/repository"
+	mosks "drone/v2/repository/mocks"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func Test_canTransition(t *testing.T) {
+	tests := []struct {
+		name string
+		from string
+		to   string
+		want bool
+	}{
+		{name: "test idle drone can start loading", from: StateIdle, to: StateLoading, want: true},
+		{name: "test loading drone can be loaded", from: StateLoading, to: StateLoaded, want: true},
+		{name: "test loaded drone can start delivery", from: StateLoaded, to: StateDelivering, want: true},
+		{name: "test delivering drone can deliver", from: StateDelivering, to: StateDelivered, want: true},
+		{name: "test delivered drone can return", from: StateDelivered, to: StateReturning, want: true},
+		{name: "test returning drone can be idle", from: StateReturning, to: StateIdle, want: true},
+		{name: "test idle drone can not start delivery", from: StateIdle, to: StateDelivering, want: false},
+		{name: "test loaded drone can take more medications", from: StateLoaded, to: StateLoading, want: true},
+		{name: "test delivering drone can not go back to loading", from: StateDelivering, to: StateLoading, want: false},
+		{name: "test drone can not stay in the same state", from: StateIdle, to: StateIdle, want: false},
+		{name: "test unknown state can not move", from: "Loading", to: StateLoaded, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := canTransition(tt.from, tt.to); got != tt.want {
+				t.Errorf("canTransition(%s, %s) = %v, want %v", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_droneUsecase_ChangeDroneState(t *testing.T) {
+	tests := []struct {
+		name        string
+		droneRepo   repo.IDroneRepository
+		id          int
+		state       string
+		wantIllegal bool
+		wantUnknown bool
+		wantErr     bool
+	}{
+		{
+			name:      "test idle drone move to loading",
+			droneRepo: mosks.NewDroneRepoMock(),
+			id:        1,
+			state:     StateLoading,
+		},
+		{
+			name:        "test idle drone can not move to delivered",
+			droneRepo:   mosks.NewDroneRepoMock(),
+			id:          1,
+			state:       StateDelivered,
+			wantIllegal: true,
+		},
+		{
+			name:        "test can not move drone to unknown state",
+			droneRepo:   mosks.NewDroneRepoMock(),
+			id:          1,
+			state:       "Loading",
+			wantUnknown: true,
+		},
+		{
+			name:      "test can not move drone that not exist",
+			droneRepo: mosks.NewDroneRepoFailMock(),
+			id:        1,
+			state:     StateLoading,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &droneUsecase{droneRepo: tt.droneRepo, jobs: newLoadingQueue(loadingQueueSize, clock.System)}
+			err := d.ChangeDroneState(tt.id, tt.state)
+			var illegal *IllegalTransitionError
+			var unknown *UnknownStateError
+			if got := errors.As(err, &illegal); got != tt.wantIllegal {
+				t.Errorf("droneUsecase.ChangeDroneState() error = %v, want illegal transition %v", err, tt.wantIllegal)
+			}
+			if got := errors.As(err, &unknown); got != tt.wantUnknown {
+				t.Errorf("droneUsecase.ChangeDroneState() error = %v, want unknown state %v", err, tt.wantUnknown)
+			}
+			if wantErr := tt.wantErr || tt.wantIllegal || tt.wantUnknown; (err != nil) != wantErr {
+				t.Errorf("droneUsecase.ChangeDroneState() error = %v, wantErr %v", err, wantErr)
+			}
+		})
+	}
+}
+
+func Test_droneUsecase_DeliveryCycle(t *testing.T) {
+	d, droneRepo, logRepo := newMemoryDroneUsecase(t, repo.Drone{
+		SerialNumber: "serial 1",
+		Weight:       500,
+		State:        StateLoaded,
+		Medications:  []repo.LoadItem{{MedicationCode: "code", Quantity: 1, Weight: 10}},
+	})
+	steps := []func(id int) error{d.StartDelivery, d.MarkDelivered, d.ReturnToBase}
+	for _, step := range steps {
+		if err := step(1); err != nil {
+			t.Fatalf("delivery step error = %v", err)
+		}
+	}
+	if err := d.ChangeDroneState(1, StateIdle); err != nil {
+		t.Fatalf("droneUsecase.ChangeDroneState() error = %v", err)
+	}
+	var illegal *IllegalTransitionError
+	if err := d.MarkDelivered(1); !errors.As(err, &illegal) {
+		t.Errorf("droneUsecase.MarkDelivered() error = %v, want IllegalTransitionError", err)
+	}
+	drone, _ := droneRepo.Get(1)
+	if drone.State != StateIdle || drone.Version != 6 || len(drone.Medications) != 0 {
+		t.Errorf("drone = %v version %v, want %v version 6 and unloaded", drone.State, drone.Version, StateIdle)
+	}
+	logs, _ := logRepo.List()
+	states := []string{}
+	for _, log := range logs {
+		states = append(states, log.DroneState)
+	}
+	want := []string{StateDelivering, StateDelivered, StateDelivered, StateReturning, StateIdle}
+	if !reflect.DeepEqual(states, want) {
+		t.Errorf("logged states = %v, want %v", states, want)
+	}
+}
+
+func Test_droneUsecase_ChangeDroneState_NotEmpty(t *testing.T) {
+	d, droneRepo, _ := newMemoryDroneUsecase(t, repo.Drone{
+		SerialNumber:    "serial 1",
+		Weight:          500,
+		State:           StateLoaded,
+		BatteryCapacity: 100,
+		CurrentPayload:  10,
+		Medications:     []repo.LoadItem{{MedicationCode: "code", Quantity: 1, Weight: 10}},
+	})
+	if err := d.ChangeDroneState(1, StateIdle); err != ErrDroneNotEmpty {
+		t.Errorf("droneUsecase.ChangeDroneState() error = %v, wantErr %v", err, ErrDroneNotEmpty)
+	}
+	if drones := droneRepo.AvailableDroneForLoading(); len(drones) != 0 {
+		t.Errorf("available drones = %+v, want the loaded drone kept out", drones)
+	}
+	if _, err := d.UnloadDrone(1); err != nil {
+		t.Fatalf("droneUsecase.UnloadDrone() error = %v", err)
+	}
+	if drone, _ := droneRepo.Get(1); drone.State != StateIdle || drone.CurrentPayload != 0 {
+		t.Errorf("drone = %+v, want it idle and empty once unloaded", drone)
+	}
+}
@@ -0,0 +1,199 @@
+package usecase
+
+import (
+	"drone/v2/clock"
+	repo "drone/v2/repository"
+	"drone/v2/settings"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/asaskevich/govalidator"
+)
+
+const (
+	maxTelemetryBatch     = 1000
+	defaultTelemetryLimit = 100
+	// telemetryClockSkew is how far in the future a reading can be recorded as the drone clocks drift
+	telemetryClockSkew = time.Minute
+)
+
+var ErrTelemetryBatchSize = errors.New(fmt.Sprintf("telemetry batch must have between 1 and %d readings", maxTelemetryBatch))
+
+type ITelemetryUsecase interface {
+	RecordTelemetry(droneID int, readings []TelemetryObject) (DroneDetails, error)
+	ListTelemetry(droneID int, query TelemetryQuery) ([]repo.Telemetry, error)
+	CompactTelemetry()
+}
+
+type telemetryUsecase struct {
+	telemetryRepo     repo.ITelemetryRepository
+	droneRepo         repo.IDroneRepository
+	drones            IDroneUsecase
+	clock             clock.Clock
+	minLoadingBattery int
+	retention         time.Duration
+	downsampleAfter   time.Duration
+	downsampleBucket  time.Duration
+	// downsampledUntil is the end of the window downsampled by the last run, zero before the first run
+	downsampledUntil time.Time
+	compacting       sync.Mutex
+}
+
+func NewTelemetryUsecase(t repo.ITelemetryRepository, d repo.IDroneRepository, drones IDroneUsecase, config settings.Telemetry, battery settings.Battery, clock clock.Clock) ITelemetryUsecase {
+	return &telemetryUsecase{
+		telemetryRepo:     t,
+		droneRepo:         d,
+		drones:            drones,
+		clock:             clock,
+		minLoadingBattery: battery.MinLoadingLevel,
+		retention:         config.Retention.Duration,
+		downsampleAfter:   config.DownsampleAfter.Duration,
+		downsampleBucket:  config.DownsampleBucket.Duration,
+	}
+}
+
+// RecordTelemetry stores the readings of the drone and updates the drone from the newest of them with the
+// values it reported. A reported state moves the drone through its lifecycle like a request would, a state
+// the drone can not move into is logged and the drone keeps its state. A reported battery level is published
+// and grounds the drone like the battery check does.
+func (t *telemetryUsecase) RecordTelemetry(droneID int, readings []TelemetryObject) (DroneDetails, error) {
+	if len(readings) == 0 || len(readings) > maxTelemetryBatch {
+		return DroneDetails{}, ErrTelemetryBatchSize
+	}
+	drone, err := t.droneRepo.Get(droneID)
+	if err != nil {
+		return DroneDetails{}, droneError(err)
+	}
+	stored := make([]repo.Telemetry, 0, len(readings))
+	latest := 0
+	for i, reading := range readings {
+		if err := t.validateReading(reading); err != nil {
+			if len(readings) > 1 {
+				return DroneDetails{}, errors.New(fmt.Sprintf("reading %d: %v", i, err))
+			}
+			return DroneDetails{}, err
+		}
+		stored = append(stored, repo.Telemetry{
+			DroneID:      droneID,
+			RecordedAt:   reading.RecordedAt.UTC(),
+			BatteryLevel: reading.BatteryLevel,
+			Latitude:     reading.Latitude,
+			Longitude:    reading.Longitude,
+			Altitude:     reading.Altitude,
+			Speed:        reading.Speed,
+			State:        reading.State,
+			Samples:      1,
+		})
+		if reading.RecordedAt.After(readings[latest].RecordedAt) {
+			latest = i
+		}
+	}
+	if err := t.telemetryRepo.Create(stored); err != nil {
+		return DroneDetails{}, err
+	}
+	reading := stored[latest]
+	if err := t.droneRepo.UpdateSnapshot(droneID, reading); err != nil {
+		return DroneDetails{}, droneError(err)
+	}
+	newer := drone.TelemetryAt == nil || reading.RecordedAt.After(*drone.TelemetryAt)
+	if newer && reading.BatteryLevel != nil {
+		drain := 0.0
+		if drone.TelemetryAt != nil {
+			drain = drainRate(float64(drone.BatteryCapacity), *reading.BatteryLevel, reading.RecordedAt.Sub(*drone.TelemetryAt))
+		}
+		t.drones.BatteryChanged(drone, int(math.Round(*reading.BatteryLevel)), drain)
+	}
+	if newer && reading.State != "" && reading.State != drone.State {
+		if err := t.reportState(droneID, reading.State); err != nil {
+			log.Println(fmt.Sprintf("drone %d reported the %s state: %v", droneID, reading.State, err))
+		}
+	}
+	if drone, err = t.droneRepo.Get(droneID); err != nil {
+		return DroneDetails{}, droneError(err)
+	}
+	return newDroneDetails(drone, t.minLoadingBattery), nil
+}
+
+// reportState moves the drone into the state it reported, a delivering drone leaves its dock
+// and a returning drone handed its medications over like they do on request.
+func (t *telemetryUsecase) reportState(droneID int, state string) error {
+	switch state {
+	case StateDelivering:
+		return t.drones.StartDelivery(droneID)
+	case StateDelivered:
+		return t.drones.MarkDelivered(droneID)
+	case StateReturning:
+		return t.drones.ReturnToBase(droneID)
+	}
+	return t.drones.ChangeDroneState(droneID, state)
+}
+
+func (t *telemetryUsecase) validateReading(reading TelemetryObject) error {
+	readingValidate, err := govalidator.ValidateStruct(reading)
+	if err != nil || !readingValidate {
+		return err
+	}
+	if reading.RecordedAt.IsZero() {
+		return errors.New("Recorded at is not provided")
+	}
+	if reading.RecordedAt.After(t.clock.Now().Add(telemetryClockSkew)) {
+		return errors.New(fmt.Sprintf("Recorded at %v is in the future", reading.RecordedAt))
+	}
+	if (reading.Latitude == nil) != (reading.Longitude == nil) {
+		return errors.New("Latitude and longitude are reported together")
+	}
+	if reading.Latitude == nil {
+		return nil
+	}
+	return validateLocation(*reading.Latitude, *reading.Longitude)
+}
+
+// ListTelemetry lists the readings of the drone, the oldest first.
+func (t *telemetryUsecase) ListTelemetry(droneID int, query TelemetryQuery) ([]repo.Telemetry, error) {
+	queryValidate, err := govalidator.ValidateStruct(query)
+	if err != nil || !queryValidate {
+		return nil, err
+	}
+	if !query.From.IsZero() && !query.To.IsZero() && !query.From.Before(query.To) {
+		return nil, errors.New("Telemetry from must be before to")
+	}
+	if _, err := t.droneRepo.Get(droneID); err != nil {
+		return nil, droneError(err)
+	}
+	filter := repo.TelemetryFilter{DroneID: droneID, Limit: query.Limit}
+	if filter.Limit == 0 {
+		filter.Limit = defaultTelemetryLimit
+	}
+	if !query.From.IsZero() {
+		filter.From = query.From.UTC()
+	}
+	if !query.To.IsZero() {
+		filter.To = query.To.UTC()
+	}
+	return t.telemetryRepo.List(filter)
+}
+
+// CompactTelemetry deletes the readings older than the retention and downsamples the readings that got older
+// than the downsampling age since the last run, the first run downsamples all of them.
+func (t *telemetryUsecase) CompactTelemetry() {
+	t.compacting.Lock()
+	defer t.compacting.Unlock()
+	now := t.clock.Now().UTC()
+	if _, err := t.telemetryRepo.DeleteBefore(now.Add(-t.retention)); err != nil {
+		log.Println(err.Error())
+	}
+	// the window ends at the start of a bucket so no bucket is split between two runs
+	before := now.Add(-t.downsampleAfter).Truncate(t.downsampleBucket)
+	if !before.After(t.downsampledUntil) {
+		return
+	}
+	if _, err := t.telemetryRepo.Downsample(t.downsampledUntil, before, t.downsampleBucket); err != nil {
+		log.Println(err.Error())
+		return
+	}
+	t.downsampledUntil = before
+}
@@ -0,0 +1,246 @@
+package usecase
+
+import (
+	"drone/v2/clock"
+	repo "drone/v2/repository"
+	"drone/v2/settings"
+	"testing"
+	"time"
+)
+
+func newMemoryTelemetryUsecase(t *testing.T, drone repo.Drone) (*telemetryUsecase, repo.IDroneRepository, *clock.Fake) {
+	t.Helper()
+	clock := clock.NewFake(testTime)
+	droneRepo := repo.NewMemoryDroneRepo(repo.NewMemoryLogRepository(clock))
+	createDrones(t, droneRepo, drone)
+	config := settings.Telemetry{
+		Retention:        settings.Duration{Duration: 48 * time.Hour},
+		DownsampleAfter:  settings.Duration{Duration: time.Hour},
+		DownsampleBucket: settings.Duration{Duration: 5 * time.Minute},
+	}
+	battery := settings.Battery{MinLoadingLevel: 25}
+	drones := NewDroneUsecase(droneRepo, repo.NewMemoryMedicationRepo(), battery, clock, nil, nil)
+	u := NewTelemetryUsecase(repo.NewMemoryTelemetryRepo(), droneRepo, drones, config, battery, clock)
+	return u.(*telemetryUsecase), droneRepo, clock
+}
+
+func Test_telemetryUsecase_RecordTelemetry(t *testing.T) {
+	tests := []struct {
+		name     string
+		droneID  int
+		readings []TelemetryObject
+		wantErr  string
+	}{
+		{name: "no readings", droneID: 1, wantErr: ErrTelemetryBatchSize.Error()},
+		{name: "missing drone", droneID: 2, readings: []TelemetryObject{{RecordedAt: testTime, BatteryLevel: floatPointer(50)}}, wantErr: ErrDroneNotFound.Error()},
+		{name: "no time", droneID: 1, readings: []TelemetryObject{{BatteryLevel: floatPointer(50)}}, wantErr: "Recorded at is not provided"},
+		{name: "future reading", droneID: 1, readings: []TelemetryObject{{RecordedAt: testTime.Add(time.Hour), BatteryLevel: floatPointer(50)}},
+			wantErr: "Recorded at 2026-10-18 13:00:00 +0000 UTC is in the future"},
+		{name: "battery out of range", droneID: 1, readings: []TelemetryObject{{RecordedAt: testTime, BatteryLevel: floatPointer(120)}},
+			wantErr: "battery_level: 120 does not validate as range(0|100)"},
+		{name: "unknown state", droneID: 1, readings: []TelemetryObject{{RecordedAt: testTime, BatteryLevel: floatPointer(50), State: "FLYING"}},
+			wantErr: "state: FLYING does not validate as matches(^(IDLE|LOADING|LOADED|DELIVERING|DELIVERED|RETURNING)$)"},
+		{name: "invalid reading in a batch", droneID: 1, readings: []TelemetryObject{{RecordedAt: testTime, BatteryLevel: floatPointer(50)}, {RecordedAt: testTime, Latitude: floatPointer(100), Longitude: floatPointer(0)}},
+			wantErr: "reading 1: Invaild location 100,0"},
+		{name: "latitude without longitude", droneID: 1, readings: []TelemetryObject{{RecordedAt: testTime, Latitude: floatPointer(30)}},
+			wantErr: "Latitude and longitude are reported together"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, droneRepo, _ := newMemoryTelemetryUsecase(t, repo.Drone{SerialNumber: "serial 1", State: StateIdle, BatteryCapacity: 100})
+			_, err := u.RecordTelemetry(tt.droneID, tt.readings)
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("telemetryUsecase.RecordTelemetry() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if readings, _ := u.telemetryRepo.List(repo.TelemetryFilter{DroneID: 1}); len(readings) != 0 {
+				t.Errorf("telemetryUsecase.RecordTelemetry() stored %v, want nothing", readings)
+			}
+			if drone, _ := droneRepo.Get(1); drone.TelemetryAt != nil {
+				t.Errorf("telemetryUsecase.RecordTelemetry() updated the drone to %+v", drone)
+			}
+		})
+	}
+}
+
+func Test_telemetryUsecase_RecordTelemetry_Snapshot(t *testing.T) {
+	u, droneRepo, _ := newMemoryTelemetryUsecase(t, repo.Drone{SerialNumber: "serial 1", State: StateLoaded, BatteryCapacity: 100, Weight: 500})
+	cairo := time.FixedZone("Cairo", 3*60*60)
+	details, err := u.RecordTelemetry(1, []TelemetryObject{
+		{RecordedAt: testTime.Add(-time.Minute), BatteryLevel: floatPointer(97.4), Latitude: floatPointer(30.05), Longitude: floatPointer(31.2), Altitude: floatPointer(80), Speed: floatPointer(45), State: StateDelivering},
+		{RecordedAt: testTime.Add(-2 * time.Minute).In(cairo), BatteryLevel: floatPointer(99), Latitude: floatPointer(30.04), Longitude: floatPointer(31.2)},
+	})
+	if err != nil {
+		t.Fatalf("telemetryUsecase.RecordTelemetry() error = %v", err)
+	}
+	if details.BatteryCapacity != 97 || details.State != StateDelivering || details.Latitude != 30.05 || details.Altitude != 80 ||
+		details.Speed != 45 || details.TelemetryAt == nil || !details.TelemetryAt.Equal(testTime.Add(-time.Minute)) {
+		t.Errorf("telemetryUsecase.RecordTelemetry() = %+v, want the drone updated from the newest reading", details)
+	}
+
+	// a late reading is stored but does not move the drone back
+	if _, err := u.RecordTelemetry(1, []TelemetryObject{{RecordedAt: testTime.Add(-90 * time.Second), BatteryLevel: floatPointer(98), State: StateLoaded}}); err != nil {
+		t.Fatalf("telemetryUsecase.RecordTelemetry() of a late reading error = %v", err)
+	}
+	if drone, _ := droneRepo.Get(1); drone.BatteryCapacity != 97 || drone.State != StateDelivering {
+		t.Errorf("drone = %v with %d%%, want DELIVERING with 97%%", drone.State, drone.BatteryCapacity)
+	}
+
+	readings, err := u.ListTelemetry(1, TelemetryQuery{})
+	if err != nil || len(readings) != 3 {
+		t.Fatalf("telemetryUsecase.ListTelemetry() = %v, %v, want 3 readings", readings, err)
+	}
+	if *readings[0].BatteryLevel != 99 || readings[0].RecordedAt.Location() != time.UTC || *readings[2].BatteryLevel != 97.4 {
+		t.Errorf("telemetryUsecase.ListTelemetry() = %+v, want the readings in UTC, the oldest first", readings)
+	}
+}
+
+func Test_telemetryUsecase_RecordTelemetry_Lifecycle(t *testing.T) {
+	u, droneRepo, _ := newMemoryTelemetryUsecase(t, repo.Drone{
+		SerialNumber:    "serial 1",
+		State:           StateLoaded,
+		BatteryCapacity: 100,
+		Weight:          500,
+		CurrentPayload:  10,
+		Medications:     []repo.LoadItem{{MedicationCode: "code", Quantity: 1, Weight: 10}},
+	})
+	record := func(minute int, reading TelemetryObject) repo.Drone {
+		t.Helper()
+		reading.RecordedAt = testTime.Add(time.Duration(minute-10) * time.Minute)
+		if _, err := u.RecordTelemetry(1, []TelemetryObject{reading}); err != nil {
+			t.Fatalf("telemetryUsecase.RecordTelemetry() error = %v", err)
+		}
+		drone, _ := droneRepo.Get(1)
+		return drone
+	}
+
+	record(0, TelemetryObject{BatteryLevel: floatPointer(90), Latitude: floatPointer(30.05), Longitude: floatPointer(31.2), Altitude: floatPointer(80)})
+	// the values that are not reported are kept
+	if drone := record(1, TelemetryObject{Speed: floatPointer(10)}); drone.BatteryCapacity != 90 || drone.Latitude != 30.05 ||
+		drone.Longitude != 31.2 || drone.Altitude != 80 || drone.Speed != 10 {
+		t.Errorf("drone = %+v, want the values of the first reading kept", drone)
+	}
+	// a state the drone can not move into is not applied
+	if drone := record(2, TelemetryObject{State: StateDelivered}); drone.State != StateLoaded {
+		t.Errorf("drone state = %v, want %v", drone.State, StateLoaded)
+	}
+	for i, state := range []string{StateDelivering, StateDelivered, StateReturning} {
+		if drone := record(3+i, TelemetryObject{State: state}); drone.State != state {
+			t.Errorf("drone state = %v, want %v", drone.State, state)
+		}
+	}
+	// the medications were handed over once the drone returns
+	if drone, _ := droneRepo.Get(1); len(drone.Medications) != 0 || drone.CurrentPayload != 0 {
+		t.Errorf("drone = %+v, want it unloaded", drone)
+	}
+}
+
+func Test_telemetryUsecase_RecordTelemetry_Grounding(t *testing.T) {
+	u, droneRepo, _ := newMemoryTelemetryUsecase(t, repo.Drone{SerialNumber: "serial 1", State: StateDelivering, BatteryCapacity: 12})
+	drones := u.drones.(*droneUsecase)
+	drones.criticalBattery = 10
+	drones.events = NewEventBus(drones.jobs.clock)
+	subscription, _ := drones.events.Subscribe(EventFilter{Types: []string{EventBatteryTick, EventDroneGrounded}}, 0)
+
+	if _, err := u.RecordTelemetry(1, []TelemetryObject{{RecordedAt: testTime, BatteryLevel: floatPointer(9.2)}}); err != nil {
+		t.Fatalf("telemetryUsecase.RecordTelemetry() error = %v", err)
+	}
+	events := nextEvents(t, subscription, 2)
+	if events[0].Type != EventBatteryTick || events[0].Data != (BatteryEvent{Level: 9, State: StateDelivering}) {
+		t.Errorf("battery event = %+v, want a tick to 9%% while delivering", events[0])
+	}
+	if events[1].Type != EventDroneGrounded || events[1].Data != (BatteryEvent{Level: 9, State: StateDelivering}) {
+		t.Errorf("grounded event = %+v, want the delivering drone grounded at 9%%", events[1])
+	}
+	if drone, _ := droneRepo.Get(1); !drone.Grounded {
+		t.Errorf("drone = %+v, want it grounded", drone)
+	}
+}
+
+func Test_telemetryUsecase_ListTelemetry(t *testing.T) {
+	u, _, _ := newMemoryTelemetryUsecase(t, repo.Drone{SerialNumber: "serial 1", State: StateIdle, BatteryCapacity: 100})
+	for i := 0; i < 3; i++ {
+		reading := TelemetryObject{RecordedAt: testTime.Add(time.Duration(i-3) * time.Minute), BatteryLevel: floatPointer(float64(90 - i))}
+		if _, err := u.RecordTelemetry(1, []TelemetryObject{reading}); err != nil {
+			t.Fatalf("telemetryUsecase.RecordTelemetry() error = %v", err)
+		}
+	}
+	tests := []struct {
+		name    string
+		droneID int
+		query   TelemetryQuery
+		want    []float64
+		wantErr string
+	}{
+		{name: "all", droneID: 1, want: []float64{90, 89, 88}},
+		{name: "time range", droneID: 1, query: TelemetryQuery{From: testTime.Add(-2 * time.Minute), To: testTime.Add(-time.Minute)}, want: []float64{89}},
+		{name: "limit", droneID: 1, query: TelemetryQuery{Limit: 2}, want: []float64{90, 89}},
+		{name: "limit out of range", droneID: 1, query: TelemetryQuery{Limit: 5000}, wantErr: "Limit: 5000 does not validate as range(1|1000)"},
+		{name: "empty range", droneID: 1, query: TelemetryQuery{From: testTime, To: testTime}, wantErr: "Telemetry from must be before to"},
+		{name: "missing drone", droneID: 2, wantErr: ErrDroneNotFound.Error()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := u.ListTelemetry(tt.droneID, tt.query)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("telemetryUsecase.ListTelemetry() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || len(got) != len(tt.want) {
+				t.Fatalf("telemetryUsecase.ListTelemetry() = %v, %v, want batteries %v", got, err, tt.want)
+			}
+			for i, want := range tt.want {
+				if *got[i].BatteryLevel != want {
+					t.Errorf("telemetryUsecase.ListTelemetry()[%d] battery = %v, want %v", i, *got[i].BatteryLevel, want)
+				}
+			}
+		})
+	}
+}
+
+func Test_telemetryUsecase_CompactTelemetry(t *testing.T) {
+	u, _, clock := newMemoryTelemetryUsecase(t, repo.Drone{SerialNumber: "serial 1", State: StateIdle, BatteryCapacity: 100})
+	readings := []TelemetryObject{}
+	for i := 0; i < 4; i++ {
+		readings = append(readings, TelemetryObject{RecordedAt: testTime.Add(time.Duration(i) * time.Minute), BatteryLevel: floatPointer(float64(90 - i))})
+	}
+	clock.Advance(10 * time.Minute)
+	if _, err := u.RecordTelemetry(1, readings); err != nil {
+		t.Fatalf("telemetryUsecase.RecordTelemetry() error = %v", err)
+	}
+	wantReadings := func(step string, want int) []repo.Telemetry {
+		t.Helper()
+		got, _ := u.ListTelemetry(1, TelemetryQuery{})
+		if len(got) != want {
+			t.Errorf("%s: readings = %+v, want %d", step, got, want)
+		}
+		return got
+	}
+
+	u.CompactTelemetry()
+	wantReadings("recent readings", 4)
+	clock.Advance(time.Hour)
+	u.CompactTelemetry()
+	if got := wantReadings("downsampled", 1); len(got) == 1 && (got[0].Samples != 4 || *got[0].BatteryLevel != 88.5) {
+		t.Errorf("downsampled reading = %+v, want 4 samples with battery 88.5", got[0])
+	}
+	// the next run only downsamples the readings that got old since
+	late := []TelemetryObject{
+		{RecordedAt: testTime.Add(20 * time.Minute), BatteryLevel: floatPointer(80)},
+		{RecordedAt: testTime.Add(21 * time.Minute), BatteryLevel: floatPointer(78)},
+	}
+	if _, err := u.RecordTelemetry(1, late); err != nil {
+		t.Fatalf("telemetryUsecase.RecordTelemetry() error = %v", err)
+	}
+	u.CompactTelemetry()
+	wantReadings("not old enough", 3)
+	clock.Advance(15 * time.Minute)
+	u.CompactTelemetry()
+	if got := wantReadings("downsampled again", 2); len(got) == 2 && (got[1].Samples != 2 || *got[1].BatteryLevel != 79) {
+		t.Errorf("downsampled reading = %+v, want 2 samples with battery 79", got[1])
+	}
+	clock.Advance(48 * time.Hour)
+	u.CompactTelemetry()
+	wantReadings("expired", 0)
+}
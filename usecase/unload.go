@@ -0,0 +1,65 @@
+package usecase
+
+import (
+	repo "drone/v2/repository"
+	"errors"
+	"fmt"
+)
+
+var ErrLoadingInProgress = errors.New("drone has loading jobs in progress, try again later")
+
+type UnloadStateError struct {
+	DroneID int
+	State   string
+}
+
+func (e *UnloadStateError) Error() string {
+	return fmt.Sprintf("drone %d can not be unloaded in %s state", e.DroneID, e.State)
+}
+
+// UnloadMedication takes the medication with the given code off the drone,
+// the drone goes back to IDLE once it has no medications left.
+func (d *droneUsecase) UnloadMedication(id int, code string) (DroneDetails, error) {
+	return d.unload(id, func(drone repo.Drone) error {
+		return medicationError(d.droneRepo.RemoveMedication(id, drone.Version, code))
+	})
+}
+
+// UnloadDrone takes all the medications off the drone and moves it back to IDLE.
+func (d *droneUsecase) UnloadDrone(id int) (DroneDetails, error) {
+	return d.unload(id, func(drone repo.Drone) error {
+		if len(drone.Medications) == 0 {
+			return nil
+		}
+		return d.droneRepo.Unload(id, drone.Version)
+	})
+}
+
+func (d *droneUsecase) unload(id int, remove func(drone repo.Drone) error) (DroneDetails, error) {
+	unlock := d.jobs.lockDrone(id)
+	defer unlock()
+	drone, err := d.droneRepo.Get(id)
+	if err != nil {
+		return DroneDetails{}, droneError(err)
+	}
+	if drone.State != StateLoading && drone.State != StateLoaded {
+		return DroneDetails{}, &UnloadStateError{DroneID: id, State: drone.State}
+	}
+	if d.jobs.pendingFor(id) > 0 {
+		return DroneDetails{}, ErrLoadingInProgress
+	}
+	if err := remove(drone); err != nil {
+		return DroneDetails{}, err
+	}
+	drone, err = d.droneRepo.Get(id)
+	if err != nil {
+		return DroneDetails{}, droneError(err)
+	}
+	if len(drone.Medications) == 0 {
+		if err := d.transition(drone, StateIdle); err != nil {
+			return DroneDetails{}, err
+		}
+		drone.State = StateIdle
+	}
+	return newDroneDetails(drone, d.minLoadingBattery), nil
+}
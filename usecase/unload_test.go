@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	repo "drone/v2/repository"
+	"errors"
+	"testing"
+)
+
+func newLoadedDroneUsecase(t *testing.T, state string) (*droneUsecase, repo.IDroneRepository) {
+	d, droneRepo, _ := newMemoryDroneUsecase(t, repo.Drone{
+		SerialNumber:    "serial 1",
+		Weight:          500,
+		State:           state,
+		BatteryCapacity: 100,
+		CurrentPayload:  30,
+		Medications: []repo.LoadItem{
+			{MedicationCode: "code", Quantity: 1, Weight: 10},
+			{MedicationCode: "other", Quantity: 2, Weight: 20},
+		},
+	})
+	return d, droneRepo
+}
+
+func Test_droneUsecase_UnloadMedication(t *testing.T) {
+	tests := []struct {
+		name        string
+		state       string
+		codes       []string
+		wantPayload float32
+		wantState   string
+		wantErr     error
+	}{
+		{
+			name:        "unload one medication",
+			state:       StateLoaded,
+			codes:       []string{"code"},
+			wantPayload: 20,
+			wantState:   StateLoaded,
+		},
+		{
+			name:        "drone becomes idle when empty",
+			state:       StateLoading,
+			codes:       []string{"code", "other"},
+			wantPayload: 0,
+			wantState:   StateIdle,
+		},
+		{
+			name:        "medication is not on the drone",
+			state:       StateLoaded,
+			codes:       []string{"missing"},
+			wantPayload: 30,
+			wantState:   StateLoaded,
+			wantErr:     ErrMedicationNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, droneRepo := newLoadedDroneUsecase(t, tt.state)
+			var err error
+			for _, code := range tt.codes {
+				_, err = d.UnloadMedication(1, code)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("droneUsecase.UnloadMedication() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			drone, _ := droneRepo.Get(1)
+			if drone.CurrentPayload != tt.wantPayload || drone.State != tt.wantState {
+				t.Errorf("drone = %v %v, want %v %v", drone.CurrentPayload, drone.State, tt.wantPayload, tt.wantState)
+			}
+		})
+	}
+}
+
+func Test_droneUsecase_UnloadDrone(t *testing.T) {
+	d, _ := newLoadedDroneUsecase(t, StateLoaded)
+	got, err := d.UnloadDrone(1)
+	if err != nil {
+		t.Fatalf("droneUsecase.UnloadDrone() error = %v", err)
+	}
+	if got.CurrentPayload != 0 || got.State != StateIdle || got.RemainingCapacity != 500 {
+		t.Errorf("droneUsecase.UnloadDrone() = %+v, want empty IDLE drone", got)
+	}
+
+	d, _ = newLoadedDroneUsecase(t, StateDelivering)
+	_, err = d.UnloadDrone(1)
+	var stateErr *UnloadStateError
+	if !errors.As(err, &stateErr) {
+		t.Errorf("droneUsecase.UnloadDrone() error = %v, want UnloadStateError", err)
+	}
+
+	if _, err = d.UnloadDrone(2); !errors.Is(err, ErrDroneNotFound) {
+		t.Errorf("droneUsecase.UnloadDrone() of a missing drone error = %v, want %v", err, ErrDroneNotFound)
+	}
+}
@@ -0,0 +1,370 @@
+package usecase
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"drone/v2/clock"
+	repo "drone/v2/repository"
+	"drone/v2/settings"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/asaskevich/govalidator"
+)
+
+const (
+	WebhookDroneLoaded    = "drone-loaded"
+	WebhookDroneDelivered = "drone-delivered"
+	WebhookBatteryLow     = "battery-low"
+)
+
+// webhookFilter picks the fleet events webhook events are made of
+var webhookFilter = EventFilter{Types: []string{EventStateChanged, EventBatteryLow}}
+
+var webhookEvents = map[string]bool{
+	WebhookDroneLoaded:    true,
+	WebhookDroneDelivered: true,
+	WebhookBatteryLow:     true,
+}
+
+const (
+	DeliveryPending   = "PENDING"
+	DeliverySucceeded = "SUCCEEDED"
+	DeliveryFailed    = "FAILED"
+)
+
+const (
+	// SignatureHeader holds sha256= and the hex HMAC-SHA256 of the body keyed with the webhook secret
+	SignatureHeader = "X-Drone-Signature"
+	EventHeader     = "X-Drone-Event"
+	DeliveryHeader  = "X-Drone-Delivery"
+	// deliveryBatchSize is how many due deliveries are attempted in one run
+	deliveryBatchSize = 100
+	maxRetryBackoff   = 24 * time.Hour
+)
+
+var ErrWebhookNotFound = errors.New("webhook is not exist")
+
+type IWebhookUsecase interface {
+	CreateWebhook(object WebhookObject) (CreatedWebhook, error)
+	GetWebhook(id int) (WebhookDetails, error)
+	ListWebhooks() ([]WebhookDetails, error)
+	DeleteWebhook(id int) error
+	ListDeliveries(id int) ([]repo.WebhookDelivery, error)
+	DeliverWebhooks()
+}
+
+// WebhookPayload is the signed json body a webhook receives.
+type WebhookPayload struct {
+	EventID int64     `json:"event_id"`
+	Event   string    `json:"event"`
+	DroneID int       `json:"drone_id"`
+	At      time.Time `json:"at"`
+	Data    any       `json:"data"`
+}
+
+type webhookUsecase struct {
+	// mu lets one run attempt the due deliveries at a time so a delivery is not sent twice
+	mu           sync.Mutex
+	webhookRepo  repo.IWebhookRepository
+	client       *http.Client
+	clock        clock.Clock
+	maxAttempts  int
+	retryBackoff time.Duration
+	// wake asks the delivery worker to attempt the due deliveries
+	wake chan struct{}
+}
+
+// NewWebhookUsecase starts turning the fleet events into deliveries to the subscribed webhooks
+// and attempting them as soon as they are created.
+func NewWebhookUsecase(w repo.IWebhookRepository, events IEventBus, config settings.Webhooks, clock clock.Clock) IWebhookUsecase {
+	usecase := &webhookUsecase{
+		webhookRepo:  w,
+		client:       &http.Client{Timeout: config.Timeout.Duration},
+		clock:        clock,
+		maxAttempts:  config.MaxAttempts,
+		retryBackoff: config.RetryBackoff.Duration,
+		wake:         make(chan struct{}, 1),
+	}
+	if subscription, err := events.Subscribe(webhookFilter, 0); err != nil {
+		log.Println(err.Error())
+	} else {
+		go usecase.listen(events, subscription)
+	}
+	go func() {
+		for range usecase.wake {
+			usecase.DeliverWebhooks()
+		}
+	}()
+	return usecase
+}
+
+func (w *webhookUsecase) CreateWebhook(object WebhookObject) (CreatedWebhook, error) {
+	webhookValidate, err := govalidator.ValidateStruct(object)
+	if err != nil || !webhookValidate {
+		return CreatedWebhook{}, err
+	}
+	if target, err := url.Parse(object.URL); err != nil || (target.Scheme != "http" && target.Scheme != "https") {
+		return CreatedWebhook{}, errors.New(fmt.Sprintf("Webhook url %s must be a http or https url", object.URL))
+	}
+	for _, event := range object.Events {
+		if !webhookEvents[event] {
+			return CreatedWebhook{}, errors.New(fmt.Sprintf("webhook event %q is not exist", event))
+		}
+	}
+	if object.Secret == "" {
+		if object.Secret, err = newSecret(); err != nil {
+			return CreatedWebhook{}, err
+		}
+	}
+	webhook := repo.Webhook{URL: object.URL, Secret: object.Secret, Events: strings.Join(object.Events, ",")}
+	if _, err := w.webhookRepo.Create(&webhook); err != nil {
+		return CreatedWebhook{}, err
+	}
+	return CreatedWebhook{WebhookDetails: newWebhookDetails(webhook), Secret: webhook.Secret}, nil
+}
+
+func (w *webhookUsecase) GetWebhook(id int) (WebhookDetails, error) {
+	webhook, err := w.webhookRepo.Get(id)
+	if err != nil {
+		return WebhookDetails{}, webhookError(err)
+	}
+	return newWebhookDetails(webhook), nil
+}
+
+func (w *webhookUsecase) ListWebhooks() ([]WebhookDetails, error) {
+	webhooks, err := w.webhookRepo.List()
+	if err != nil {
+		return nil, err
+	}
+	details := make([]WebhookDetails, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		details = append(details, newWebhookDetails(webhook))
+	}
+	return details, nil
+}
+
+// DeleteWebhook unsubscribes the webhook, its pending deliveries are not attempted anymore.
+func (w *webhookUsecase) DeleteWebhook(id int) error {
+	return webhookError(w.webhookRepo.Delete(id))
+}
+
+// ListDeliveries lists the deliveries of the webhook with their attempts, the newest first.
+func (w *webhookUsecase) ListDeliveries(id int) ([]repo.WebhookDelivery, error) {
+	if _, err := w.webhookRepo.Get(id); err != nil {
+		return nil, webhookError(err)
+	}
+	return w.webhookRepo.ListDeliveries(id)
+}
+
+// listen creates the deliveries of the events, it subscribes again after the last event
+// it handled when the subscription falls behind.
+func (w *webhookUsecase) listen(events IEventBus, subscription *Subscription) {
+	var lastEventID int64
+	for {
+		for event := range subscription.Events {
+			lastEventID = event.ID
+			w.enqueue(event)
+		}
+		var err error
+		if subscription, err = events.Subscribe(webhookFilter, lastEventID); err != nil {
+			log.Println(err.Error())
+			return
+		}
+	}
+}
+
+// webhookEvent is the webhook event of the fleet event, empty when webhooks do not get it.
+func webhookEvent(event Event) string {
+	switch event.Type {
+	case EventBatteryLow:
+		return WebhookBatteryLow
+	case EventStateChanged:
+		if change, ok := event.Data.(StateChangedEvent); ok {
+			switch change.To {
+			case StateLoaded:
+				return WebhookDroneLoaded
+			case StateDelivered:
+				return WebhookDroneDelivered
+			}
+		}
+	}
+	return ""
+}
+
+// enqueue creates a delivery of the event to every webhook subscribed to it and wakes the delivery worker.
+func (w *webhookUsecase) enqueue(event Event) {
+	name := webhookEvent(event)
+	if name == "" {
+		return
+	}
+	webhooks, err := w.webhookRepo.List()
+	if err != nil {
+		log.Println(err.Error())
+		return
+	}
+	payload, err := json.Marshal(WebhookPayload{EventID: event.ID, Event: name, DroneID: event.DroneID, At: event.At, Data: event.Data})
+	if err != nil {
+		log.Println(err.Error())
+		return
+	}
+	now := w.clock.Now()
+	deliveries := []repo.WebhookDelivery{}
+	for _, webhook := range webhooks {
+		if !subscribed(webhook, name) {
+			continue
+		}
+		deliveries = append(deliveries, repo.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			EventID:       event.ID,
+			EventType:     name,
+			Payload:       string(payload),
+			Status:        DeliveryPending,
+			NextAttemptAt: &now,
+		})
+	}
+	if len(deliveries) == 0 {
+		return
+	}
+	if err := w.webhookRepo.CreateDeliveries(deliveries); err != nil {
+		log.Println(err.Error())
+		return
+	}
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+func subscribed(webhook repo.Webhook, event string) bool {
+	if webhook.Events == "" {
+		return true
+	}
+	for _, subscribed := range strings.Split(webhook.Events, ",") {
+		if subscribed == event {
+			return true
+		}
+	}
+	return false
+}
+
+// DeliverWebhooks attempts the deliveries due now, a failed delivery is attempted again after the
+// retry backoff doubled for every failed attempt until it runs out of attempts.
+func (w *webhookUsecase) DeliverWebhooks() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	deliveries, err := w.webhookRepo.DueDeliveries(w.clock.Now(), deliveryBatchSize)
+	if err != nil {
+		log.Println(err.Error())
+		return
+	}
+	webhooks := map[int]repo.Webhook{}
+	for _, delivery := range deliveries {
+		webhook, found := webhooks[delivery.WebhookID]
+		if !found {
+			if webhook, err = w.webhookRepo.Get(delivery.WebhookID); err != nil {
+				log.Println(err.Error())
+				continue
+			}
+			webhooks[webhook.ID] = webhook
+		}
+		if err := w.webhookRepo.RecordAttempt(w.attempt(webhook, delivery)); err != nil {
+			log.Println(err.Error())
+		}
+	}
+}
+
+// attempt sends the delivery and returns it updated with the attempt.
+func (w *webhookUsecase) attempt(webhook repo.Webhook, delivery repo.WebhookDelivery) (repo.WebhookDelivery, repo.WebhookAttempt) {
+	now := w.clock.Now()
+	statusCode, err := w.send(webhook, delivery)
+	attempt := repo.WebhookAttempt{AttemptedAt: now, StatusCode: statusCode}
+	if err != nil {
+		attempt.Error = err.Error()
+	}
+	delivery.AttemptCount++
+	switch {
+	case attempt.Error == "":
+		delivery.Status, delivery.NextAttemptAt = DeliverySucceeded, nil
+	case delivery.AttemptCount >= w.maxAttempts:
+		delivery.Status, delivery.NextAttemptAt = DeliveryFailed, nil
+	default:
+		next := now.Add(w.backoff(delivery.AttemptCount))
+		delivery.NextAttemptAt = &next
+	}
+	return delivery, attempt
+}
+
+// backoff is the wait after the failed attempts, it doubles after every attempt up to maxRetryBackoff.
+func (w *webhookUsecase) backoff(attempts int) time.Duration {
+	backoff := w.retryBackoff
+	for i := 1; i < attempts && backoff < maxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxRetryBackoff {
+		return maxRetryBackoff
+	}
+	return backoff
+}
+
+func (w *webhookUsecase) send(webhook repo.Webhook, delivery repo.WebhookDelivery) (int, error) {
+	request, err := http.NewRequest(http.MethodPost, webhook.URL, strings.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(EventHeader, delivery.EventType)
+	request.Header.Set(DeliveryHeader, strconv.Itoa(delivery.ID))
+	request.Header.Set(SignatureHeader, Sign(webhook.Secret, []byte(delivery.Payload)))
+	response, err := w.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(response.Body, 64*1024))
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response.StatusCode, errors.New(fmt.Sprintf("receiver answered %s", response.Status))
+	}
+	return response.StatusCode, nil
+}
+
+// Sign is the signature header of the body, receivers compute it with their secret and compare.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func newSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+func newWebhookDetails(webhook repo.Webhook) WebhookDetails {
+	events := []string{}
+	if webhook.Events != "" {
+		events = strings.Split(webhook.Events, ",")
+	}
+	return WebhookDetails{ID: webhook.ID, URL: webhook.URL, Events: events, CreatedAt: webhook.CreatedAt}
+}
+
+func webhookError(err error) error {
+	if errors.Is(err, repo.ErrRecordNotFound) {
+		return ErrWebhookNotFound
+	}
+	return err
+}
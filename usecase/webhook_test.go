@@ -0,0 +1,185 @@
+package usecase
+
+import (
+	"drone/v2/clock"
+	repo "drone/v2/repository"
+	"drone/v2/settings"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type receivedWebhook struct {
+	header http.Header
+	body   []byte
+}
+
+// newWebhookReceiver answers the deliveries with the statuses in order, then with 204.
+func newWebhookReceiver(t *testing.T, statuses ...int) (*httptest.Server, chan receivedWebhook) {
+	t.Helper()
+	received := make(chan receivedWebhook, 10)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- receivedWebhook{header: r.Header, body: body}
+		status := http.StatusNoContent
+		if len(statuses) > 0 {
+			status, statuses = statuses[0], statuses[1:]
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(s.Close)
+	return s, received
+}
+
+func newMemoryWebhookUsecase(t *testing.T) (*webhookUsecase, IEventBus, *clock.Fake) {
+	t.Helper()
+	clock := clock.NewFake(testTime)
+	events := NewEventBus(clock)
+	config := settings.Webhooks{
+		Timeout:      settings.Duration{Duration: time.Second},
+		MaxAttempts:  3,
+		RetryBackoff: settings.Duration{Duration: 30 * time.Second},
+	}
+	u := NewWebhookUsecase(repo.NewMemoryWebhookRepo(clock), events, config, clock)
+	return u.(*webhookUsecase), events, clock
+}
+
+func nextWebhook(t *testing.T, received chan receivedWebhook) receivedWebhook {
+	t.Helper()
+	select {
+	case webhook := <-received:
+		return webhook
+	case <-time.After(5 * time.Second):
+		t.Fatal("the webhook was not delivered")
+	}
+	return receivedWebhook{}
+}
+
+// waitForDelivery waits for the attempt in flight to be recorded and checks the newest
+// delivery of the webhook has the attempts.
+func waitForDelivery(t *testing.T, u *webhookUsecase, webhookID int, attempts int) repo.WebhookDelivery {
+	t.Helper()
+	// an attempt holds the lock from sending the delivery until it is recorded
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	deliveries, err := u.ListDeliveries(webhookID)
+	if err != nil || len(deliveries) == 0 || deliveries[len(deliveries)-1].AttemptCount != attempts {
+		t.Fatalf("deliveries = %+v, %v, want %d attempts", deliveries, err, attempts)
+	}
+	return deliveries[len(deliveries)-1]
+}
+
+func Test_webhookUsecase_CreateWebhook(t *testing.T) {
+	tests := []struct {
+		name    string
+		object  WebhookObject
+		wantErr string
+	}{
+		{name: "no url", object: WebhookObject{}, wantErr: "Webhook url is not provided"},
+		{name: "not a url", object: WebhookObject{URL: "erp"}, wantErr: "url: erp does not validate as requrl"},
+		{name: "not http", object: WebhookObject{URL: "ftp://erp.example.com/hooks"}, wantErr: "Webhook url ftp://erp.example.com/hooks must be a http or https url"},
+		{name: "short secret", object: WebhookObject{URL: "https://erp.example.com/hooks", Secret: "secret"}, wantErr: "secret: secret does not validate as stringlength(16|256)"},
+		{name: "unknown event", object: WebhookObject{URL: "https://erp.example.com/hooks", Events: []string{"drone-crashed"}}, wantErr: `webhook event "drone-crashed" is not exist`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, _, _ := newMemoryWebhookUsecase(t)
+			if _, err := u.CreateWebhook(tt.object); err == nil || err.Error() != tt.wantErr {
+				t.Errorf("webhookUsecase.CreateWebhook() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	u, _, _ := newMemoryWebhookUsecase(t)
+	created, err := u.CreateWebhook(WebhookObject{URL: "https://erp.example.com/hooks", Events: []string{WebhookDroneLoaded, WebhookBatteryLow}})
+	if err != nil || created.ID != 1 || len(created.Secret) != 64 || strings.Join(created.Events, ",") != "drone-loaded,battery-low" {
+		t.Fatalf("webhookUsecase.CreateWebhook() = %+v, %v, want the webhook with a generated secret", created, err)
+	}
+	if got, err := u.GetWebhook(1); err != nil || got.URL != created.URL || !got.CreatedAt.Equal(testTime) {
+		t.Errorf("webhookUsecase.GetWebhook() = %+v, %v, want %+v", got, err, created.WebhookDetails)
+	}
+	if err := u.DeleteWebhook(1); err != nil {
+		t.Errorf("webhookUsecase.DeleteWebhook() error = %v", err)
+	}
+	if _, err := u.GetWebhook(1); err != ErrWebhookNotFound {
+		t.Errorf("webhookUsecase.GetWebhook() of a deleted webhook error = %v, wantErr %v", err, ErrWebhookNotFound)
+	}
+}
+
+func Test_webhookUsecase_Deliver(t *testing.T) {
+	u, events, clock := newMemoryWebhookUsecase(t)
+	receiver, received := newWebhookReceiver(t, http.StatusInternalServerError)
+	secret := "0123456789abcdef"
+	loaded, _ := u.CreateWebhook(WebhookObject{URL: receiver.URL, Secret: secret, Events: []string{WebhookDroneLoaded}})
+	lowBattery, _ := u.CreateWebhook(WebhookObject{URL: receiver.URL + "/battery", Secret: secret, Events: []string{WebhookBatteryLow}})
+
+	events.Publish(EventStateChanged, 1, StateChangedEvent{From: StateIdle, To: StateLoading})
+	events.Publish(EventStateChanged, 1, StateChangedEvent{From: StateLoading, To: StateLoaded})
+	webhook := nextWebhook(t, received)
+	if got := webhook.header.Get(SignatureHeader); got != Sign(secret, webhook.body) {
+		t.Errorf("signature = %s, want %s", got, Sign(secret, webhook.body))
+	}
+	var payload WebhookPayload
+	if err := json.Unmarshal(webhook.body, &payload); err != nil || payload.Event != WebhookDroneLoaded || payload.EventID != 2 || payload.DroneID != 1 ||
+		webhook.header.Get(EventHeader) != WebhookDroneLoaded || webhook.header.Get(DeliveryHeader) != "1" {
+		t.Errorf("webhook = %s %v, %v, want the drone loaded event", webhook.body, webhook.header, err)
+	}
+
+	// the receiver failed so the delivery is retried after the backoff
+	delivery := waitForDelivery(t, u, loaded.ID, 1)
+	if delivery.Status != DeliveryPending || delivery.NextAttemptAt == nil || !delivery.NextAttemptAt.Equal(testTime.Add(30*time.Second)) ||
+		len(delivery.Attempts) != 1 || delivery.Attempts[0].StatusCode != http.StatusInternalServerError {
+		t.Fatalf("delivery = %+v, want a failed attempt retried in 30s", delivery)
+	}
+	u.DeliverWebhooks()
+	if delivery := waitForDelivery(t, u, loaded.ID, 1); delivery.Status != DeliveryPending {
+		t.Errorf("delivery = %+v, want it not retried before the backoff", delivery)
+	}
+	clock.Advance(30 * time.Second)
+	u.DeliverWebhooks()
+	retried := nextWebhook(t, received)
+	if string(retried.body) != string(webhook.body) || retried.header.Get(SignatureHeader) != webhook.header.Get(SignatureHeader) {
+		t.Errorf("retried webhook = %s, want the same signed payload %s", retried.body, webhook.body)
+	}
+	delivery = waitForDelivery(t, u, loaded.ID, 2)
+	if delivery.Status != DeliverySucceeded || delivery.NextAttemptAt != nil || len(delivery.Attempts) != 2 || delivery.Attempts[1].StatusCode != http.StatusNoContent {
+		t.Errorf("delivery = %+v, want it succeeded on the second attempt", delivery)
+	}
+	if deliveries, _ := u.ListDeliveries(lowBattery.ID); len(deliveries) != 0 {
+		t.Errorf("deliveries of the battery webhook = %+v, want none", deliveries)
+	}
+}
+
+func Test_webhookUsecase_GiveUp(t *testing.T) {
+	u, events, clock := newMemoryWebhookUsecase(t)
+	receiver, received := newWebhookReceiver(t, http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway)
+	created, _ := u.CreateWebhook(WebhookObject{URL: receiver.URL})
+
+	events.Publish(EventBatteryLow, 3, BatteryEvent{Level: 24})
+	nextWebhook(t, received)
+	waitForDelivery(t, u, created.ID, 1)
+	// the backoff doubles after every failed attempt
+	for attempt, backoff := range []time.Duration{30 * time.Second, time.Minute} {
+		clock.Advance(backoff)
+		u.DeliverWebhooks()
+		nextWebhook(t, received)
+		waitForDelivery(t, u, created.ID, attempt+2)
+	}
+	delivery := waitForDelivery(t, u, created.ID, 3)
+	if delivery.Status != DeliveryFailed || delivery.NextAttemptAt != nil || delivery.EventType != WebhookBatteryLow {
+		t.Errorf("delivery = %+v, want it failed after 3 attempts", delivery)
+	}
+}
+
+func Test_webhookUsecase_backoff(t *testing.T) {
+	u := &webhookUsecase{retryBackoff: time.Minute}
+	for attempts, want := range map[int]time.Duration{1: time.Minute, 2: 2 * time.Minute, 4: 8 * time.Minute, 20: maxRetryBackoff, 100: maxRetryBackoff} {
+		if got := u.backoff(attempts); got != want {
+			t.Errorf("webhookUsecase.backoff(%d) = %v, want %v", attempts, got, want)
+		}
+	}
+}